@@ -2,16 +2,18 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 
 	"net/http"
 
 	"github.com/Neniel/gotennis/auth/usecase"
 	"github.com/Neniel/gotennis/lib/app"
-	"github.com/Neniel/gotennis/lib/database"
 	"github.com/Neniel/gotennis/lib/log"
 	"github.com/Neniel/gotennis/lib/middleware"
+	"github.com/Neniel/gotennis/lib/problem"
 	"github.com/Neniel/gotennis/lib/telemetry/grafana"
+	"github.com/Neniel/gotennis/lib/util"
 )
 
 type Usecases struct {
@@ -45,7 +47,7 @@ func (api *APIServer) Run() {
 	mux.HandleFunc("GET /ping", api.pingHandler)
 	mux.HandleFunc("POST /login", api.login)
 
-	log.Logger.Error(http.ListenAndServe(os.Getenv("APP_PORT"), middleware.CORSMiddleware(mux)).Error())
+	log.Logger.Error(http.ListenAndServe(os.Getenv("APP_PORT"), middleware.CORSMiddleware(api.AuthMicroservice.App.GetTenantResolver().Middleware(mux))).Error())
 }
 
 func (api *APIServer) pingHandler(w http.ResponseWriter, r *http.Request) {
@@ -65,8 +67,7 @@ func (api *APIServer) login(w http.ResponseWriter, r *http.Request) {
 		grafana.SendMetric("login", 1, 1, map[string]interface{}{
 			"status_code": http.StatusBadRequest,
 		})
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -76,31 +77,35 @@ func (api *APIServer) login(w http.ResponseWriter, r *http.Request) {
 	   3. obtener datos del token
 	*/
 
+	// The header holds the slug of the tenant, although its name is accepted too as long as it
+	// turns into the slug
 	tenantName := r.Header.Get("X-Tenant-Name")
 
-	tenant, err := api.AuthMicroservice.App.GetTenantByName(tenantName)
+	tenant, err := api.AuthMicroservice.App.GetTenantBySlug(util.Slug(tenantName))
 	if err != nil {
-		w.WriteHeader(http.StatusNotFound)
-		w.Write([]byte("tenant not found"))
+		problem.Write(w, r, util.ErrTenantNameHeaderIsInvalid)
 		return
 	}
 
 	client, err := api.AuthMicroservice.App.GetTenantMongoDBClient(tenant.ID.Hex())
+	if errors.Is(err, util.ErrTenantHeaderIsInvalid) {
+		problem.Write(w, r, util.ErrTenantNameHeaderIsInvalid)
+		return
+	}
+
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte("invalid value in header X-Tenant-Name"))
+		problem.Write(w, r, err)
 		return
 	}
 
-	login := usecase.NewLogin(database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+	login := usecase.NewLogin(client.DBReader())
 
 	err = login.Do(r.Context(), &request)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
 		grafana.SendMetric("login", 1, 1, map[string]interface{}{
-			"status_code": http.StatusInternalServerError,
+			"status_code": problem.StatusCode(err),
 		})
+		problem.Write(w, r, err)
 		return
 	}
 
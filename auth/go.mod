@@ -14,7 +14,7 @@ require (
 	github.com/Neniel/gotennis/lib/entity v0.0.0-20240602192022-f8de9f9ace57 // indirect
 	github.com/Neniel/gotennis/lib/log v0.0.0-20240602192022-f8de9f9ace57
 	github.com/Neniel/gotennis/lib/telemetry v0.0.0-20240602192022-f8de9f9ace57
-	github.com/Neniel/gotennis/lib/util v0.0.0-20240602192022-f8de9f9ace57 // indirect
+	github.com/Neniel/gotennis/lib/util v0.0.0-20240602192022-f8de9f9ace57
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/klauspost/compress v1.17.8 // indirect
 	github.com/montanaflynn/stats v0.7.1 // indirect
@@ -22,5 +22,7 @@ func main() {
 		*/
 	}
 
+	go app.WatchTenants(context.Background())
+
 	ms.NewAPIServer().Run()
 }
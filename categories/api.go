@@ -2,7 +2,7 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -10,12 +10,13 @@ import (
 	"github.com/Neniel/gotennis/categories/usecase"
 
 	"github.com/Neniel/gotennis/lib/app"
-	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/etag"
 	"github.com/Neniel/gotennis/lib/middleware"
+	"github.com/Neniel/gotennis/lib/problem"
 	"github.com/Neniel/gotennis/lib/telemetry/grafana"
-
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/Neniel/gotennis/lib/util"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -25,7 +26,10 @@ type Usecases struct {
 	ListCategories        usecase.ListCategories
 	GetCategory           usecase.GetCategory
 	UpdateCategory        usecase.UpdateCategory
+	PatchCategory         usecase.PatchCategory
 	DeleteCategory        usecase.DeleteCategory
+	RestoreCategory       usecase.RestoreCategory
+	ExportCategories      usecase.ExportCategories
 }
 
 type CategoryMicroservice struct {
@@ -54,13 +58,17 @@ func (api *APIServer) Run() {
 
 	mux.HandleFunc("GET /ping", api.pingHandler)
 	mux.HandleFunc("GET /categories", api.listCategories)
+	mux.HandleFunc("GET /categories/export", api.exportCategories)
 	mux.HandleFunc("GET /categories/{id}", api.getCategory)
-	mux.HandleFunc("POST /categories", api.addCategory)
+	mux.Handle("POST /categories", api.CategoryMicroservice.App.GetIdempotency().Middleware(http.HandlerFunc(api.addCategory)))
 	mux.HandleFunc("PUT /categories/{id}", api.updateCategory)
+	mux.HandleFunc("PATCH /categories/{id}", api.patchCategory)
 	mux.HandleFunc("DELETE /categories/{id}", api.deleteCategory)
+	mux.HandleFunc("POST /categories/{id}/restore", api.restoreCategory)
 	mux.Handle("/metrics", promhttp.Handler())
 
-	log.Fatal(http.ListenAndServe(os.Getenv("APP_PORT"), middleware.CORSMiddleware(mux)))
+	handler := middleware.TenantMiddleware(middleware.RateLimitMiddleware(mux, api.CategoryMicroservice.App.APICallsPerMinute), api.CategoryMicroservice.App.CheckTenant)
+	log.Fatal(http.ListenAndServe(os.Getenv("APP_PORT"), middleware.CORSMiddleware(api.CategoryMicroservice.App.GetTenantResolver().Middleware(handler))))
 }
 
 func (api *APIServer) pingHandler(w http.ResponseWriter, r *http.Request) {
@@ -72,6 +80,13 @@ func (api *APIServer) pingHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (api *APIServer) listCategories(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	q, err := query.Parse(r.URL.Query(), usecase.CategoriesQuerySchema)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
 	/*
 	   1. recibir el token
 	   2. validar el token
@@ -82,20 +97,20 @@ func (api *APIServer) listCategories(w http.ResponseWriter, r *http.Request) {
 
 	client, err := api.CategoryMicroservice.App.GetTenantMongoDBClient(tenantID)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte("invalid value in header X-Tenant-ID"))
+		problem.Write(w, r, err)
 		return
 	}
 
-	listCategories := usecase.NewListCategories(database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+	listCategories := usecase.NewListCategories(client.DBReader())
 
-	categories, err := listCategories.Do(r.Context())
+	categories, err := listCategories.Do(r.Context(), q)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
-	err = json.NewEncoder(w).Encode(&categories)
+	query.SetPaginationHeaders(w, r, categories.NextCursor)
+	err = json.NewEncoder(w).Encode(&categories.Items)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -103,6 +118,7 @@ func (api *APIServer) listCategories(w http.ResponseWriter, r *http.Request) {
 }
 
 func (api *APIServer) getCategory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
 	if categoryId := r.PathValue("id"); categoryId != "" {
 
 		/*
@@ -115,30 +131,22 @@ func (api *APIServer) getCategory(w http.ResponseWriter, r *http.Request) {
 
 		client, err := api.CategoryMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("invalid value in header X-Tenant-ID"))
+			problem.Write(w, r, err)
 			return
 		}
 
-		getCategory := usecase.NewGetCategory(database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+		getCategory := usecase.NewGetCategory(client.DBReader())
 
 		categories, err := getCategory.Do(r.Context(), categoryId)
-		if errors.Is(err, primitive.ErrInvalidHex) {
-			w.WriteHeader(http.StatusBadRequest)
-			grafana.SendMetric("get.category", 1, 1, map[string]interface{}{
-				"status_code": http.StatusBadRequest,
-			})
-			return
-		}
-
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			w.WriteHeader(http.StatusNotFound)
+		if err != nil {
+			problem.Write(w, r, err)
 			grafana.SendMetric("get.category", 1, 1, map[string]interface{}{
-				"status_code": http.NotFound,
+				"status_code": problem.StatusCode(err),
 			})
 			return
 		}
 
+		etag.Set(w, categories.Version)
 		err = json.NewEncoder(w).Encode(&categories)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -154,13 +162,13 @@ func (api *APIServer) getCategory(w http.ResponseWriter, r *http.Request) {
 }
 
 func (api *APIServer) addCategory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
 	var request usecase.CreateCategoryRequest
 
 	defer r.Body.Close()
 	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -174,17 +182,15 @@ func (api *APIServer) addCategory(w http.ResponseWriter, r *http.Request) {
 
 	client, err := api.CategoryMicroservice.App.GetTenantMongoDBClient(tenantID)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte("invalid value in header X-Tenant-ID"))
+		problem.Write(w, r, err)
 		return
 	}
 
-	createCategory := usecase.NewCreateCategory(database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName))
+	createCategory := usecase.NewCreateCategory(client.DBWriter())
 
 	category, err := createCategory.Do(r.Context(), &request)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -192,11 +198,11 @@ func (api *APIServer) addCategory(w http.ResponseWriter, r *http.Request) {
 	err = json.NewEncoder(w).Encode(&category)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
 		return
 	}
 }
 func (api *APIServer) updateCategory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
 	if id := r.PathValue("id"); id != "" {
 
 		var request usecase.UpdateCategoryRequest
@@ -204,8 +210,13 @@ func (api *APIServer) updateCategory(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
 		err := json.NewDecoder(r.Body).Decode(&request)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
+			return
+		}
+
+		request.Version, err = etag.IfMatch(r)
+		if err != nil {
+			problem.Write(w, r, err)
 			return
 		}
 
@@ -219,25 +230,74 @@ func (api *APIServer) updateCategory(w http.ResponseWriter, r *http.Request) {
 
 		client, err := api.CategoryMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("invalid value in header X-Tenant-ID"))
+			problem.Write(w, r, err)
 			return
 		}
 
-		updateCategory := usecase.NewUpdateCategory(database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName), database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName))
+		updateCategory := usecase.NewUpdateCategory(client.DBReader(), client.DBWriter())
 
 		category, err := updateCategory.Do(r.Context(), id, &request)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
 			return
 		}
 
+		etag.Set(w, category.Version)
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(&category)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (api *APIServer) patchCategory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+
+		var request usecase.PatchCategoryRequest
+
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		request.Version, err = etag.IfMatch(r)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		/*
+		   1. recibir el token
+		   2. validar el token
+		   3. obtener datos del token
+		*/
+
+		tenantID := r.Header.Get("X-Tenant-ID")
+
+		client, err := api.CategoryMicroservice.App.GetTenantMongoDBClient(tenantID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		patchCategory := usecase.NewPatchCategory(client.DBReader(), client.DBWriter())
+
+		category, err := patchCategory.Do(r.Context(), id, &request)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		etag.Set(w, category.Version)
 		w.WriteHeader(http.StatusOK)
 		err = json.NewEncoder(w).Encode(&category)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(err.Error()))
 			return
 		}
 	}
@@ -256,19 +316,94 @@ func (api *APIServer) deleteCategory(w http.ResponseWriter, r *http.Request) {
 
 		client, err := api.CategoryMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("invalid value in header X-Tenant-ID"))
+			problem.Write(w, r, err)
+			return
+		}
+
+		version, err := etag.IfMatch(r)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		deleteCategory := usecase.NewDeleteCategory(client.DBReader(), client.DBWriter())
+
+		err = deleteCategory.Do(r.Context(), id, &usecase.DeleteCategoryRequest{
+			Policy:     entity.CategoryDeletePolicy(r.URL.Query().Get("policy")),
+			ReassignTo: r.URL.Query().Get("reassign_to"),
+			DeletedBy:  r.Header.Get("X-User-ID"),
+			Version:    version,
+		})
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (api *APIServer) restoreCategory(w http.ResponseWriter, r *http.Request) {
+	if id := r.PathValue("id"); id != "" {
+
+		/*
+		   1. recibir el token
+		   2. validar el token
+		   3. obtener datos del token
+		*/
+
+		tenantID := r.Header.Get("X-Tenant-ID")
+
+		client, err := api.CategoryMicroservice.App.GetTenantMongoDBClient(tenantID)
+		if err != nil {
+			problem.Write(w, r, err)
 			return
 		}
 
-		deleteCategory := usecase.NewDeleteCategory(database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName))
+		restoreCategory := usecase.NewRestoreCategory(client.DBWriter())
 
-		err = deleteCategory.Do(r.Context(), id)
+		err = restoreCategory.Do(r.Context(), id)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
 			return
 		}
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+func (api *APIServer) exportCategories(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	format, err := util.ParseExportFormat(values.Get("format"))
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	values.Del("format")
+
+	q, err := query.Parse(values, usecase.CategoriesQuerySchema)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	/*
+	   1. recibir el token
+	   2. validar el token
+	   3. obtener datos del token
+	*/
+
+	tenantID := r.Header.Get("X-Tenant-ID")
+
+	client, err := api.CategoryMicroservice.App.GetTenantMongoDBClient(tenantID)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	exportCategories := usecase.NewExportCategories(client.DBReader())
+
+	// The response is streamed, so once it has started an error can only be logged
+	util.SetExportHeaders(w, "categories", format)
+	if err := exportCategories.Do(r.Context(), w, format, q); err != nil {
+		log.Println(fmt.Errorf("couldn't export categories: %w", err))
+	}
+}
@@ -20,5 +20,8 @@ func main() {
 		//},
 	}
 
+	go app.StartPurge(context.Background(), "categories")
+	go app.WatchTenants(context.Background())
+
 	ms.NewAPIServer().Run()
 }
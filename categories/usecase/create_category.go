@@ -31,11 +31,9 @@ type CreateCategoryRequest struct {
 }
 
 func (r *CreateCategoryRequest) Validate() error {
-	if r.Name == "" {
-		return util.ErrCategoryNameIsEmpty
-	}
-
-	return nil
+	var v util.Validator
+	v.Check(r.Name != "", "name", util.ErrCategoryNameIsEmpty)
+	return v.Err()
 }
 
 func (uc *createCategory) Do(ctx context.Context, request *CreateCategoryRequest) (*entity.Category, error) {
@@ -2,28 +2,81 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type DeleteCategory interface {
-	Do(ctx context.Context, id string) error
+	Do(ctx context.Context, id string, request *DeleteCategoryRequest) error
 }
 
 type deleteCategory struct {
+	DBReader database.DBReader
 	DBWriter database.DBWriter
 }
 
-func NewDeleteCategory(dbWriter database.DBWriter) DeleteCategory {
+func NewDeleteCategory(dbReader database.DBReader, dbWriter database.DBWriter) DeleteCategory {
 	return &deleteCategory{
+		DBReader: dbReader,
 		DBWriter: dbWriter,
 	}
 }
 
-func (uc *deleteCategory) Do(ctx context.Context, id string) error {
-	err := uc.DBWriter.DeleteCategory(ctx, id)
+type DeleteCategoryRequest struct {
+	Policy     entity.CategoryDeletePolicy `json:"policy"`
+	ReassignTo string                      `json:"reassign_to"`
+	DeletedBy  string                      `json:"-"`
+	// Version is the one the deletion is based on, taken from header If-Match
+	Version *int64 `json:"-"`
+}
+
+func (r *DeleteCategoryRequest) Validate(id string) error {
+	if r.Policy == "" {
+		r.Policy = entity.CategoryDeletePolicyBlock
+	}
+
+	var v util.Validator
+	v.Check(r.Policy.IsValid(), "policy", util.ErrCategoryInvalidDeletePolicy)
+
+	if r.Policy == entity.CategoryDeletePolicyReassign {
+		if r.ReassignTo == "" {
+			v.Add("reassign_to", util.ErrCategoryReassignToIsEmpty)
+		} else {
+			v.Check(r.ReassignTo != id, "reassign_to", util.ErrCategoryReassignToIsSameCategory)
+		}
+	}
+
+	return v.Err()
+}
+
+func (uc *deleteCategory) Do(ctx context.Context, id string, request *DeleteCategoryRequest) error {
+	if err := request.Validate(id); err != nil {
+		log.Println(fmt.Errorf("error at DeleteCategory: %w", err))
+		return err
+	}
+
+	var replacement *entity.Category
+	if request.Policy == entity.CategoryDeletePolicyReassign {
+		category, err := uc.DBReader.GetCategory(ctx, request.ReassignTo)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return util.ErrCategoryReassignToNotFound
+		}
+
+		if err != nil {
+			log.Println(fmt.Errorf("error at DeleteCategory: %w", err))
+			return err
+		}
+
+		replacement = category
+	}
+
+	err := uc.DBWriter.DeleteCategory(ctx, id, request.Version, request.Policy, replacement, request.DeletedBy)
 	if err != nil {
 		log.Println(fmt.Errorf("error at DeleteCategory: %w", err))
 		return err
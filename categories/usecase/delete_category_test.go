@@ -7,13 +7,18 @@ import (
 	"testing"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.uber.org/mock/gomock"
 )
 
 func TestNewDeleteCategory(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
 	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
 
 	type args struct {
+		dbReader database.DBReader
 		dbWriter database.DBWriter
 	}
 	tests := []struct {
@@ -24,31 +29,88 @@ func TestNewDeleteCategory(t *testing.T) {
 		{
 			name: "NewDeleteCategoryUsecase",
 			args: args{
+				dbReader: dbReader,
 				dbWriter: dbWriter,
 			},
 			want: &deleteCategory{
+				DBReader: dbReader,
 				DBWriter: dbWriter,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewDeleteCategory(tt.args.dbWriter); !reflect.DeepEqual(got, tt.want) {
+			if got := NewDeleteCategory(tt.args.dbReader, tt.args.dbWriter); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewDeleteCategory() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func TestDeleteCategoryRequest_Validate(t *testing.T) {
+	type args struct {
+		id string
+	}
+	tests := []struct {
+		name       string
+		request    *DeleteCategoryRequest
+		args       args
+		wantPolicy entity.CategoryDeletePolicy
+		wantErr    error
+	}{
+		{
+			name:       "Policy_defaults_to_block",
+			request:    &DeleteCategoryRequest{},
+			args:       args{id: "663d70d88264adea5d7d29bb"},
+			wantPolicy: entity.CategoryDeletePolicyBlock,
+			wantErr:    nil,
+		},
+		{
+			name:       "Policy_is_unknown",
+			request:    &DeleteCategoryRequest{Policy: "ignore"},
+			args:       args{id: "663d70d88264adea5d7d29bb"},
+			wantPolicy: "ignore",
+			wantErr:    util.ErrCategoryInvalidDeletePolicy,
+		},
+		{
+			name:       "Reassign_requires_target",
+			request:    &DeleteCategoryRequest{Policy: entity.CategoryDeletePolicyReassign},
+			args:       args{id: "663d70d88264adea5d7d29bb"},
+			wantPolicy: entity.CategoryDeletePolicyReassign,
+			wantErr:    util.ErrCategoryReassignToIsEmpty,
+		},
+		{
+			name:       "Reassign_target_must_be_another_category",
+			request:    &DeleteCategoryRequest{Policy: entity.CategoryDeletePolicyReassign, ReassignTo: "663d70d88264adea5d7d29bb"},
+			args:       args{id: "663d70d88264adea5d7d29bb"},
+			wantPolicy: entity.CategoryDeletePolicyReassign,
+			wantErr:    util.ErrCategoryReassignToIsSameCategory,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.request.Validate(tt.args.id); !errors.Is(err, tt.wantErr) {
+				t.Errorf("DeleteCategoryRequest.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.request.Policy != tt.wantPolicy {
+				t.Errorf("DeleteCategoryRequest.Validate() policy = %v, want %v", tt.request.Policy, tt.wantPolicy)
+			}
+		})
+	}
+}
+
 func Test_deleteCategoryUsecase_Delete_Success(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
 	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
 
 	type fields struct {
+		DBReader database.DBReader
 		DBWriter database.DBWriter
 	}
 	type args struct {
-		ctx context.Context
-		id  string
+		ctx     context.Context
+		id      string
+		request *DeleteCategoryRequest
 	}
 	tests := []struct {
 		name         string
@@ -60,14 +122,38 @@ func Test_deleteCategoryUsecase_Delete_Success(t *testing.T) {
 		{
 			name: "Deletes_category_successfully",
 			fields: fields{
+				DBReader: dbReader,
+				DBWriter: dbWriter,
+			},
+			args: args{
+				ctx:     context.Background(),
+				id:      "663d70d88264adea5d7d29bb",
+				request: &DeleteCategoryRequest{},
+			},
+			prepareMocks: func() {
+				dbWriter.EXPECT().DeleteCategory(gomock.Any(), "663d70d88264adea5d7d29bb", nil, entity.CategoryDeletePolicyBlock, nil, "").Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Deletes_category_and_reassigns_references",
+			fields: fields{
+				DBReader: dbReader,
 				DBWriter: dbWriter,
 			},
 			args: args{
 				ctx: context.Background(),
 				id:  "663d70d88264adea5d7d29bb",
+				request: &DeleteCategoryRequest{
+					Policy:     entity.CategoryDeletePolicyReassign,
+					ReassignTo: "663d70d88264adea5d7d29ba",
+					DeletedBy:  "admin",
+					Version:    util.ToPtr(int64(2)),
+				},
 			},
 			prepareMocks: func() {
-				dbWriter.EXPECT().DeleteCategory(gomock.Any(), gomock.Any()).Return(nil)
+				dbReader.EXPECT().GetCategory(gomock.Any(), "663d70d88264adea5d7d29ba").Return(&entity.Category{Name: "Category 2"}, nil)
+				dbWriter.EXPECT().DeleteCategory(gomock.Any(), "663d70d88264adea5d7d29bb", util.ToPtr(int64(2)), entity.CategoryDeletePolicyReassign, &entity.Category{Name: "Category 2"}, "admin").Return(nil)
 			},
 			wantErr: false,
 		},
@@ -76,9 +162,10 @@ func Test_deleteCategoryUsecase_Delete_Success(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.prepareMocks()
 			uc := &deleteCategory{
+				DBReader: tt.fields.DBReader,
 				DBWriter: tt.fields.DBWriter,
 			}
-			if err := uc.Do(tt.args.ctx, tt.args.id); (err != nil) != tt.wantErr {
+			if err := uc.Do(tt.args.ctx, tt.args.id, tt.args.request); (err != nil) != tt.wantErr {
 				t.Errorf("deleteCategory.Do() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -86,14 +173,17 @@ func Test_deleteCategoryUsecase_Delete_Success(t *testing.T) {
 }
 
 func Test_deleteCategoryUsecase_Delete_Failure(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
 	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
 
 	type fields struct {
+		DBReader database.DBReader
 		DBWriter database.DBWriter
 	}
 	type args struct {
-		ctx context.Context
-		id  string
+		ctx     context.Context
+		id      string
+		request *DeleteCategoryRequest
 	}
 	tests := []struct {
 		name         string
@@ -105,14 +195,35 @@ func Test_deleteCategoryUsecase_Delete_Failure(t *testing.T) {
 		{
 			name: "Deletes_category_fails",
 			fields: fields{
+				DBReader: dbReader,
+				DBWriter: dbWriter,
+			},
+			args: args{
+				ctx:     context.Background(),
+				id:      "663d70d88264adea5d7d29bb",
+				request: &DeleteCategoryRequest{},
+			},
+			prepareMocks: func() {
+				dbWriter.EXPECT().DeleteCategory(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("error when deleting the category"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Reassign_target_does_not_exist",
+			fields: fields{
+				DBReader: dbReader,
 				DBWriter: dbWriter,
 			},
 			args: args{
 				ctx: context.Background(),
 				id:  "663d70d88264adea5d7d29bb",
+				request: &DeleteCategoryRequest{
+					Policy:     entity.CategoryDeletePolicyReassign,
+					ReassignTo: "663d70d88264adea5d7d29ba",
+				},
 			},
 			prepareMocks: func() {
-				dbWriter.EXPECT().DeleteCategory(gomock.Any(), gomock.Any()).Return(errors.New("error when deleting the category"))
+				dbReader.EXPECT().GetCategory(gomock.Any(), "663d70d88264adea5d7d29ba").Return(nil, mongo.ErrNoDocuments)
 			},
 			wantErr: true,
 		},
@@ -121,9 +232,10 @@ func Test_deleteCategoryUsecase_Delete_Failure(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.prepareMocks()
 			uc := &deleteCategory{
+				DBReader: tt.fields.DBReader,
 				DBWriter: tt.fields.DBWriter,
 			}
-			if err := uc.Do(tt.args.ctx, tt.args.id); (err != nil) != tt.wantErr {
+			if err := uc.Do(tt.args.ctx, tt.args.id, tt.args.request); (err != nil) != tt.wantErr {
 				t.Errorf("deleteCategory.Do() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+var categoriesExportHeader = []string{"id", "name", "created_at"}
+
+type ExportCategories interface {
+	Do(ctx context.Context, w io.Writer, format util.ExportFormat, q *query.Query) error
+}
+
+type exportCategories struct {
+	DBReader database.DBReader
+}
+
+func NewExportCategories(dbReader database.DBReader) ExportCategories {
+	return &exportCategories{
+		DBReader: dbReader,
+	}
+}
+
+// Do writes to w, in the given format, the categories matching the filters and sort of q.
+func (uc *exportCategories) Do(ctx context.Context, w io.Writer, format util.ExportFormat, q *query.Query) error {
+	exporter := util.NewExporter(w, format, categoriesExportHeader, func(c *entity.Category) []string {
+		return []string{c.ID.Hex(), c.Name, c.CreatedAt.Format(time.RFC3339)}
+	})
+
+	if err := uc.DBReader.StreamCategories(ctx, q, exporter.Write); err != nil {
+		return err
+	}
+
+	return exporter.Flush()
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_exportCategories_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+
+	id, _ := primitive.ObjectIDFromHex("665cd2c2e1a8b1a6c0b7a001")
+	category := entity.Category{ID: id, Name: "Primera, A", CreatedAt: time.Date(2024, 6, 2, 10, 0, 0, 0, time.UTC)}
+
+	streamCategories := func(_ context.Context, _ *query.Query, fn func(*entity.Category) error) error {
+		return fn(&category)
+	}
+
+	tests := []struct {
+		name           string
+		format         util.ExportFormat
+		prepareUsecase func()
+		want           string
+		wantErr        bool
+	}{
+		{
+			name:   "Export_as_CSV",
+			format: util.ExportFormatCSV,
+			prepareUsecase: func() {
+				dbReader.EXPECT().StreamCategories(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(streamCategories)
+			},
+			want: "id,name,created_at\n665cd2c2e1a8b1a6c0b7a001,\"Primera, A\",2024-06-02T10:00:00Z\n",
+		},
+		{
+			name:   "Export_as_NDJSON",
+			format: util.ExportFormatNDJSON,
+			prepareUsecase: func() {
+				dbReader.EXPECT().StreamCategories(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(streamCategories)
+			},
+			want: `{"id":"665cd2c2e1a8b1a6c0b7a001","name":"Primera, A","version":0,"created_at":"2024-06-02T10:00:00Z","updated_at":null,"deleted_at":null,"deleted_by":null}` + "\n",
+		},
+		{
+			name:   "Export_header_only_when_there_are_no_categories",
+			format: util.ExportFormatCSV,
+			prepareUsecase: func() {
+				dbReader.EXPECT().StreamCategories(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			},
+			want: "id,name,created_at\n",
+		},
+		{
+			name:   "Fail_when_reading_categories",
+			format: util.ExportFormatCSV,
+			prepareUsecase: func() {
+				dbReader.EXPECT().StreamCategories(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("error when reading categories"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			var w bytes.Buffer
+			uc := NewExportCategories(dbReader)
+			err := uc.Do(context.Background(), &w, tt.format, &query.Query{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("exportCategories.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && w.String() != tt.want {
+				t.Errorf("exportCategories.Do() = %q, want %q", w.String(), tt.want)
+			}
+		})
+	}
+}
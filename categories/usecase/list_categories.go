@@ -6,12 +6,23 @@ import (
 	"log"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
 
 	"github.com/Neniel/gotennis/lib/entity"
 )
 
+var CategoriesQuerySchema = query.Schema{
+	Filters: map[string]query.Field{
+		"name": {Path: "name", Type: query.String},
+	},
+	Sorts: map[string]query.Field{
+		"name":       {Path: "name"},
+		"created_at": {Path: "created_at"},
+	},
+}
+
 type ListCategories interface {
-	Do(ctx context.Context) ([]entity.Category, error)
+	Do(ctx context.Context, q *query.Query) (*query.Page[entity.Category], error)
 }
 
 type listCategories struct {
@@ -24,8 +35,8 @@ func NewListCategories(dbReader database.DBReader) ListCategories {
 	}
 }
 
-func (uc *listCategories) Do(ctx context.Context) ([]entity.Category, error) {
-	categories, err := uc.DBReader.GetCategories(ctx)
+func (uc *listCategories) Do(ctx context.Context, q *query.Query) (*query.Page[entity.Category], error) {
+	categories, err := uc.DBReader.GetCategories(ctx, q)
 	if err != nil {
 		log.Println(fmt.Errorf("error at GetCategories: %w", err))
 		return nil, err
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
 	"github.com/Neniel/gotennis/lib/entity"
 	"go.uber.org/mock/gomock"
 )
@@ -47,13 +48,14 @@ func Test_listCategories_List_Success(t *testing.T) {
 	}
 	type args struct {
 		ctx context.Context
+		q   *query.Query
 	}
 	tests := []struct {
 		name           string
 		fields         fields
 		args           args
 		prepareUsecase func()
-		want           []entity.Category
+		want           *query.Page[entity.Category]
 		wantErr        bool
 	}{
 		{
@@ -63,24 +65,31 @@ func Test_listCategories_List_Success(t *testing.T) {
 			},
 			args: args{
 				ctx: context.Background(),
+				q:   &query.Query{Limit: 2},
 			},
 			prepareUsecase: func() {
-				dbReader.EXPECT().GetCategories(gomock.Any()).Return([]entity.Category{
+				dbReader.EXPECT().GetCategories(gomock.Any(), &query.Query{Limit: 2}).Return(&query.Page[entity.Category]{
+					Items: []entity.Category{
+						{
+							Name: "Category 1",
+						},
+						{
+							Name: "Category 2",
+						},
+					},
+					NextCursor: "next",
+				}, nil)
+			},
+			want: &query.Page[entity.Category]{
+				Items: []entity.Category{
 					{
 						Name: "Category 1",
 					},
 					{
 						Name: "Category 2",
 					},
-				}, nil)
-			},
-			want: []entity.Category{
-				{
-					Name: "Category 1",
-				},
-				{
-					Name: "Category 2",
 				},
+				NextCursor: "next",
 			},
 			wantErr: false,
 		},
@@ -91,7 +100,7 @@ func Test_listCategories_List_Success(t *testing.T) {
 			uc := &listCategories{
 				DBReader: tt.fields.DBReader,
 			}
-			got, err := uc.Do(tt.args.ctx)
+			got, err := uc.Do(tt.args.ctx, tt.args.q)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listCategories.Do() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -110,13 +119,14 @@ func Test_listCategories_List_Failed(t *testing.T) {
 	}
 	type args struct {
 		ctx context.Context
+		q   *query.Query
 	}
 	tests := []struct {
 		name           string
 		fields         fields
 		args           args
 		prepareUsecase func()
-		want           []entity.Category
+		want           *query.Page[entity.Category]
 		wantErr        bool
 	}{
 		{
@@ -126,9 +136,10 @@ func Test_listCategories_List_Failed(t *testing.T) {
 			},
 			args: args{
 				ctx: context.Background(),
+				q:   &query.Query{Limit: 2},
 			},
 			prepareUsecase: func() {
-				dbReader.EXPECT().GetCategories(gomock.Any()).Return(nil, errors.New("error when fetting the categories"))
+				dbReader.EXPECT().GetCategories(gomock.Any(), gomock.Any()).Return(nil, errors.New("error when fetting the categories"))
 			},
 			want:    nil,
 			wantErr: true,
@@ -140,7 +151,7 @@ func Test_listCategories_List_Failed(t *testing.T) {
 			uc := &listCategories{
 				DBReader: tt.fields.DBReader,
 			}
-			got, err := uc.Do(tt.args.ctx)
+			got, err := uc.Do(tt.args.ctx, tt.args.q)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("listCategories.Do() error = %v, wantErr %v", err, tt.wantErr)
 				return
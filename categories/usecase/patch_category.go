@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+type PatchCategory interface {
+	Do(ctx context.Context, id string, request *PatchCategoryRequest) (*entity.Category, error)
+}
+
+type patchCategory struct {
+	DBReader database.DBReader
+	DBWriter database.DBWriter
+}
+
+func NewPatchCategory(dbReader database.DBReader, dbWriter database.DBWriter) PatchCategory {
+	return &patchCategory{
+		DBReader: dbReader,
+		DBWriter: dbWriter,
+	}
+}
+
+// PatchCategoryRequest is a JSON merge patch (RFC 7396) of a category: only the fields in it are
+// changed, and the ones that are null are cleared.
+type PatchCategoryRequest struct {
+	ID   util.PatchField[string] `json:"id"`
+	Name util.PatchField[string] `json:"name"`
+	// Version is the one the update is based on, taken from header If-Match. When set, the update
+	// fails with util.ErrPreconditionFailed if the category has been written since
+	Version *int64 `json:"-"`
+}
+
+func (r *PatchCategoryRequest) Validate(id string) error {
+	var v util.Validator
+	if id == "" {
+		v.Add("id", util.ErrCategoryIDIsEmpty)
+	} else if r.ID.Present {
+		v.Check(r.ID.Value == id, "id", util.ErrCategoryIDMismatch)
+	}
+
+	return v.Err()
+}
+
+func (uc *patchCategory) Do(ctx context.Context, id string, request *PatchCategoryRequest) (*entity.Category, error) {
+	if err := request.Validate(id); err != nil {
+		return nil, err
+	}
+
+	category, err := uc.DBReader.GetCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := util.CheckVersion(request.Version, category.Version); err != nil {
+		return nil, err
+	}
+
+	patch := util.NewPatch()
+	util.ApplyPatchField(patch, "name", request.Name, &category.Name)
+
+	var v util.Validator
+	v.Check(category.Name != "", "name", util.ErrCategoryNameIsEmpty)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	if patch.IsEmpty() {
+		return category, nil
+	}
+
+	return uc.DBWriter.PatchCategory(ctx, category, patch)
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_patchCategory_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+
+	tests := []struct {
+		name         string
+		patch        string
+		version      *int64
+		prepareMocks func()
+		want         *entity.Category
+		wantErr      error
+	}{
+		{
+			name:    "Patches_category",
+			patch:   `{"name":"Category 1"}`,
+			version: util.ToPtr(int64(3)),
+			prepareMocks: func() {
+				dbReader.EXPECT().GetCategory(gomock.Any(), "663d70d88264adea5d7d29bb").Return(&entity.Category{Name: "1 category", Version: 3}, nil)
+				dbWriter.EXPECT().PatchCategory(gomock.Any(), &entity.Category{Name: "Category 1", Version: 3}, &util.Patch{
+					Set: map[string]interface{}{"name": "Category 1"},
+				}).Return(&entity.Category{Name: "Category 1", Version: 4}, nil)
+			},
+			want: &entity.Category{Name: "Category 1", Version: 4},
+		},
+		{
+			name:  "Fails_when_ID_does_not_match",
+			patch: `{"id":"663d70d88264adea5d7d29ba"}`,
+			prepareMocks: func() {
+			},
+			wantErr: util.ErrCategoryIDMismatch,
+		},
+		{
+			name:  "Fails_when_clearing_name",
+			patch: `{"name":null}`,
+			prepareMocks: func() {
+				dbReader.EXPECT().GetCategory(gomock.Any(), "663d70d88264adea5d7d29bb").Return(&entity.Category{Name: "1 category"}, nil)
+			},
+			wantErr: util.ErrCategoryNameIsEmpty,
+		},
+		{
+			name:    "Fails_when_category_has_been_modified",
+			patch:   `{"name":"Category 1"}`,
+			version: util.ToPtr(int64(2)),
+			prepareMocks: func() {
+				dbReader.EXPECT().GetCategory(gomock.Any(), "663d70d88264adea5d7d29bb").Return(&entity.Category{Name: "1 category", Version: 3}, nil)
+			},
+			wantErr: util.ErrPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request PatchCategoryRequest
+			if err := json.Unmarshal([]byte(tt.patch), &request); err != nil {
+				t.Fatal(err)
+			}
+			request.Version = tt.version
+
+			tt.prepareMocks()
+			uc := NewPatchCategory(dbReader, dbWriter)
+			got, err := uc.Do(context.Background(), "663d70d88264adea5d7d29bb", &request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("patchCategory.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("patchCategory.Do() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
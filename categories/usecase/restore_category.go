@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/Neniel/gotennis/lib/database"
+)
+
+type RestoreCategory interface {
+	Do(ctx context.Context, id string) error
+}
+
+type restoreCategory struct {
+	DBWriter database.DBWriter
+}
+
+func NewRestoreCategory(dbWriter database.DBWriter) RestoreCategory {
+	return &restoreCategory{
+		DBWriter: dbWriter,
+	}
+}
+
+func (uc *restoreCategory) Do(ctx context.Context, id string) error {
+	err := uc.DBWriter.RestoreCategory(ctx, id)
+	if err != nil {
+		log.Println(fmt.Errorf("error at RestoreCategory: %w", err))
+		return err
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 
 	"github.com/Neniel/gotennis/lib/database"
 	"github.com/Neniel/gotennis/lib/entity"
@@ -28,23 +27,21 @@ func NewUpdateCategory(dbReader database.DBReader, dbWriter database.DBWriter) U
 type UpdateCategoryRequest struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	// Version is the one the update is based on, taken from header If-Match. When set, the update
+	// fails with util.ErrPreconditionFailed if the category has been written since
+	Version *int64 `json:"-"`
 }
 
 func (r *UpdateCategoryRequest) Validate(id string) error {
-
+	var v util.Validator
 	if r.ID == "" {
-		return errors.New("category ID is required for update")
-	}
-
-	if r.ID != id {
-		return errors.New("provided category ID does not match the ID of the category to be updated")
-	}
-
-	if r.Name == "" {
-		return util.ErrCategoryNameIsEmpty
+		v.Add("id", util.ErrCategoryIDIsEmpty)
+	} else {
+		v.Check(r.ID == id, "id", util.ErrCategoryIDMismatch)
 	}
 
-	return nil
+	v.Check(r.Name != "", "name", util.ErrCategoryNameIsEmpty)
+	return v.Err()
 }
 
 func (uc *updateCategory) Do(ctx context.Context, id string, request *UpdateCategoryRequest) (*entity.Category, error) {
@@ -57,6 +54,10 @@ func (uc *updateCategory) Do(ctx context.Context, id string, request *UpdateCate
 		return nil, err
 	}
 
+	if err := util.CheckVersion(request.Version, category.Version); err != nil {
+		return nil, err
+	}
+
 	category.Name = request.Name
 
 	updatedCategory, err := uc.DBWriter.UpdateCategory(ctx, category)
@@ -8,6 +8,7 @@ import (
 
 	"github.com/Neniel/gotennis/lib/database"
 	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
 	"go.uber.org/mock/gomock"
 )
 
@@ -233,6 +234,30 @@ func Test_updateCategory_UpdateCategory(t *testing.T) {
 			want:    nil,
 			wantErr: true,
 		},
+		{
+			name: "Fails_when_category_has_been_modified",
+			fields: fields{
+				DBReader: dbReader,
+				DBWriter: dbWriter,
+			},
+			args: args{
+				ctx: context.Background(),
+				id:  "663d70d88264adea5d7d29bb",
+				request: &UpdateCategoryRequest{
+					ID:      "663d70d88264adea5d7d29bb",
+					Name:    "Category 1",
+					Version: util.ToPtr(int64(1)),
+				},
+			},
+			prepareMocks: func() {
+				dbReader.EXPECT().GetCategory(gomock.Any(), "663d70d88264adea5d7d29bb").Return(&entity.Category{
+					Name:    "1 category",
+					Version: 2,
+				}, nil)
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "Fails_when_fetching_category",
 			fields: fields{
@@ -2,22 +2,42 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/Neniel/gotennis/lib/config"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/migration"
 	"github.com/Neniel/gotennis/lib/entity"
 	"github.com/Neniel/gotennis/lib/log"
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/Neniel/gotennis/lib/security"
+	"github.com/Neniel/gotennis/lib/util"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type IApp interface {
+	GetMongoDBClients() map[string]*TenantMongoDB
 	GetSystemMongoDBClient() *SystemMongoDB
-	GetTenantByName(tenantName string) (*entity.Tenant, error)
+	GetTenantBySlug(slug string) (*entity.Tenant, error)
+	GetTenantByDomain(domain string) (*entity.Tenant, error)
+	GetTenantResolver() *TenantResolver
+	GetIdempotency() *Idempotency
 	GetTenantMongoDBClient(tenantID string) (*TenantMongoDB, error)
+	GetTenantMongoDBClientOfAnyStatus(tenantID string) (*TenantMongoDB, error)
+	CheckTenant(tenantID string) error
+	GetKeyProvider() security.KeyProvider
+	APICallsPerMinute(tenantID string) int64
+	GetDefaultTenantMongoDBURI() string
+	ConnectTenantMongoDB(ctx context.Context, tenant *entity.Tenant) (*TenantMongoDB, error)
+	StartPurge(ctx context.Context, collections ...string)
+	StartSystemPurge(ctx context.Context, collections ...string)
+	WatchTenants(ctx context.Context)
+	Migrate(ctx context.Context, direction migration.Direction, target int, dryRun bool) map[string]*migration.Result
 }
 
 type SystemMongoDB struct {
@@ -26,161 +46,251 @@ type SystemMongoDB struct {
 }
 
 type TenantMongoDB struct {
-	ID            string
-	DatabaseName  string
-	MongoDBClient *mongo.Client
+	ID           string
+	DatabaseName string
+	// SharedDatabase is set when the database holds other tenants too, so every read and write has
+	// to be scoped to this tenant
+	SharedDatabase bool
+	Status         entity.TenantStatus
+	Plan           entity.Plan
+	MongoDBClient  *mongo.Client
+
+	connectionString string
 }
 
 type App struct {
-	SystemMongoDBClient   *SystemMongoDB
+	SystemMongoDBClient *SystemMongoDB
+	// TenantsMongoDBClients is guarded by mu, since tenants are connected and removed while
+	// serving requests
 	TenantsMongoDBClients map[string]*TenantMongoDB
+	mu                    sync.RWMutex
+	// tenantLocks holds a *sync.Mutex per tenant, so that its connection is changed by one
+	// goroutine at a time
+	tenantLocks sync.Map
+	// tenantMisses holds the errors of the tenants that could not be connected lately, guarded by mu
+	tenantMisses map[string]tenantMiss
+	// Serving tells which tenants are served, DedicatedTenantID being the only one when serving a
+	// dedicated tenant
+	Serving           Serving
+	DedicatedTenantID string
+	// DefaultTenantMongoDBURI is where the databases of the tenants that do not bring their own
+	// cluster are created
+	DefaultTenantMongoDBURI string
+	// Resolver finds the tenants of the requests sent to their own domains
+	Resolver *TenantResolver
+	// Idempotency keeps the idempotency keys of the tenants in their databases
+	Idempotency *Idempotency
+	// Keys encrypt the connection strings of the tenants, which are stored as they are when nil
+	Keys                security.KeyProvider
+	SoftDeleteRetention time.Duration
+	PurgeInterval       time.Duration
 }
 
+// GetMongoDBClients returns a copy of the connections to the databases of the known tenants.
 func (a *App) GetMongoDBClients() map[string]*TenantMongoDB {
-	return a.TenantsMongoDBClients
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	clients := make(map[string]*TenantMongoDB, len(a.TenantsMongoDBClients))
+	for tenantID, client := range a.TenantsMongoDBClients {
+		clients[tenantID] = client
+	}
+
+	return clients
 }
 
 func (a *App) GetSystemMongoDBClient() *SystemMongoDB {
 	return a.SystemMongoDBClient
 }
 
-func (a *App) GetTenantByName(tenantName string) (*entity.Tenant, error) {
-	tenant := entity.Tenant{}
+// GetTenantBySlug returns the tenant whose subdomain is slug.
+func (a *App) GetTenantBySlug(slug string) (*entity.Tenant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tenantLookupTimeout)
+	defer cancel()
+
+	return a.tenantBySlug(ctx, slug)
+}
+
+func (a *App) tenantBySlug(ctx context.Context, slug string) (*entity.Tenant, error) {
+	return database.NewDatabaseReader(a.SystemMongoDBClient.MongoDBClient, a.SystemMongoDBClient.DatabaseName).GetTenantBySlug(ctx, slug)
+}
+
+// GetTenantByDomain returns the tenant that verified the custom domain.
+func (a *App) GetTenantByDomain(domain string) (*entity.Tenant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tenantLookupTimeout)
+	defer cancel()
+
+	return a.tenantByDomain(ctx, domain)
+}
+
+func (a *App) tenantByDomain(ctx context.Context, domain string) (*entity.Tenant, error) {
+	return database.NewDatabaseReader(a.SystemMongoDBClient.MongoDBClient, a.SystemMongoDBClient.DatabaseName).GetTenantByCustomDomain(ctx, domain)
+}
+
+func (a *App) GetTenantResolver() *TenantResolver {
+	return a.Resolver
+}
 
-	err := a.SystemMongoDBClient.MongoDBClient.Database(a.SystemMongoDBClient.DatabaseName).
-		Collection("tenants").
-		FindOne(context.Background(), bson.M{"name": tenantName}).Decode(&tenant)
+func (a *App) GetIdempotency() *Idempotency {
+	return a.Idempotency
+}
 
+func (a *App) tenantIdempotencyStore(tenantID string) (database.DBReader, database.DBWriter, error) {
+	client, err := a.GetTenantMongoDBClient(tenantID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return &tenant, nil
+	return client.DBReader(), client.DBWriter(), nil
 }
 
+// GetTenantMongoDBClient returns the connection to the database of the tenant, as long as its
+// requests can be served (see CheckTenant).
 func (a *App) GetTenantMongoDBClient(tenantID string) (*TenantMongoDB, error) {
 
-	client, ok := a.TenantsMongoDBClients[tenantID]
-	if !ok {
-		return nil, fmt.Errorf("invalid tenant id")
+	client, err := a.tenantMongoDB(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.check(); err != nil {
+		return nil, err
 	}
 
 	return client, nil
 }
 
-func NewApp(ctx context.Context) IApp {
-	// Store all mongodb clients for each of the customers
-	mongoDBClients := make(map[string]*TenantMongoDB)
+// GetTenantMongoDBClientOfAnyStatus returns the connection to the database of the tenant whatever
+// its status, so that suspended tenants can be administered too. It fails with
+// mongo.ErrNoDocuments when the tenant is unknown.
+func (a *App) GetTenantMongoDBClientOfAnyStatus(tenantID string) (*TenantMongoDB, error) {
+	client, err := a.tenantMongoDB(tenantID)
+	if errors.Is(err, util.ErrTenantHeaderIsInvalid) {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	return client, err
+}
+
+// CheckTenant returns util.ErrTenantIsSuspended or util.ErrTenantIsOffboarded when the requests of
+// the tenant cannot be served. Tenants that cannot be found are left to GetTenantMongoDBClient.
+func (a *App) CheckTenant(tenantID string) error {
+	client, err := a.tenantMongoDB(tenantID)
+	if err != nil {
+		return nil
+	}
+
+	return client.check()
+}
+
+// APICallsPerMinute returns how many requests the plan of the tenant allows per minute, zero when
+// they are unlimited or the tenant cannot be found.
+func (a *App) APICallsPerMinute(tenantID string) int64 {
+	client, err := a.tenantMongoDB(tenantID)
+	if err != nil {
+		return 0
+	}
+
+	return client.Plan.MaxAPICallsPerMinute
+}
+
+func (t *TenantMongoDB) check() error {
+	switch t.Status {
+	case entity.TenantStatusSuspended:
+		return util.ErrTenantIsSuspended
+	case entity.TenantStatusOffboarded:
+		return util.ErrTenantIsOffboarded
+	default:
+		return nil
+	}
+}
+
+func (a *App) GetDefaultTenantMongoDBURI() string {
+	return a.DefaultTenantMongoDBURI
+}
+
+func (a *App) GetKeyProvider() security.KeyProvider {
+	return a.Keys
+}
+
+// ConnectTenantMongoDB opens a new connection to the cluster of tenant and checks it works. The
+// connection string of tenant may be encrypted or not.
+func (a *App) ConnectTenantMongoDB(ctx context.Context, tenant *entity.Tenant) (*TenantMongoDB, error) {
+	tenant, err := a.decryptTenant(tenant)
+	if err != nil {
+		return nil, err
+	}
 
+	return connectTenantMongoDB(ctx, tenant)
+}
+
+// decryptTenant returns a copy of tenant with its connection string decrypted.
+func (a *App) decryptTenant(tenant *entity.Tenant) (*entity.Tenant, error) {
+	connectionString, err := security.Decrypt(a.Keys, tenant.MongoDBConnectionString, tenant.ID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("error while decrypting connection string of '%s' customer database: %w", tenant.Name, err)
+	}
+
+	decrypted := *tenant
+	decrypted.MongoDBConnectionString = connectionString
+	return &decrypted, nil
+}
+
+// NewSystemMongoDB connects to the system database only, for the commands that do not serve
+// tenants.
+func NewSystemMongoDB(ctx context.Context) (*SystemMongoDB, error) {
+	c, err := config.LoadConfiguration()
+	if err != nil {
+		return nil, fmt.Errorf("error while loading configurations: %w", err)
+	}
+
+	return connectSystemMongoDB(ctx, c.SystemDataSource.URI)
+}
+
+func connectSystemMongoDB(ctx context.Context, uri string) (*SystemMongoDB, error) {
+	systemMongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, fmt.Errorf("error while connecting to system database: %w", err)
+	}
+
+	if err := systemMongoClient.Ping(ctx, nil); err != nil {
+		return nil, fmt.Errorf("error while checking system database connection: %w", err)
+	}
+
+	return &SystemMongoDB{
+		DatabaseName:  "neniel",
+		MongoDBClient: systemMongoClient,
+	}, nil
+}
+
+func NewApp(ctx context.Context) IApp {
 	c, err := config.LoadConfiguration()
 	if err != nil {
 		log.Logger.Error(fmt.Errorf("error while loading configurations: %w", err).Error())
 		os.Exit(1)
 	}
 
-	systemMongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(c.SystemDataSource.URI))
+	system, err := connectSystemMongoDB(ctx, c.SystemDataSource.URI)
 	if err != nil {
-		log.Logger.Error(fmt.Errorf("error while connecting to system database: %w", err).Error())
+		log.Logger.Error(err.Error())
 		os.Exit(1)
 	}
 
-	if err := systemMongoClient.Ping(ctx, nil); err != nil {
-		log.Logger.Error(fmt.Errorf("error while checking system database connection: %w", err).Error())
+	serving, dedicatedTenantID, err := servingFromEnvironment()
+	if err != nil {
+		log.Logger.Error(err.Error())
 		os.Exit(1)
 	}
 
-	tier := os.Getenv("TIER")
-
-	if tier == "diamond" {
-		_id, err := primitive.ObjectIDFromHex(os.Getenv("CUSTOMER_ID"))
-		if err != nil {
-			log.Logger.Error(fmt.Errorf("error while reading CUSTOMER_ID environment variable: %w", err).Error())
-			os.Exit(1)
-		}
-
-		var customer entity.Tenant
-		err = systemMongoClient.Database("system").Collection("customers").FindOne(ctx, bson.D{{Key: "_id", Value: _id}}).Decode(&customer)
-		if err != nil {
-			log.Logger.Error(fmt.Errorf("error while fetching database of customer '%s': %w", _id, err).Error())
-			os.Exit(1)
-		}
-
-		customerMongoDBClient, err := mongo.Connect(ctx, options.Client().ApplyURI(customer.MongoDBConnectionString))
-		if err != nil {
-			log.Logger.Error(fmt.Errorf("error while connecting to '%s' customer database: %w", customer.Name, err).Error())
-			os.Exit(1)
-		}
-		if err := customerMongoDBClient.Ping(ctx, nil); err != nil {
-			log.Logger.Error(fmt.Errorf("error while checking customer database connection: %w", err).Error())
-			os.Exit(1)
-		}
-
-		mongoDBClients[customer.ID.Hex()] = &TenantMongoDB{
-			ID:            customer.ID.Hex(),
-			DatabaseName:  customer.DatabaseName,
-			MongoDBClient: customerMongoDBClient,
-		}
-
-	} else {
-		customersCursor, err := systemMongoClient.Database("neniel").Collection("tenants").Find(ctx, bson.D{}) // TODO filter out diamond clients
-		if err != nil {
-			log.Logger.Error(fmt.Errorf("error while fetching to customers from database: %w", err).Error())
-			os.Exit(1)
-		}
-
-		customers := make([]entity.Tenant, 0)
-		err = customersCursor.All(ctx, &customers)
-		if err != nil {
-			log.Logger.Error(fmt.Errorf("error while parsing customers: %w", err).Error())
-			os.Exit(1)
-		}
-
-		mongoDBClients = loadCustomersClients(ctx, customers)
+	keys, err := security.KeyProviderFromEnvironment()
+	if err != nil {
+		log.Logger.Error(err.Error())
+		os.Exit(1)
 	}
 
-	/*
-		mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(c.MongoDB.URI))
-		if err != nil {
-			log.Logger.Error(err.Error())
-			os.Exit(1)
-		}
-		if err := mongoClient.Ping(ctx, nil); err != nil {
-			log.Logger.Error(err.Error())
-			os.Exit(1)
-		}
-		log.Logger.Info("Connected to MongoDB")
-
-		db := mongoClient.Database(util.DBName)
-
-		log.Logger.Info("Preparing 'players' collection")
-		playersColl := db.Collection(util.CollNamePlayers)
-		indexOptionsGovernmentID := options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"government_id": bson.M{"$type": "string"}})
-		indexOptionsEmail := options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"email": bson.M{"$type": "string"}})
-		indexOptionsAlias := options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"alias": bson.M{"$type": "string"}})
-
-		indexModelGovernmentID := mongo.IndexModel{
-			Keys:    bson.D{{"government_id", 1}},
-			Options: indexOptionsGovernmentID,
-		}
-
-		indexModelEmail := mongo.IndexModel{
-			Keys:    bson.D{{"email", 1}},
-			Options: indexOptionsEmail,
-		}
-
-		indexModelAlias := mongo.IndexModel{
-			Keys:    bson.D{{"alias", 1}},
-			Options: indexOptionsAlias,
-		}
-
-		indexNames, err := playersColl.Indexes().CreateMany(ctx, []mongo.IndexModel{indexModelGovernmentID, indexModelEmail, indexModelAlias})
-		if err != nil {
-			log.Logger.Error(err.Error())
-			os.Exit(1)
-		}
-
-		log.Logger.Info(fmt.Sprintf("Indexes %v created!", indexNames))
-	*/
+	if keys == nil {
+		log.Logger.Warn("neither MASTER_KEYS nor MASTER_KEYFILE are set, connection strings of new tenants are stored unencrypted")
+	}
 
 	/*
 		redisClient := redis.NewClient(&redis.Options{
@@ -189,38 +299,75 @@ func NewApp(ctx context.Context) IApp {
 		})
 		log.Logger.Info("Connected to Redis")
 	*/
-	return &App{
-		SystemMongoDBClient: &SystemMongoDB{
-			DatabaseName:  "neniel",
-			MongoDBClient: systemMongoClient,
-		},
-		TenantsMongoDBClients: mongoDBClients,
+	a := &App{
+		SystemMongoDBClient:     system,
+		TenantsMongoDBClients:   make(map[string]*TenantMongoDB),
+		Serving:                 serving,
+		DedicatedTenantID:       dedicatedTenantID,
+		DefaultTenantMongoDBURI: c.MongoDB.URI,
+		Keys:                    keys,
+		SoftDeleteRetention:     softDeleteRetention(c.SoftDelete),
+		PurgeInterval:           purgeInterval(c.SoftDelete),
+	}
+	a.Resolver = NewTenantResolver(os.Getenv("BASE_DOMAIN"), tenantResolverTTL, tenantResolverMaxHosts, a.tenantBySlug, a.tenantByDomain)
+	a.Idempotency = NewIdempotency(a.tenantIdempotencyStore, IdempotencyKeyTTL)
+
+	if err := a.RefreshTenants(ctx); err != nil {
+		log.Logger.Error(fmt.Errorf("error while fetching tenants from database: %w", err).Error())
+		os.Exit(1)
+	}
+
+	if _, ok := a.GetMongoDBClients()[dedicatedTenantID]; serving == ServingDedicatedTenant && !ok {
+		log.Logger.Error(fmt.Sprintf("tenant '%s' does not exist, is not in the diamond tier or its database is not available", dedicatedTenantID))
+		os.Exit(1)
 	}
 
+	return a
 }
 
-func loadCustomersClients(ctx context.Context, customers []entity.Tenant) map[string]*TenantMongoDB {
-	mongoDBClients := make(map[string]*TenantMongoDB)
-	for _, customer := range customers {
-		tenantMongoDBClient, err := mongo.Connect(ctx, options.Client().ApplyURI(customer.MongoDBConnectionString))
-		if err != nil {
-			log.Logger.Warn(fmt.Errorf("error while connecting to '%s' customer database: %w", customer.Name, err).Error())
-			continue
-		}
+func connectTenantMongoDB(ctx context.Context, tenant *entity.Tenant) (*TenantMongoDB, error) {
+	tenantMongoDBClient, err := mongo.Connect(ctx, options.Client().ApplyURI(tenant.MongoDBConnectionString))
+	if err != nil {
+		return nil, fmt.Errorf("error while connecting to '%s' customer database: %w", tenant.Name, err)
+	}
+
+	if err := tenantMongoDBClient.Ping(ctx, nil); err != nil {
+		tenantMongoDBClient.Disconnect(ctx)
+		return nil, fmt.Errorf("error while checking '%s' customer database connection: %w", tenant.Name, err)
+	}
 
-		if err := tenantMongoDBClient.Ping(ctx, nil); err != nil {
-			log.Logger.Warn(fmt.Errorf("error while checking '%s' customer database connection: %w", customer.Name, err).Error())
-			continue
-		}
+	return &TenantMongoDB{
+		ID:             tenant.ID.Hex(),
+		DatabaseName:   tenant.DatabaseName,
+		SharedDatabase: tenant.HasSharedDatabase(),
+		Status:         tenant.GetStatus(),
+		Plan:           tenant.GetPlan(),
+		MongoDBClient:  tenantMongoDBClient,
 
-		if _, ok := mongoDBClients[customer.ID.Hex()]; !ok {
-			mongoDBClients[customer.ID.Hex()] = &TenantMongoDB{
-				ID:            customer.ID.Hex(),
-				DatabaseName:  customer.DatabaseName,
-				MongoDBClient: tenantMongoDBClient,
-			}
-		}
+		connectionString: tenant.MongoDBConnectionString,
+	}, nil
+}
+
+// DBReader returns a reader of the database of the tenant that only sees its documents.
+func (t *TenantMongoDB) DBReader() database.DBReader {
+	if !t.SharedDatabase {
+		return database.NewDatabaseReader(t.MongoDBClient, t.DatabaseName)
 	}
 
-	return mongoDBClients
+	return database.NewSharedDatabaseReader(t.MongoDBClient, t.DatabaseName, t.tenantID())
+}
+
+// DBWriter returns a writer of the database of the tenant that only touches its documents.
+func (t *TenantMongoDB) DBWriter() database.DBWriter {
+	if !t.SharedDatabase {
+		return database.NewDatabaseWriter(t.MongoDBClient, t.DatabaseName)
+	}
+
+	return database.NewSharedDatabaseWriter(t.MongoDBClient, t.DatabaseName, t.tenantID())
+}
+
+func (t *TenantMongoDB) tenantID() primitive.ObjectID {
+	// IDs come from tenants stored in the system database, so they are always valid
+	_id, _ := primitive.ObjectIDFromHex(t.ID)
+	return _id
 }
@@ -3,7 +3,9 @@ module github.com/Neniel/gotennis/lib/app
 go 1.22.3
 
 require (
+	github.com/Neniel/gotennis/lib v0.0.0-20240602192022-f8de9f9ace57
 	github.com/Neniel/gotennis/lib/config v0.0.0-20240524221600-2e18421cb76f
+	github.com/Neniel/gotennis/lib/database v0.0.0-20240602192022-f8de9f9ace57
 	github.com/Neniel/gotennis/lib/log v0.0.0-20240524221600-2e18421cb76f
 	github.com/Neniel/gotennis/lib/util v0.0.0-20240524221600-2e18421cb76f
 	github.com/go-redis/redis v6.15.9+incompatible
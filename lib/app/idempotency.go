@@ -0,0 +1,194 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/problem"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyKeyTTL is how long the responses are replayed
+	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyKeyLease is how long a key is held by a request still being served, so that it is
+	// freed soon enough when its service stops before keeping the response
+	IdempotencyKeyLease = 5 * time.Minute
+
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// IdempotencyStore returns where the idempotency keys of a tenant are kept.
+type IdempotencyStore func(tenantID string) (database.DBReader, database.DBWriter, error)
+
+// Idempotency keeps the first response to the requests sent with an Idempotency-Key and replays it
+// when the same request is sent again with the same key, for TTL. Keys are per tenant, since they
+// are kept in the database of the tenant. Keys of requests still being served are held for Lease.
+type Idempotency struct {
+	TTL   time.Duration
+	Lease time.Duration
+
+	store IdempotencyStore
+}
+
+func NewIdempotency(store IdempotencyStore, ttl time.Duration) *Idempotency {
+	return &Idempotency{
+		TTL:   ttl,
+		Lease: IdempotencyKeyLease,
+		store: store,
+	}
+}
+
+// Middleware serves the requests without an Idempotency-Key as they are. Requests reusing a key
+// with a different method, path or body are rejected, and so are the ones sent while the first one
+// is still being served. Responses with a 5xx status are not kept, and neither are the ones of
+// handlers that panic, so that they can be retried.
+func (i *Idempotency) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := r.Header[http.CanonicalHeaderKey(IdempotencyKeyHeader)]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key[0]) == 0 || len(key[0]) > maxIdempotencyKeyLength {
+			problem.Write(w, r, util.ErrIdempotencyKeyIsInvalid)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBytes))
+		r.Body.Close()
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		dbReader, dbWriter, err := i.store(r.Header.Get("X-Tenant-ID"))
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		record := &entity.IdempotencyRecord{
+			Key:         key[0],
+			Fingerprint: fingerprint(r, body),
+			ExpiresAt:   time.Now().UTC().Add(i.Lease),
+		}
+
+		reserved, err := dbWriter.ReserveIdempotencyKey(r.Context(), record)
+		if err != nil {
+			log.Logger.Error(fmt.Errorf("could not reserve idempotency key: %w", err).Error())
+			problem.Write(w, r, err)
+			return
+		}
+
+		if !reserved {
+			replay(w, r, dbReader, record)
+			return
+		}
+
+		// The response has been sent already, so the request must not cancel keeping it
+		ctx := context.WithoutCancel(r.Context())
+
+		defer func() {
+			if p := recover(); p != nil {
+				if err := dbWriter.ReleaseIdempotencyKey(ctx, record); err != nil {
+					log.Logger.Error(fmt.Errorf("could not release idempotency key '%s': %w", record.Key, err).Error())
+				}
+				panic(p)
+			}
+		}()
+
+		// Only the headers of the handler are kept, not the ones of the middlewares, e.g. rate limits
+		before := w.Header().Clone()
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		if recorder.statusCode >= http.StatusInternalServerError {
+			err = dbWriter.ReleaseIdempotencyKey(ctx, record)
+		} else {
+			record.StatusCode = recorder.statusCode
+			record.Header = make(http.Header)
+			for name, values := range w.Header() {
+				if _, ok := before[name]; !ok {
+					record.Header[name] = values
+				}
+			}
+			record.Body = recorder.body.Bytes()
+			record.ExpiresAt = time.Now().UTC().Add(i.TTL)
+			err = dbWriter.CompleteIdempotencyKey(ctx, record)
+		}
+
+		if err != nil {
+			log.Logger.Error(fmt.Errorf("could not keep the response of idempotency key '%s': %w", record.Key, err).Error())
+		}
+	})
+}
+
+// replay sends the response kept for the key of record, as long as it was for the same request.
+func replay(w http.ResponseWriter, r *http.Request, dbReader database.DBReader, record *entity.IdempotencyRecord) {
+	kept, err := dbReader.GetIdempotencyRecord(r.Context(), record.Key)
+	if err != nil {
+		// The first request failed and released the key in the meantime, so it can be retried
+		problem.Write(w, r, util.ErrIdempotencyKeyIsInProgress)
+		return
+	}
+
+	if kept.Fingerprint != record.Fingerprint {
+		problem.Write(w, r, util.ErrIdempotencyKeyIsReused)
+		return
+	}
+
+	if !kept.IsCompleted() {
+		problem.Write(w, r, util.ErrIdempotencyKeyIsInProgress)
+		return
+	}
+
+	for name, values := range kept.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(kept.StatusCode)
+	w.Write(kept.Body)
+}
+
+func fingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.Path)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response written through it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	if !rr.wroteHeader {
+		rr.statusCode = statusCode
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
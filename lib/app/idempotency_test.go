@@ -0,0 +1,218 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func TestIdempotency_Middleware(t *testing.T) {
+	const body = `{"name":"Primera"}`
+
+	request := func(key string, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/categories", strings.NewReader(body))
+		req.Header.Set("X-Tenant-ID", "tenant")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		return req
+	}
+
+	created := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/categories/1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"1"}`))
+	})
+
+	tests := []struct {
+		name           string
+		key            string
+		body           string
+		handler        http.HandlerFunc
+		prepareMocks   func(dbReader *database.MockDBReader, dbWriter *database.MockDBWriter)
+		wantStatusCode int
+		wantBody       string
+		wantServed     bool
+	}{
+		{
+			name:           "Serves_requests_without_key",
+			handler:        created,
+			prepareMocks:   func(dbReader *database.MockDBReader, dbWriter *database.MockDBWriter) {},
+			wantStatusCode: http.StatusCreated,
+			wantServed:     true,
+		},
+		{
+			name:           "Rejects_keys_that_are_too_long",
+			key:            strings.Repeat("k", maxIdempotencyKeyLength+1),
+			handler:        created,
+			prepareMocks:   func(dbReader *database.MockDBReader, dbWriter *database.MockDBWriter) {},
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:    "Keeps_the_response_of_the_handler_for_the_TTL",
+			key:     "key",
+			handler: created,
+			prepareMocks: func(dbReader *database.MockDBReader, dbWriter *database.MockDBWriter) {
+				dbWriter.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, record *entity.IdempotencyRecord) (bool, error) {
+					if record.IsCompleted() || time.Until(record.ExpiresAt) > IdempotencyKeyLease {
+						t.Errorf("key is reserved for longer than the lease: %+v", record)
+					}
+					return true, nil
+				})
+				dbWriter.EXPECT().CompleteIdempotencyKey(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, record *entity.IdempotencyRecord) error {
+					if record.StatusCode != http.StatusCreated || string(record.Body) != `{"id":"1"}` || record.Header.Get("Location") != "/categories/1" {
+						t.Errorf("response is not kept: %+v", record)
+					}
+					if record.Header.Get("X-Ratelimit-Remaining") != "" {
+						t.Errorf("headers of the middlewares are kept: %v", record.Header)
+					}
+					if time.Until(record.ExpiresAt) <= IdempotencyKeyLease {
+						t.Errorf("response is not kept for the TTL: %v", record.ExpiresAt)
+					}
+					return nil
+				})
+			},
+			wantStatusCode: http.StatusCreated,
+			wantBody:       `{"id":"1"}`,
+			wantServed:     true,
+		},
+		{
+			name:    "Replays_the_kept_response",
+			key:     "key",
+			handler: created,
+			prepareMocks: func(dbReader *database.MockDBReader, dbWriter *database.MockDBWriter) {
+				dbWriter.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any()).Return(false, nil)
+				dbReader.EXPECT().GetIdempotencyRecord(gomock.Any(), "key").Return(&entity.IdempotencyRecord{
+					Key:         "key",
+					Fingerprint: fingerprint(request("key", body), []byte(body)),
+					StatusCode:  http.StatusCreated,
+					Body:        []byte(`{"id":"1"}`),
+				}, nil)
+			},
+			wantStatusCode: http.StatusCreated,
+			wantBody:       `{"id":"1"}`,
+		},
+		{
+			name:    "Rejects_keys_reused_with_another_body",
+			key:     "key",
+			body:    `{"name":"Segunda"}`,
+			handler: created,
+			prepareMocks: func(dbReader *database.MockDBReader, dbWriter *database.MockDBWriter) {
+				dbWriter.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any()).Return(false, nil)
+				dbReader.EXPECT().GetIdempotencyRecord(gomock.Any(), "key").Return(&entity.IdempotencyRecord{
+					Key:         "key",
+					Fingerprint: fingerprint(request("key", body), []byte(body)),
+					StatusCode:  http.StatusCreated,
+				}, nil)
+			},
+			wantStatusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "Rejects_keys_of_requests_still_being_served",
+			key:     "key",
+			handler: created,
+			prepareMocks: func(dbReader *database.MockDBReader, dbWriter *database.MockDBWriter) {
+				dbWriter.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any()).Return(false, nil)
+				dbReader.EXPECT().GetIdempotencyRecord(gomock.Any(), "key").Return(&entity.IdempotencyRecord{
+					Key:         "key",
+					Fingerprint: fingerprint(request("key", body), []byte(body)),
+				}, nil)
+			},
+			wantStatusCode: http.StatusConflict,
+		},
+		{
+			name:    "Rejects_keys_released_meanwhile",
+			key:     "key",
+			handler: created,
+			prepareMocks: func(dbReader *database.MockDBReader, dbWriter *database.MockDBWriter) {
+				dbWriter.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any()).Return(false, nil)
+				dbReader.EXPECT().GetIdempotencyRecord(gomock.Any(), "key").Return(nil, mongo.ErrNoDocuments)
+			},
+			wantStatusCode: http.StatusConflict,
+		},
+		{
+			name: "Releases_the_key_of_server_errors",
+			key:  "key",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			prepareMocks: func(dbReader *database.MockDBReader, dbWriter *database.MockDBWriter) {
+				dbWriter.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any()).Return(true, nil)
+				dbWriter.EXPECT().ReleaseIdempotencyKey(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantStatusCode: http.StatusInternalServerError,
+			wantServed:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbReader := database.NewMockDBReader(gomock.NewController(t))
+			dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+			tt.prepareMocks(dbReader, dbWriter)
+
+			i := NewIdempotency(func(tenantID string) (database.DBReader, database.DBWriter, error) {
+				return dbReader, dbWriter, nil
+			}, IdempotencyKeyTTL)
+
+			var served bool
+			handler := i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				served = true
+				tt.handler(w, r)
+			}))
+
+			reqBody := tt.body
+			if reqBody == "" {
+				reqBody = body
+			}
+
+			w := httptest.NewRecorder()
+			w.Header().Set("X-Ratelimit-Remaining", "59")
+			handler.ServeHTTP(w, request(tt.key, reqBody))
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("Idempotency.Middleware() status = %v, want %v", w.Code, tt.wantStatusCode)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("Idempotency.Middleware() body = %v, want %v", w.Body.String(), tt.wantBody)
+			}
+			if served != tt.wantServed {
+				t.Errorf("Idempotency.Middleware() served = %v, want %v", served, tt.wantServed)
+			}
+		})
+	}
+}
+
+func TestIdempotency_Middleware_ReleasesKeyWhenHandlerPanics(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+
+	dbWriter.EXPECT().ReserveIdempotencyKey(gomock.Any(), gomock.Any()).Return(true, nil)
+	dbWriter.EXPECT().ReleaseIdempotencyKey(gomock.Any(), gomock.Any()).Return(nil)
+
+	i := NewIdempotency(func(tenantID string) (database.DBReader, database.DBWriter, error) {
+		return dbReader, dbWriter, nil
+	}, IdempotencyKeyTTL)
+
+	handler := i.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("handler failed")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/categories", strings.NewReader(`{}`))
+	req.Header.Set(IdempotencyKeyHeader, "key")
+
+	defer func() {
+		if p := recover(); p != "handler failed" {
+			t.Errorf("Idempotency.Middleware() recovered %v, want the panic of the handler", p)
+		}
+	}()
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Neniel/gotennis/lib/database/migration"
+	"github.com/Neniel/gotennis/lib/log"
+)
+
+// Migrate runs the migrations of every tenant database towards target, see migration.Runner.Run.
+// Tenants are migrated one after the other and a failure in one of them does not stop the rest;
+// it is reported in its result instead. Databases shared by several tenants are migrated once, and
+// all of them get the same result.
+func (a *App) Migrate(ctx context.Context, direction migration.Direction, target int, dryRun bool) map[string]*migration.Result {
+	runner := migration.NewRunner(migration.All)
+
+	results := make(map[string]*migration.Result)
+	// migrated holds the results by cluster and database
+	migrated := make(map[[2]string]*migration.Result)
+	for tenantID, client := range a.GetMongoDBClients() {
+		database := [2]string{client.connectionString, client.DatabaseName}
+		if result, ok := migrated[database]; ok {
+			results[tenantID] = result
+			continue
+		}
+
+		result, err := runner.Run(ctx, client.MongoDBClient.Database(client.DatabaseName), direction, target, dryRun)
+		if result == nil {
+			result = &migration.Result{}
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+			log.Logger.Error(fmt.Errorf("error while migrating database of tenant '%s': %w", tenantID, err).Error())
+		} else if !dryRun && len(result.Steps) > 0 {
+			log.Logger.Info(fmt.Sprintf("database of tenant '%s' migrated from version %d to %d", tenantID, result.From, result.To))
+		}
+
+		results[tenantID] = result
+		migrated[database] = result
+	}
+
+	return results
+}
@@ -10,8 +10,12 @@
 package app
 
 import (
+	context "context"
 	reflect "reflect"
 
+	migration "github.com/Neniel/gotennis/lib/database/migration"
+	entity "github.com/Neniel/gotennis/lib/entity"
+	security "github.com/Neniel/gotennis/lib/security"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -38,6 +42,91 @@ func (m *MockIApp) EXPECT() *MockIAppMockRecorder {
 	return m.recorder
 }
 
+// APICallsPerMinute mocks base method.
+func (m *MockIApp) APICallsPerMinute(tenantID string) int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APICallsPerMinute", tenantID)
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// APICallsPerMinute indicates an expected call of APICallsPerMinute.
+func (mr *MockIAppMockRecorder) APICallsPerMinute(tenantID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APICallsPerMinute", reflect.TypeOf((*MockIApp)(nil).APICallsPerMinute), tenantID)
+}
+
+// CheckTenant mocks base method.
+func (m *MockIApp) CheckTenant(tenantID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckTenant", tenantID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckTenant indicates an expected call of CheckTenant.
+func (mr *MockIAppMockRecorder) CheckTenant(tenantID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckTenant", reflect.TypeOf((*MockIApp)(nil).CheckTenant), tenantID)
+}
+
+// ConnectTenantMongoDB mocks base method.
+func (m *MockIApp) ConnectTenantMongoDB(ctx context.Context, tenant *entity.Tenant) (*TenantMongoDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConnectTenantMongoDB", ctx, tenant)
+	ret0, _ := ret[0].(*TenantMongoDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConnectTenantMongoDB indicates an expected call of ConnectTenantMongoDB.
+func (mr *MockIAppMockRecorder) ConnectTenantMongoDB(ctx, tenant any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConnectTenantMongoDB", reflect.TypeOf((*MockIApp)(nil).ConnectTenantMongoDB), ctx, tenant)
+}
+
+// GetDefaultTenantMongoDBURI mocks base method.
+func (m *MockIApp) GetDefaultTenantMongoDBURI() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDefaultTenantMongoDBURI")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetDefaultTenantMongoDBURI indicates an expected call of GetDefaultTenantMongoDBURI.
+func (mr *MockIAppMockRecorder) GetDefaultTenantMongoDBURI() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDefaultTenantMongoDBURI", reflect.TypeOf((*MockIApp)(nil).GetDefaultTenantMongoDBURI))
+}
+
+// GetIdempotency mocks base method.
+func (m *MockIApp) GetIdempotency() *Idempotency {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotency")
+	ret0, _ := ret[0].(*Idempotency)
+	return ret0
+}
+
+// GetIdempotency indicates an expected call of GetIdempotency.
+func (mr *MockIAppMockRecorder) GetIdempotency() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotency", reflect.TypeOf((*MockIApp)(nil).GetIdempotency))
+}
+
+// GetKeyProvider mocks base method.
+func (m *MockIApp) GetKeyProvider() security.KeyProvider {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyProvider")
+	ret0, _ := ret[0].(security.KeyProvider)
+	return ret0
+}
+
+// GetKeyProvider indicates an expected call of GetKeyProvider.
+func (mr *MockIAppMockRecorder) GetKeyProvider() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyProvider", reflect.TypeOf((*MockIApp)(nil).GetKeyProvider))
+}
+
 // GetMongoDBClients mocks base method.
 func (m *MockIApp) GetMongoDBClients() map[string]*TenantMongoDB {
 	m.ctrl.T.Helper()
@@ -65,3 +154,137 @@ func (mr *MockIAppMockRecorder) GetSystemMongoDBClient() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSystemMongoDBClient", reflect.TypeOf((*MockIApp)(nil).GetSystemMongoDBClient))
 }
+
+// GetTenantByDomain mocks base method.
+func (m *MockIApp) GetTenantByDomain(domain string) (*entity.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantByDomain", domain)
+	ret0, _ := ret[0].(*entity.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantByDomain indicates an expected call of GetTenantByDomain.
+func (mr *MockIAppMockRecorder) GetTenantByDomain(domain any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantByDomain", reflect.TypeOf((*MockIApp)(nil).GetTenantByDomain), domain)
+}
+
+// GetTenantBySlug mocks base method.
+func (m *MockIApp) GetTenantBySlug(slug string) (*entity.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantBySlug", slug)
+	ret0, _ := ret[0].(*entity.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantBySlug indicates an expected call of GetTenantBySlug.
+func (mr *MockIAppMockRecorder) GetTenantBySlug(slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantBySlug", reflect.TypeOf((*MockIApp)(nil).GetTenantBySlug), slug)
+}
+
+// GetTenantMongoDBClient mocks base method.
+func (m *MockIApp) GetTenantMongoDBClient(tenantID string) (*TenantMongoDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantMongoDBClient", tenantID)
+	ret0, _ := ret[0].(*TenantMongoDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantMongoDBClient indicates an expected call of GetTenantMongoDBClient.
+func (mr *MockIAppMockRecorder) GetTenantMongoDBClient(tenantID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantMongoDBClient", reflect.TypeOf((*MockIApp)(nil).GetTenantMongoDBClient), tenantID)
+}
+
+// GetTenantMongoDBClientOfAnyStatus mocks base method.
+func (m *MockIApp) GetTenantMongoDBClientOfAnyStatus(tenantID string) (*TenantMongoDB, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantMongoDBClientOfAnyStatus", tenantID)
+	ret0, _ := ret[0].(*TenantMongoDB)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantMongoDBClientOfAnyStatus indicates an expected call of GetTenantMongoDBClientOfAnyStatus.
+func (mr *MockIAppMockRecorder) GetTenantMongoDBClientOfAnyStatus(tenantID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantMongoDBClientOfAnyStatus", reflect.TypeOf((*MockIApp)(nil).GetTenantMongoDBClientOfAnyStatus), tenantID)
+}
+
+// GetTenantResolver mocks base method.
+func (m *MockIApp) GetTenantResolver() *TenantResolver {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantResolver")
+	ret0, _ := ret[0].(*TenantResolver)
+	return ret0
+}
+
+// GetTenantResolver indicates an expected call of GetTenantResolver.
+func (mr *MockIAppMockRecorder) GetTenantResolver() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantResolver", reflect.TypeOf((*MockIApp)(nil).GetTenantResolver))
+}
+
+// Migrate mocks base method.
+func (m *MockIApp) Migrate(ctx context.Context, direction migration.Direction, target int, dryRun bool) map[string]*migration.Result {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Migrate", ctx, direction, target, dryRun)
+	ret0, _ := ret[0].(map[string]*migration.Result)
+	return ret0
+}
+
+// Migrate indicates an expected call of Migrate.
+func (mr *MockIAppMockRecorder) Migrate(ctx, direction, target, dryRun any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Migrate", reflect.TypeOf((*MockIApp)(nil).Migrate), ctx, direction, target, dryRun)
+}
+
+// StartPurge mocks base method.
+func (m *MockIApp) StartPurge(ctx context.Context, collections ...string) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range collections {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "StartPurge", varargs...)
+}
+
+// StartPurge indicates an expected call of StartPurge.
+func (mr *MockIAppMockRecorder) StartPurge(ctx any, collections ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, collections...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartPurge", reflect.TypeOf((*MockIApp)(nil).StartPurge), varargs...)
+}
+
+// StartSystemPurge mocks base method.
+func (m *MockIApp) StartSystemPurge(ctx context.Context, collections ...string) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx}
+	for _, a := range collections {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "StartSystemPurge", varargs...)
+}
+
+// StartSystemPurge indicates an expected call of StartSystemPurge.
+func (mr *MockIAppMockRecorder) StartSystemPurge(ctx any, collections ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx}, collections...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSystemPurge", reflect.TypeOf((*MockIApp)(nil).StartSystemPurge), varargs...)
+}
+
+// WatchTenants mocks base method.
+func (m *MockIApp) WatchTenants(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "WatchTenants", ctx)
+}
+
+// WatchTenants indicates an expected call of WatchTenants.
+func (mr *MockIAppMockRecorder) WatchTenants(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchTenants", reflect.TypeOf((*MockIApp)(nil).WatchTenants), ctx)
+}
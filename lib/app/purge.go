@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/config"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/log"
+)
+
+const (
+	defaultSoftDeleteRetention = 30 * 24 * time.Hour
+	defaultPurgeInterval       = time.Hour
+)
+
+func softDeleteRetention(c config.SoftDelete) time.Duration {
+	if c.RetentionDays <= 0 {
+		return defaultSoftDeleteRetention
+	}
+
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+func purgeInterval(c config.SoftDelete) time.Duration {
+	if c.PurgeIntervalMinutes <= 0 {
+		return defaultPurgeInterval
+	}
+
+	return time.Duration(c.PurgeIntervalMinutes) * time.Minute
+}
+
+// StartPurge permanently deletes, on every tenant database, the documents of the given collections
+// that were soft deleted longer ago than the retention period. It blocks until ctx is done.
+func (a *App) StartPurge(ctx context.Context, collections ...string) {
+	a.runPurge(ctx, func() map[string]database.DBWriter {
+		writers := make(map[string]database.DBWriter)
+		for tenantID, client := range a.GetMongoDBClients() {
+			writers[tenantID] = client.DBWriter()
+		}
+		return writers
+	}, collections)
+}
+
+// StartSystemPurge works like StartPurge but on the system database.
+func (a *App) StartSystemPurge(ctx context.Context, collections ...string) {
+	a.runPurge(ctx, func() map[string]database.DBWriter {
+		return map[string]database.DBWriter{
+			a.SystemMongoDBClient.DatabaseName: database.NewDatabaseWriter(a.SystemMongoDBClient.MongoDBClient, a.SystemMongoDBClient.DatabaseName),
+		}
+	}, collections)
+}
+
+func (a *App) runPurge(ctx context.Context, writers func() map[string]database.DBWriter, collections []string) {
+	ticker := time.NewTicker(a.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		deletedBefore := time.Now().UTC().Add(-a.SoftDeleteRetention)
+		for name, dbWriter := range writers() {
+			for _, collection := range collections {
+				purged, err := dbWriter.PurgeDeleted(ctx, collection, deletedBefore)
+				if err != nil {
+					log.Logger.Warn(fmt.Errorf("error while purging deleted documents of '%s' collection in '%s': %w", collection, name, err).Error())
+					continue
+				}
+
+				if purged > 0 {
+					log.Logger.Info(fmt.Sprintf("purged %d deleted documents of '%s' collection in '%s'", purged, collection, name))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/problem"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	tenantResolverTTL = time.Minute
+	// tenantResolverMaxHosts bounds the hosts kept by the resolver, since they come from clients
+	tenantResolverMaxHosts = 10000
+	tenantLookupTimeout    = 2 * time.Second
+)
+
+// TenantResolver finds the tenant a request is for from its Host, which is either
+// <tenant slug>.<BaseDomain> or a custom domain verified by the tenant. Hosts are cached for TTL,
+// including the ones of no tenant, up to MaxHosts, evicting the least recently used ones.
+type TenantResolver struct {
+	BaseDomain string
+	TTL        time.Duration
+	MaxHosts   int
+
+	bySlug   func(ctx context.Context, slug string) (*entity.Tenant, error)
+	byDomain func(ctx context.Context, domain string) (*entity.Tenant, error)
+
+	mu    sync.Mutex
+	hosts map[string]*list.Element
+	// recent holds the *resolvedHost of hosts, the most recently used first
+	recent *list.List
+}
+
+type resolvedHost struct {
+	host      string
+	tenant    *entity.Tenant
+	expiresAt time.Time
+}
+
+func NewTenantResolver(baseDomain string, ttl time.Duration, maxHosts int, bySlug func(context.Context, string) (*entity.Tenant, error), byDomain func(context.Context, string) (*entity.Tenant, error)) *TenantResolver {
+	return &TenantResolver{
+		BaseDomain: strings.ToLower(strings.Trim(baseDomain, ".")),
+		TTL:        ttl,
+		MaxHosts:   maxHosts,
+		bySlug:     bySlug,
+		byDomain:   byDomain,
+		hosts:      make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+// Resolve returns the tenant of host, or nil when host is not the domain of any tenant. Hosts that
+// are not well-formed domain names are never looked up.
+func (r *TenantResolver) Resolve(ctx context.Context, host string) (*entity.Tenant, error) {
+	host = normalizeHost(host)
+	if !util.IsDomainName(host) {
+		return nil, nil
+	}
+
+	now := time.Now()
+	if resolved, ok := r.cached(host); ok && now.Before(resolved.expiresAt) {
+		return resolved.tenant, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tenantLookupTimeout)
+	defer cancel()
+
+	tenant, err := r.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	r.cache(&resolvedHost{host: host, tenant: tenant, expiresAt: now.Add(r.TTL)})
+	return tenant, nil
+}
+
+func (r *TenantResolver) cached(host string) (*resolvedHost, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := r.hosts[host]
+	if !ok {
+		return nil, false
+	}
+
+	r.recent.MoveToFront(element)
+	return element.Value.(*resolvedHost), true
+}
+
+func (r *TenantResolver) cache(resolved *resolvedHost) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if element, ok := r.hosts[resolved.host]; ok {
+		element.Value = resolved
+		r.recent.MoveToFront(element)
+		return
+	}
+
+	r.hosts[resolved.host] = r.recent.PushFront(resolved)
+	for r.recent.Len() > r.MaxHosts {
+		oldest := r.recent.Back()
+		r.recent.Remove(oldest)
+		delete(r.hosts, oldest.Value.(*resolvedHost).host)
+	}
+}
+
+func (r *TenantResolver) lookup(ctx context.Context, host string) (*entity.Tenant, error) {
+	var (
+		tenant *entity.Tenant
+		err    error
+	)
+
+	if r.BaseDomain != "" && host == r.BaseDomain {
+		return nil, nil
+	}
+
+	if slug, ok := strings.CutSuffix(host, "."+r.BaseDomain); ok && r.BaseDomain != "" {
+		// Only the first label below the base domain can be the subdomain of a tenant
+		if !util.IsDomainLabel(slug) {
+			return nil, nil
+		}
+
+		tenant, err = r.bySlug(ctx, slug)
+	} else {
+		tenant, err = r.byDomain(ctx, host)
+	}
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	return tenant, err
+}
+
+// Middleware sets the X-Tenant-ID and X-Tenant-Name headers of the requests whose Host is the
+// domain of a tenant, replacing the ones sent by the client. The rest of the requests are left as
+// they are. Requests whose Host cannot be looked up fail with util.ErrTenantLookupIsNotAvailable
+// rather than being served for the tenant in the headers of the client.
+func (r *TenantResolver) Middleware(next http.Handler) http.Handler {
+	f := func(w http.ResponseWriter, req *http.Request) {
+		tenant, err := r.Resolve(req.Context(), req.Host)
+		if err != nil {
+			log.Logger.Error(fmt.Errorf("error while resolving tenant of host '%s': %w", req.Host, err).Error())
+			problem.Write(w, req, util.ErrTenantLookupIsNotAvailable)
+			return
+		}
+
+		if tenant != nil {
+			req.Header.Set("X-Tenant-ID", tenant.ID.Hex())
+			req.Header.Set("X-Tenant-Name", tenant.Slug)
+		}
+
+		next.ServeHTTP(w, req)
+	}
+
+	return http.HandlerFunc(f)
+}
+
+// normalizeHost returns host in lower case, without port nor trailing dot.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,184 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func TestTenantResolver_Resolve(t *testing.T) {
+	tenant := &entity.Tenant{ID: primitive.NewObjectID(), Slug: "club"}
+
+	tests := []struct {
+		name         string
+		hosts        []string
+		ttl          time.Duration
+		maxHosts     int
+		prepareMocks func(dbReader *database.MockDBReader)
+		want         *entity.Tenant
+		wantErr      bool
+	}{
+		{
+			name:  "Resolves_subdomains_once_while_cached",
+			hosts: []string{"club.ourapp.com", "CLUB.ourapp.com:443", "club.ourapp.com."},
+			ttl:   time.Minute,
+			prepareMocks: func(dbReader *database.MockDBReader) {
+				dbReader.EXPECT().GetTenantBySlug(gomock.Any(), "club").Return(tenant, nil).Times(1)
+			},
+			want: tenant,
+		},
+		{
+			name:  "Resolves_verified_custom_domains",
+			hosts: []string{"www.club.com"},
+			ttl:   time.Minute,
+			prepareMocks: func(dbReader *database.MockDBReader) {
+				dbReader.EXPECT().GetTenantByCustomDomain(gomock.Any(), "www.club.com").Return(tenant, nil)
+			},
+			want: tenant,
+		},
+		{
+			name:  "Caches_hosts_of_no_tenant",
+			hosts: []string{"other.ourapp.com", "other.ourapp.com"},
+			ttl:   time.Minute,
+			prepareMocks: func(dbReader *database.MockDBReader) {
+				dbReader.EXPECT().GetTenantBySlug(gomock.Any(), "other").Return(nil, mongo.ErrNoDocuments).Times(1)
+			},
+		},
+		{
+			name:  "Looks_hosts_up_again_once_expired",
+			hosts: []string{"club.ourapp.com", "club.ourapp.com"},
+			prepareMocks: func(dbReader *database.MockDBReader) {
+				dbReader.EXPECT().GetTenantBySlug(gomock.Any(), "club").Return(tenant, nil).Times(2)
+			},
+			want: tenant,
+		},
+		{
+			name:     "Evicts_the_least_recently_used_hosts",
+			hosts:    []string{"club.ourapp.com", "www.club.com", "club.ourapp.com"},
+			ttl:      time.Minute,
+			maxHosts: 1,
+			prepareMocks: func(dbReader *database.MockDBReader) {
+				dbReader.EXPECT().GetTenantBySlug(gomock.Any(), "club").Return(tenant, nil).Times(2)
+				dbReader.EXPECT().GetTenantByCustomDomain(gomock.Any(), "www.club.com").Return(tenant, nil)
+			},
+			want: tenant,
+		},
+		{
+			name:         "Never_looks_up_the_base_domain_nor_nested_subdomains",
+			hosts:        []string{"ourapp.com", "a.club.ourapp.com", "not a host"},
+			ttl:          time.Minute,
+			prepareMocks: func(dbReader *database.MockDBReader) {},
+		},
+		{
+			name:  "Does_not_cache_failed_lookups",
+			hosts: []string{"club.ourapp.com"},
+			ttl:   time.Minute,
+			prepareMocks: func(dbReader *database.MockDBReader) {
+				dbReader.EXPECT().GetTenantBySlug(gomock.Any(), "club").Return(nil, errors.New("database is down"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbReader := database.NewMockDBReader(gomock.NewController(t))
+			tt.prepareMocks(dbReader)
+
+			maxHosts := tt.maxHosts
+			if maxHosts == 0 {
+				maxHosts = tenantResolverMaxHosts
+			}
+
+			r := NewTenantResolver("OurApp.com.", tt.ttl, maxHosts, dbReader.GetTenantBySlug, dbReader.GetTenantByCustomDomain)
+
+			var (
+				got *entity.Tenant
+				err error
+			)
+			for _, host := range tt.hosts {
+				if got, err = r.Resolve(context.Background(), host); err != nil {
+					break
+				}
+			}
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TenantResolver.Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("TenantResolver.Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantResolver_Middleware(t *testing.T) {
+	tenant := &entity.Tenant{ID: primitive.NewObjectID(), Slug: "club"}
+
+	tests := []struct {
+		name           string
+		host           string
+		prepareMocks   func(dbReader *database.MockDBReader)
+		wantStatusCode int
+		wantTenantID   string
+	}{
+		{
+			name: "Replaces_the_tenant_sent_by_the_client",
+			host: "club.ourapp.com",
+			prepareMocks: func(dbReader *database.MockDBReader) {
+				dbReader.EXPECT().GetTenantBySlug(gomock.Any(), "club").Return(tenant, nil)
+			},
+			wantStatusCode: http.StatusOK,
+			wantTenantID:   tenant.ID.Hex(),
+		},
+		{
+			name:           "Keeps_the_tenant_sent_by_the_client_on_other_hosts",
+			host:           "ourapp.com",
+			prepareMocks:   func(dbReader *database.MockDBReader) {},
+			wantStatusCode: http.StatusOK,
+			wantTenantID:   "client",
+		},
+		{
+			name: "Fails_when_the_tenant_cannot_be_looked_up",
+			host: "club.ourapp.com",
+			prepareMocks: func(dbReader *database.MockDBReader) {
+				dbReader.EXPECT().GetTenantBySlug(gomock.Any(), "club").Return(nil, errors.New("database is down"))
+			},
+			wantStatusCode: http.StatusServiceUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbReader := database.NewMockDBReader(gomock.NewController(t))
+			tt.prepareMocks(dbReader)
+
+			r := NewTenantResolver("ourapp.com", time.Minute, tenantResolverMaxHosts, dbReader.GetTenantBySlug, dbReader.GetTenantByCustomDomain)
+
+			var gotTenantID string
+			handler := r.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				gotTenantID = req.Header.Get("X-Tenant-ID")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/players", nil)
+			req.Host = tt.host
+			req.Header.Set("X-Tenant-ID", "client")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatusCode {
+				t.Errorf("TenantResolver.Middleware() status = %v, want %v", w.Code, tt.wantStatusCode)
+			}
+			if gotTenantID != tt.wantTenantID {
+				t.Errorf("TenantResolver.Middleware() tenant = %v, want %v", gotTenantID, tt.wantTenantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	tenantConnectTimeout      = 10 * time.Second
+	tenantDisconnectTimeout   = 30 * time.Second
+	tenantsWatchRetryInterval = 30 * time.Second
+	// tenantMissTTL is how long the tenants that are unknown or cannot be reached are not looked up
+	// again, and tenantMaxMisses how many of them are kept, since their IDs come from clients
+	tenantMissTTL   = 30 * time.Second
+	tenantMaxMisses = 10000
+)
+
+type tenantMiss struct {
+	err       error
+	expiresAt time.Time
+}
+
+// tenantChange is the part of a change event of the tenants collection the App cares about.
+type tenantChange struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ID primitive.ObjectID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *entity.Tenant `bson:"fullDocument"`
+}
+
+func (a *App) tenantsCollection() *mongo.Collection {
+	return a.SystemMongoDBClient.MongoDBClient.Database(a.SystemMongoDBClient.DatabaseName).Collection("tenants")
+}
+
+// tenantMongoDB returns the connection to the database of the tenant, connecting to it first when
+// the tenant is not known yet, e.g. because it was created after the App started. It fails with
+// util.ErrTenantHeaderIsInvalid when the tenant is unknown and with
+// util.ErrTenantDatabaseIsNotAvailable when it cannot be reached, and does so again for
+// tenantMissTTL.
+func (a *App) tenantMongoDB(tenantID string) (*TenantMongoDB, error) {
+	if client, err := a.knownTenant(tenantID); client != nil || err != nil {
+		return client, err
+	}
+
+	_id, err := primitive.ObjectIDFromHex(tenantID)
+	if err != nil {
+		return nil, util.ErrTenantHeaderIsInvalid
+	}
+
+	// The tenant is read while holding its lock, so that a change of the tenant applied meanwhile,
+	// e.g. its removal, is not undone with what was read before it
+	lock := a.tenantLock(tenantID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if client, err := a.knownTenant(tenantID); client != nil || err != nil {
+		return client, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tenantConnectTimeout)
+	defer cancel()
+
+	var tenant entity.Tenant
+	err = a.tenantsCollection().FindOne(ctx, bson.D{{Key: "_id", Value: _id}, {Key: "deleted_at", Value: nil}}).Decode(&tenant)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, a.miss(tenantID, util.ErrTenantHeaderIsInvalid)
+	}
+
+	if err != nil {
+		log.Logger.Error(fmt.Errorf("error while fetching tenant '%s': %w", tenantID, err).Error())
+		return nil, util.ErrTenantDatabaseIsNotAvailable
+	}
+
+	// e.g. a diamond tenant reaching the shared deployments
+	if !a.serves(&tenant) {
+		return nil, a.miss(tenantID, util.ErrTenantHeaderIsInvalid)
+	}
+
+	client, err := a.storeTenant(ctx, &tenant)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return nil, a.miss(tenantID, util.ErrTenantDatabaseIsNotAvailable)
+	}
+
+	return client, nil
+}
+
+// knownTenant returns the connection to the database of the tenant, or the error it failed with
+// lately, or neither when the tenant has to be looked up.
+func (a *App) knownTenant(tenantID string) (*TenantMongoDB, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if client, ok := a.TenantsMongoDBClients[tenantID]; ok {
+		return client, nil
+	}
+
+	if miss, ok := a.tenantMisses[tenantID]; ok && time.Now().Before(miss.expiresAt) {
+		return nil, miss.err
+	}
+
+	return nil, nil
+}
+
+// miss keeps err as the error of the tenant for tenantMissTTL, and returns it.
+func (a *App) miss(tenantID string, err error) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if a.tenantMisses == nil || len(a.tenantMisses) >= tenantMaxMisses {
+		misses := make(map[string]tenantMiss)
+		for id, miss := range a.tenantMisses {
+			if now.Before(miss.expiresAt) && len(misses) < tenantMaxMisses/2 {
+				misses[id] = miss
+			}
+		}
+		a.tenantMisses = misses
+	}
+
+	a.tenantMisses[tenantID] = tenantMiss{err: err, expiresAt: now.Add(tenantMissTTL)}
+	return err
+}
+
+// tenantLock returns the lock of the connection to the database of the tenant.
+func (a *App) tenantLock(tenantID string) *sync.Mutex {
+	lock, _ := a.tenantLocks.LoadOrStore(tenantID, &sync.Mutex{})
+	return lock.(*sync.Mutex)
+}
+
+// setTenant stores the connection to the database of tenant, reusing the current one unless the
+// tenant moved to another cluster. Concurrent calls for the same tenant, e.g. from a refresh and
+// from a request, are serialized, so that every replaced connection is closed.
+func (a *App) setTenant(ctx context.Context, tenant *entity.Tenant) (*TenantMongoDB, error) {
+	lock := a.tenantLock(tenant.ID.Hex())
+	lock.Lock()
+	defer lock.Unlock()
+
+	return a.storeTenant(ctx, tenant)
+}
+
+// storeTenant is setTenant for the callers holding the lock of the tenant.
+func (a *App) storeTenant(ctx context.Context, tenant *entity.Tenant) (*TenantMongoDB, error) {
+	tenant, err := a.decryptTenant(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.RLock()
+	current, ok := a.TenantsMongoDBClients[tenant.ID.Hex()]
+	a.mu.RUnlock()
+
+	if ok && current.connectionString == tenant.MongoDBConnectionString {
+		client := &TenantMongoDB{
+			ID:               current.ID,
+			DatabaseName:     tenant.DatabaseName,
+			SharedDatabase:   tenant.HasSharedDatabase(),
+			Status:           tenant.GetStatus(),
+			Plan:             tenant.GetPlan(),
+			MongoDBClient:    current.MongoDBClient,
+			connectionString: current.connectionString,
+		}
+
+		a.mu.Lock()
+		a.TenantsMongoDBClients[client.ID] = client
+		delete(a.tenantMisses, client.ID)
+		a.mu.Unlock()
+
+		return client, nil
+	}
+
+	client, err := connectTenantMongoDB(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	previous := a.TenantsMongoDBClients[client.ID]
+	a.TenantsMongoDBClients[client.ID] = client
+	delete(a.tenantMisses, client.ID)
+	a.mu.Unlock()
+
+	if previous != nil {
+		go disconnect(previous)
+	}
+
+	log.Logger.Info(fmt.Sprintf("connected to database of tenant '%s'", tenant.Name))
+	return client, nil
+}
+
+// removeTenant forgets the tenant and closes the connection to its database.
+func (a *App) removeTenant(tenantID string) {
+	lock := a.tenantLock(tenantID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	a.mu.Lock()
+	client, ok := a.TenantsMongoDBClients[tenantID]
+	delete(a.TenantsMongoDBClients, tenantID)
+	a.mu.Unlock()
+
+	if ok {
+		log.Logger.Info(fmt.Sprintf("disconnecting from database of removed tenant '%s'", tenantID))
+		go disconnect(client)
+	}
+}
+
+// disconnect closes client once the requests still using it are done, or the timeout expires.
+func disconnect(client *TenantMongoDB) {
+	ctx, cancel := context.WithTimeout(context.Background(), tenantDisconnectTimeout)
+	defer cancel()
+
+	if err := client.MongoDBClient.Disconnect(ctx); err != nil {
+		log.Logger.Warn(fmt.Errorf("error while disconnecting from database of tenant '%s': %w", client.ID, err).Error())
+	}
+}
+
+// RefreshTenants brings the served tenants in line with the system database: new tenants are
+// connected, changed ones updated and removed ones disconnected.
+func (a *App) RefreshTenants(ctx context.Context) error {
+	filter := bson.D{{Key: "deleted_at", Value: nil}}
+	if a.Serving == ServingDedicatedTenant {
+		_id, err := primitive.ObjectIDFromHex(a.DedicatedTenantID)
+		if err != nil {
+			return err
+		}
+		filter = append(filter, bson.E{Key: "_id", Value: _id})
+	}
+
+	cursor, err := a.tenantsCollection().Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	tenants := make([]entity.Tenant, 0)
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return err
+	}
+
+	found := make(map[string]bool)
+	for _, tenant := range tenants {
+		if !a.serves(&tenant) {
+			continue
+		}
+
+		found[tenant.ID.Hex()] = true
+		if _, err := a.setTenant(ctx, &tenant); err != nil {
+			log.Logger.Warn(err.Error())
+		}
+	}
+
+	for tenantID := range a.GetMongoDBClients() {
+		if !found[tenantID] {
+			a.removeTenant(tenantID)
+		}
+	}
+
+	return nil
+}
+
+// WatchTenants applies the changes of the tenants collection of the system database as soon as
+// they happen. When the changes cannot be watched, e.g. because the system database is not a
+// replica set, it falls back to refreshing every tenant periodically. It blocks until ctx is done.
+func (a *App) WatchTenants(ctx context.Context) {
+	for {
+		// Changes made while not watching are picked up here
+		if err := a.RefreshTenants(ctx); err != nil {
+			log.Logger.Warn(fmt.Errorf("error while refreshing tenants: %w", err).Error())
+		}
+
+		if err := a.watchTenants(ctx); err != nil {
+			log.Logger.Warn(fmt.Errorf("error while watching tenants: %w", err).Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(tenantsWatchRetryInterval):
+		}
+	}
+}
+
+func (a *App) watchTenants(ctx context.Context) error {
+	stream, err := a.tenantsCollection().Watch(ctx, mongo.Pipeline{}, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change tenantChange
+		if err := stream.Decode(&change); err != nil {
+			return err
+		}
+
+		tenantID := change.DocumentKey.ID.Hex()
+		switch change.OperationType {
+		case "insert", "update", "replace":
+			// Tenants moved to or from the diamond tier change the deployments serving them
+			if change.FullDocument == nil || change.FullDocument.DeletedAt != nil || !a.serves(change.FullDocument) {
+				a.removeTenant(tenantID)
+				continue
+			}
+
+			if _, err := a.setTenant(ctx, change.FullDocument); err != nil {
+				log.Logger.Warn(err.Error())
+			}
+		case "delete":
+			a.removeTenant(tenantID)
+		}
+	}
+
+	return stream.Err()
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const testTenantConnectionString = "mongodb://localhost:27017"
+
+// newTestApp returns an App connected to tenant, without reaching its cluster.
+func newTestApp(t *testing.T, tenant *entity.Tenant) *App {
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(testTenantConnectionString))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &App{
+		TenantsMongoDBClients: map[string]*TenantMongoDB{
+			tenant.ID.Hex(): {
+				ID:               tenant.ID.Hex(),
+				DatabaseName:     tenant.DatabaseName,
+				Status:           tenant.GetStatus(),
+				MongoDBClient:    client,
+				connectionString: testTenantConnectionString,
+			},
+		},
+	}
+}
+
+func TestApp_setTenant(t *testing.T) {
+	tenant := &entity.Tenant{
+		ID:                      primitive.NewObjectID(),
+		MongoDBConnectionString: testTenantConnectionString,
+		DatabaseName:            "club",
+		Status:                  entity.TenantStatusActive,
+	}
+
+	t.Run("Updates_tenants_on_the_same_cluster_without_reconnecting", func(t *testing.T) {
+		a := newTestApp(t, tenant)
+		current := a.GetMongoDBClients()[tenant.ID.Hex()]
+
+		suspended := *tenant
+		suspended.Status = entity.TenantStatusSuspended
+		client, err := a.setTenant(context.Background(), &suspended)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if client.MongoDBClient != current.MongoDBClient {
+			t.Errorf("App.setTenant() reconnected to the cluster of the tenant")
+		}
+
+		if _, err := a.GetTenantMongoDBClient(tenant.ID.Hex()); !errors.Is(err, util.ErrTenantIsSuspended) {
+			t.Errorf("App.GetTenantMongoDBClient() error = %v, wantErr %v", err, util.ErrTenantIsSuspended)
+		}
+
+		if _, err := a.GetTenantMongoDBClientOfAnyStatus(tenant.ID.Hex()); err != nil {
+			t.Errorf("App.GetTenantMongoDBClientOfAnyStatus() error = %v", err)
+		}
+	})
+
+	t.Run("Serves_the_latest_change_of_concurrent_ones", func(t *testing.T) {
+		a := newTestApp(t, tenant)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				changed := *tenant
+				changed.DatabaseName = fmt.Sprintf("club_%d", i)
+				if _, err := a.setTenant(context.Background(), &changed); err != nil {
+					t.Error(err)
+				}
+			}(i)
+			go func() {
+				defer wg.Done()
+				a.GetTenantMongoDBClient(tenant.ID.Hex())
+			}()
+		}
+		wg.Wait()
+
+		changed := *tenant
+		changed.DatabaseName = "club_latest"
+		if _, err := a.setTenant(context.Background(), &changed); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := a.GetMongoDBClients()[tenant.ID.Hex()].DatabaseName; got != "club_latest" {
+			t.Errorf("database of the tenant = %v, want %v", got, "club_latest")
+		}
+	})
+}
+
+func TestApp_removeTenant(t *testing.T) {
+	tenant := &entity.Tenant{ID: primitive.NewObjectID(), DatabaseName: "club"}
+	a := newTestApp(t, tenant)
+
+	a.removeTenant(tenant.ID.Hex())
+
+	if client, err := a.knownTenant(tenant.ID.Hex()); client != nil || err != nil {
+		t.Errorf("App.knownTenant() = %v, %v, want the tenant to be looked up again", client, err)
+	}
+}
+
+func TestApp_miss(t *testing.T) {
+	t.Run("Remembers_the_tenants_that_failed_until_they_expire", func(t *testing.T) {
+		a := &App{TenantsMongoDBClients: make(map[string]*TenantMongoDB)}
+		tenantID := primitive.NewObjectID().Hex()
+
+		a.miss(tenantID, util.ErrTenantDatabaseIsNotAvailable)
+		if _, err := a.knownTenant(tenantID); !errors.Is(err, util.ErrTenantDatabaseIsNotAvailable) {
+			t.Errorf("App.knownTenant() error = %v, wantErr %v", err, util.ErrTenantDatabaseIsNotAvailable)
+		}
+
+		a.tenantMisses[tenantID] = tenantMiss{err: util.ErrTenantDatabaseIsNotAvailable, expiresAt: time.Now().Add(-time.Second)}
+		if _, err := a.knownTenant(tenantID); err != nil {
+			t.Errorf("App.knownTenant() error = %v, want the tenant to be looked up again", err)
+		}
+	})
+
+	t.Run("Keeps_a_bounded_number_of_tenants", func(t *testing.T) {
+		a := &App{TenantsMongoDBClients: make(map[string]*TenantMongoDB)}
+		for i := 0; i < tenantMaxMisses+1; i++ {
+			a.miss(fmt.Sprint(i), util.ErrTenantHeaderIsInvalid)
+		}
+
+		if len(a.tenantMisses) > tenantMaxMisses {
+			t.Errorf("App.miss() kept %d tenants, want at most %d", len(a.tenantMisses), tenantMaxMisses)
+		}
+	})
+
+	t.Run("Never_looks_up_malformed_tenant_IDs", func(t *testing.T) {
+		a := &App{TenantsMongoDBClients: make(map[string]*TenantMongoDB)}
+		if _, err := a.GetTenantMongoDBClient("club"); !errors.Is(err, util.ErrTenantHeaderIsInvalid) {
+			t.Errorf("App.GetTenantMongoDBClient() error = %v, wantErr %v", err, util.ErrTenantHeaderIsInvalid)
+		}
+
+		if _, err := a.GetTenantMongoDBClientOfAnyStatus("club"); !errors.Is(err, mongo.ErrNoDocuments) {
+			t.Errorf("App.GetTenantMongoDBClientOfAnyStatus() error = %v, wantErr %v", err, mongo.ErrNoDocuments)
+		}
+	})
+}
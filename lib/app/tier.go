@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Neniel/gotennis/lib/entity"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Serving tells which tenants a deployment of a service serves. It is set through the TIER
+// environment variable:
+//
+//	TIER=            serves every tenant but the diamond ones (default)
+//	TIER=diamond     serves only the diamond tenant in TENANT_ID
+//	TIER=all         serves every tenant, as the tenants service does to manage them
+type Serving string
+
+const (
+	ServingSharedTenants   Serving = ""
+	ServingDedicatedTenant Serving = "diamond"
+	ServingAllTenants      Serving = "all"
+)
+
+func servingFromEnvironment() (Serving, string, error) {
+	serving := Serving(os.Getenv("TIER"))
+	switch serving {
+	case ServingSharedTenants, ServingAllTenants:
+		return serving, "", nil
+	case ServingDedicatedTenant:
+		tenantID := os.Getenv("TENANT_ID")
+		if _, err := primitive.ObjectIDFromHex(tenantID); err != nil {
+			return "", "", fmt.Errorf("error while reading TENANT_ID environment variable: %w", err)
+		}
+
+		return serving, tenantID, nil
+	default:
+		return "", "", fmt.Errorf("invalid value '%s' for environment variable TIER", serving)
+	}
+}
+
+// serves reports whether the requests of tenant are served by this App.
+func (a *App) serves(tenant *entity.Tenant) bool {
+	switch a.Serving {
+	case ServingAllTenants:
+		return true
+	case ServingDedicatedTenant:
+		return tenant.ID.Hex() == a.DedicatedTenantID && tenant.IsDedicated()
+	default:
+		return !tenant.IsDedicated()
+	}
+}
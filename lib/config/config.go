@@ -12,12 +12,18 @@ type Configuration struct {
 	Redis            Redis            `json:"redis"`
 	Grafana          Grafana          `json:"grafana"`
 	SystemDataSource SystemDataSource `json:"system_data_source"`
+	SoftDelete       SoftDelete       `json:"soft_delete"`
 }
 
 type SystemDataSource struct {
 	URI string `json:"uri"`
 }
 
+type SoftDelete struct {
+	RetentionDays        int `json:"retention_days"`
+	PurgeIntervalMinutes int `json:"purge_interval_minutes"`
+}
+
 type MongoDB struct {
 	URI string `json:"uri"`
 }
@@ -0,0 +1,440 @@
+// Package backup dumps the collections of a database into a portable archive and restores them.
+//
+// An archive is a gzipped tar file with a manifest.json describing it, followed by a
+// <collection>.bson file with the documents and a <collection>.indexes.bson file with the indexes
+// of every collection. Each file holds BSON documents one after the other, like mongodump does.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/migration"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// FormatVersion is the version of the layout of the archive itself
+const FormatVersion = 1
+
+const (
+	manifestName     = "manifest.json"
+	restoreBatchSize = 1000
+)
+
+type Manifest struct {
+	FormatVersion int          `json:"format_version"`
+	SchemaVersion int          `json:"schema_version"`
+	TenantID      string       `json:"tenant_id"`
+	DatabaseName  string       `json:"database_name"`
+	CreatedAt     time.Time    `json:"created_at"`
+	Collections   []Collection `json:"collections"`
+}
+
+type Collection struct {
+	Name            string `json:"name"`
+	Documents       int64  `json:"documents"`
+	SHA256          string `json:"sha256"`
+	Indexes         int64  `json:"indexes"`
+	IndexesSHA256   string `json:"indexes_sha256"`
+	documentsFile   *os.File
+	indexesFile     *os.File
+	documentsHash   hash.Hash
+	indexesHash     hash.Hash
+	documentsLength int64
+	indexesLength   int64
+}
+
+// Dump writes to w an archive with the documents of every collection of db matching filter, e.g.
+// the ones of a tenant in a shared database. The collections are staged in temporary files, as tar
+// needs to know the size of a file before writing it.
+func Dump(ctx context.Context, db *mongo.Database, filter bson.D, tenantID string, w io.Writer) (*Manifest, error) {
+	names, err := db.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		FormatVersion: FormatVersion,
+		SchemaVersion: database.SchemaVersion,
+		TenantID:      tenantID,
+		DatabaseName:  db.Name(),
+		CreatedAt:     time.Now().UTC(),
+		Collections:   make([]Collection, 0, len(names)),
+	}
+
+	defer func() {
+		for _, c := range manifest.Collections {
+			c.close()
+		}
+	}()
+
+	for _, name := range names {
+		c, err := dumpCollection(ctx, db.Collection(name), filter)
+		manifest.Collections = append(manifest.Collections, c)
+		if err != nil {
+			return nil, fmt.Errorf("could not dump collection '%s': %w", name, err)
+		}
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeFile(archive, manifestName, int64(len(content)), manifest.CreatedAt, bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+
+	for _, c := range manifest.Collections {
+		if err := writeFile(archive, c.Name+".bson", c.documentsLength, manifest.CreatedAt, c.documentsFile); err != nil {
+			return nil, err
+		}
+
+		if err := writeFile(archive, c.Name+".indexes.bson", c.indexesLength, manifest.CreatedAt, c.indexesFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return manifest, gz.Close()
+}
+
+func dumpCollection(ctx context.Context, collection *mongo.Collection, filter bson.D) (Collection, error) {
+	c := Collection{
+		Name:          collection.Name(),
+		documentsHash: sha256.New(),
+		indexesHash:   sha256.New(),
+	}
+
+	var err error
+	if c.documentsFile, err = os.CreateTemp("", "backup-*.bson"); err != nil {
+		return c, err
+	}
+
+	if c.indexesFile, err = os.CreateTemp("", "backup-*.indexes.bson"); err != nil {
+		return c, err
+	}
+
+	documents, err := collection.Find(ctx, filter)
+	if err != nil {
+		return c, err
+	}
+
+	if c.Documents, c.documentsLength, err = copyDocuments(ctx, documents, io.MultiWriter(c.documentsFile, c.documentsHash)); err != nil {
+		return c, err
+	}
+
+	indexes, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return c, err
+	}
+
+	if c.Indexes, c.indexesLength, err = copyDocuments(ctx, indexes, io.MultiWriter(c.indexesFile, c.indexesHash)); err != nil {
+		return c, err
+	}
+
+	c.SHA256 = hex.EncodeToString(c.documentsHash.Sum(nil))
+	c.IndexesSHA256 = hex.EncodeToString(c.indexesHash.Sum(nil))
+
+	if _, err := c.documentsFile.Seek(0, io.SeekStart); err != nil {
+		return c, err
+	}
+
+	_, err = c.indexesFile.Seek(0, io.SeekStart)
+	return c, err
+}
+
+func copyDocuments(ctx context.Context, cursor *mongo.Cursor, w io.Writer) (int64, int64, error) {
+	defer cursor.Close(ctx)
+
+	var count, length int64
+	for cursor.Next(ctx) {
+		n, err := w.Write(cursor.Current)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		count++
+		length += int64(n)
+	}
+
+	return count, length, cursor.Err()
+}
+
+func writeFile(archive *tar.Writer, name string, size int64, modTime time.Time, r io.Reader) error {
+	err := archive.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o644,
+		Size:    size,
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(archive, r)
+	return err
+}
+
+// Restore loads the archive read from r into db, which must have no collections with documents.
+// The whole archive is read and its checksums verified before anything is written to db. Archives
+// of an older schema version are migrated to the current one once restored.
+func Restore(ctx context.Context, db *mongo.Database, r io.Reader) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", util.ErrBackupInvalidArchive, err)
+	}
+	defer gz.Close()
+
+	archive := tar.NewReader(gz)
+
+	header, err := archive.Next()
+	if err != nil || header.Name != manifestName {
+		return nil, fmt.Errorf("%w: it must start with %s", util.ErrBackupInvalidArchive, manifestName)
+	}
+
+	var manifest Manifest
+	if err := json.NewDecoder(archive).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("%w: %w", util.ErrBackupInvalidArchive, err)
+	}
+
+	defer func() {
+		for _, c := range manifest.Collections {
+			c.close()
+		}
+	}()
+
+	if err := checkCompatibility(&manifest); err != nil {
+		return nil, err
+	}
+
+	if err := readCollections(archive, &manifest); err != nil {
+		return nil, err
+	}
+
+	if err := checkIsEmpty(ctx, db); err != nil {
+		return nil, err
+	}
+
+	for _, c := range manifest.Collections {
+		if err := restoreCollection(ctx, db.Collection(c.Name), &c); err != nil {
+			return nil, fmt.Errorf("could not restore collection '%s': %w", c.Name, err)
+		}
+	}
+
+	if manifest.SchemaVersion < database.SchemaVersion {
+		if _, err := migration.NewRunner(migration.All).Run(ctx, db, migration.Up, 0, false); err != nil {
+			return nil, fmt.Errorf("could not migrate restored archive of schema version %d: %w", manifest.SchemaVersion, err)
+		}
+	}
+
+	return &manifest, nil
+}
+
+func checkCompatibility(manifest *Manifest) error {
+	if manifest.FormatVersion != FormatVersion {
+		return fmt.Errorf("%w: archive format version %d is not supported", util.ErrBackupIncompatible, manifest.FormatVersion)
+	}
+
+	if manifest.SchemaVersion > database.SchemaVersion {
+		return fmt.Errorf("%w: archive schema version %d is newer than the supported %d", util.ErrBackupIncompatible, manifest.SchemaVersion, database.SchemaVersion)
+	}
+
+	return nil
+}
+
+// readCollections stages the files of the archive in temporary files, checking them against manifest.
+func readCollections(archive *tar.Reader, manifest *Manifest) error {
+	files := make(map[string]*Collection)
+	for i := range manifest.Collections {
+		c := &manifest.Collections[i]
+		c.documentsHash, c.indexesHash = sha256.New(), sha256.New()
+		files[c.Name+".bson"] = c
+		files[c.Name+".indexes.bson"] = c
+	}
+
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", util.ErrBackupInvalidArchive, err)
+		}
+
+		c, ok := files[header.Name]
+		if !ok {
+			return fmt.Errorf("%w: unexpected file %s", util.ErrBackupInvalidArchive, header.Name)
+		}
+
+		file, h := &c.documentsFile, c.documentsHash
+		if header.Name == c.Name+".indexes.bson" {
+			file, h = &c.indexesFile, c.indexesHash
+		}
+
+		if *file != nil {
+			return fmt.Errorf("%w: duplicated file %s", util.ErrBackupInvalidArchive, header.Name)
+		}
+
+		if *file, err = os.CreateTemp("", "restore-*.bson"); err != nil {
+			return err
+		}
+
+		if _, err := io.Copy(io.MultiWriter(*file, h), archive); err != nil {
+			return err
+		}
+
+		if _, err := (*file).Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range manifest.Collections {
+		if c.documentsFile == nil || c.indexesFile == nil {
+			return fmt.Errorf("%w: files of collection '%s' are missing", util.ErrBackupInvalidArchive, c.Name)
+		}
+
+		if hex.EncodeToString(c.documentsHash.Sum(nil)) != c.SHA256 || hex.EncodeToString(c.indexesHash.Sum(nil)) != c.IndexesSHA256 {
+			return fmt.Errorf("%w: collection '%s'", util.ErrBackupChecksumMismatch, c.Name)
+		}
+	}
+
+	return nil
+}
+
+func checkIsEmpty(ctx context.Context, db *mongo.Database) error {
+	names, err := db.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		count, err := db.Collection(name).EstimatedDocumentCount(ctx)
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return fmt.Errorf("%w: collection '%s' has documents", util.ErrBackupTargetIsNotEmpty, name)
+		}
+	}
+
+	return nil
+}
+
+func restoreCollection(ctx context.Context, collection *mongo.Collection, c *Collection) error {
+	batch := make([]interface{}, 0, restoreBatchSize)
+	err := readDocuments(bufio.NewReader(c.documentsFile), func(document bson.Raw) error {
+		batch = append(batch, document)
+		if len(batch) < restoreBatchSize {
+			return nil
+		}
+
+		_, err := collection.InsertMany(ctx, batch)
+		batch = batch[:0]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(batch) > 0 {
+		if _, err := collection.InsertMany(ctx, batch); err != nil {
+			return err
+		}
+	}
+
+	indexes := bson.A{}
+	err = readDocuments(bufio.NewReader(c.indexesFile), func(index bson.Raw) error {
+		if index.Lookup("name").StringValue() == "_id_" {
+			return nil
+		}
+
+		// Only the options of the index are kept, the rest depends on where it was created
+		spec := bson.D{}
+		elements, err := index.Elements()
+		if err != nil {
+			return err
+		}
+
+		for _, e := range elements {
+			if e.Key() != "v" && e.Key() != "ns" {
+				spec = append(spec, bson.E{Key: e.Key(), Value: e.Value()})
+			}
+		}
+
+		indexes = append(indexes, spec)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.Documents == 0 {
+		if err := collection.Database().CreateCollection(ctx, c.Name); err != nil && !isNamespaceExists(err) {
+			return err
+		}
+	}
+
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	return collection.Database().RunCommand(ctx, bson.D{
+		{Key: "createIndexes", Value: c.Name},
+		{Key: "indexes", Value: indexes},
+	}).Err()
+}
+
+func readDocuments(r io.Reader, fn func(bson.Raw) error) error {
+	for {
+		document, err := bson.ReadDocument(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: %w", util.ErrBackupInvalidArchive, err)
+		}
+
+		if err := fn(document); err != nil {
+			return err
+		}
+	}
+}
+
+func isNamespaceExists(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Name == "NamespaceExists"
+}
+
+func (c *Collection) close() {
+	for _, file := range []*os.File{c.documentsFile, c.indexesFile} {
+		if file != nil {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}
+}
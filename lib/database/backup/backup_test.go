@@ -0,0 +1,136 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func archiveOf(t *testing.T, manifest Manifest, files map[string][]byte, duplicates ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFile(archive, manifestName, int64(len(content)), time.Now(), bytes.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		if err := writeFile(archive, name, int64(len(content)), time.Now(), bytes.NewReader(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range duplicates {
+		content := files[name]
+		if err := writeFile(archive, name, int64(len(content)), time.Now(), bytes.NewReader(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archive.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRestore_RejectsInvalidArchives(t *testing.T) {
+	document, _ := bson.Marshal(bson.D{{Key: "name", Value: "Primera"}})
+
+	valid := Manifest{
+		FormatVersion: FormatVersion,
+		SchemaVersion: database.SchemaVersion,
+		Collections: []Collection{
+			{Name: "categories", Documents: 1, SHA256: checksum(document), IndexesSHA256: checksum(nil)},
+		},
+	}
+
+	newerSchema := valid
+	newerSchema.SchemaVersion = database.SchemaVersion + 1
+
+	otherFormat := valid
+	otherFormat.FormatVersion = FormatVersion + 1
+
+	tests := []struct {
+		name    string
+		archive []byte
+		wantErr error
+	}{
+		{
+			name:    "Not_gzipped",
+			archive: []byte("not an archive"),
+			wantErr: util.ErrBackupInvalidArchive,
+		},
+		{
+			name:    "Newer_schema_version",
+			archive: archiveOf(t, newerSchema, nil),
+			wantErr: util.ErrBackupIncompatible,
+		},
+		{
+			name:    "Other_format_version",
+			archive: archiveOf(t, otherFormat, nil),
+			wantErr: util.ErrBackupIncompatible,
+		},
+		{
+			name: "Corrupted_documents",
+			archive: archiveOf(t, valid, map[string][]byte{
+				"categories.bson":         append(document[:len(document):len(document)], 0),
+				"categories.indexes.bson": nil,
+			}),
+			wantErr: util.ErrBackupChecksumMismatch,
+		},
+		{
+			name: "Missing_indexes",
+			archive: archiveOf(t, valid, map[string][]byte{
+				"categories.bson": document,
+			}),
+			wantErr: util.ErrBackupInvalidArchive,
+		},
+		{
+			name: "Duplicated_file",
+			archive: archiveOf(t, valid, map[string][]byte{
+				"categories.bson":         document,
+				"categories.indexes.bson": nil,
+			}, "categories.bson"),
+			wantErr: util.ErrBackupInvalidArchive,
+		},
+		{
+			name: "Unexpected_file",
+			archive: archiveOf(t, valid, map[string][]byte{
+				"players.bson": document,
+			}),
+			wantErr: util.ErrBackupInvalidArchive,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The archive is rejected before the database is used at all
+			_, err := Restore(context.Background(), nil, bytes.NewReader(tt.archive))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Restore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
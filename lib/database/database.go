@@ -2,51 +2,100 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/Neniel/gotennis/lib/database/mongodb"
+	"github.com/Neniel/gotennis/lib/database/query"
 	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// SchemaVersion is the version of the layout of the tenant databases this code works with.
+const SchemaVersion = 7
+
 type Database interface {
 	DBReader
 	DBWriter
 }
 
 type DBReader interface {
-	GetCategories(context.Context) ([]entity.Category, error)
+	GetCategories(context.Context, *query.Query) (*query.Page[entity.Category], error)
 	GetCategory(context.Context, string) (*entity.Category, error)
+	StreamCategories(context.Context, *query.Query, func(*entity.Category) error) error
 
-	GetPlayers(context.Context) ([]entity.Player, error)
+	GetPlayers(context.Context, *query.Query) (*query.Page[entity.Player], error)
 	GetPlayer(context.Context, string) (*entity.Player, error)
+	StreamPlayers(context.Context, *query.Query, func(*entity.Player) error) error
 	IsAvailable(context.Context, string, string) (bool, error)
+	SearchPlayers(context.Context, []string, func(*entity.Player) error) error
+	GetPlayerDuplicateCandidates(context.Context, *entity.Player) ([]entity.Player, error)
 
-	GetTournaments(context.Context) ([]entity.Tournament, error)
+	GetTournaments(context.Context, *query.Query) (*query.Page[entity.Tournament], error)
 	GetTournament(context.Context, string) (*entity.Tournament, error)
+	GetDeletedTournament(context.Context, string) (*entity.Tournament, error)
+	StreamTournaments(context.Context, *query.Query, func(*entity.Tournament) error) error
 
-	GetTenants(context.Context) ([]entity.Tenant, error)
+	GetTenants(context.Context, *query.Query) (*query.Page[entity.Tenant], error)
 	GetTenant(context.Context, string) (*entity.Tenant, error)
+	GetTenantByCustomDomain(ctx context.Context, domain string) (*entity.Tenant, error)
+	GetTenantBySlug(ctx context.Context, slug string) (*entity.Tenant, error)
+	IsTenantSlugTaken(ctx context.Context, slug string) (bool, error)
+	IsTenantDatabaseNameTaken(ctx context.Context, databaseName string) (bool, error)
 
 	Login(ctx context.Context, userID string, password string) error
+
+	IsEmpty(context.Context) (bool, error)
+
+	CountPlayers(context.Context) (int64, error)
+	CountTournaments(ctx context.Context, from time.Time, to time.Time) (int64, error)
+	StorageSize(context.Context) (int64, error)
+
+	GetIdempotencyRecord(ctx context.Context, key string) (*entity.IdempotencyRecord, error)
 }
 
 type DBWriter interface {
 	AddCategory(context.Context, *entity.Category) (*entity.Category, error)
 	UpdateCategory(context.Context, *entity.Category) (*entity.Category, error)
-	DeleteCategory(context.Context, string) error
+	PatchCategory(context.Context, *entity.Category, *util.Patch) (*entity.Category, error)
+	DeleteCategory(context.Context, string, *int64, entity.CategoryDeletePolicy, *entity.Category, string) error
+	RestoreCategory(context.Context, string) error
 
 	AddPlayer(context.Context, *entity.Player) (*entity.Player, error)
+	AddPlayers(context.Context, []*entity.Player) ([]*entity.Player, error)
 	UpdatePlayer(context.Context, *entity.Player) (*entity.Player, error)
-	DeletePlayer(context.Context, string) error
+	PatchPlayer(context.Context, *entity.Player, *util.Patch) (*entity.Player, error)
+	DeletePlayer(context.Context, string, *int64, string) error
+	RestorePlayer(context.Context, string) error
+	MergePlayers(context.Context, *entity.Player, *entity.PlayerMerge) (*entity.Player, error)
+	IndexPlayersForSearch(context.Context) error
 
 	AddTournament(context.Context, *entity.Tournament) (*entity.Tournament, error)
 	UpdateTournament(context.Context, *entity.Tournament) (*entity.Tournament, error)
-	DeleteTournament(context.Context, string) error
+	PatchTournament(context.Context, *entity.Tournament, *util.Patch) (*entity.Tournament, error)
+	DeleteTournament(context.Context, string, *int64, string) error
+	RestoreTournament(context.Context, string) error
 
 	AddTenant(context.Context, *entity.Tenant) (*entity.Tenant, error)
-	//UpdateTournament(context.Context, *entity.Tournament) (*entity.Tournament, error)
-	DeleteTenant(context.Context, string) error
+	UpdateTenant(context.Context, *entity.Tenant) (*entity.Tenant, error)
+	DeleteTenant(context.Context, string, string) error
+	RestoreTenant(context.Context, string) error
+	RewriteTenantConnectionStrings(ctx context.Context, rewrite func(id string, connectionString string) (string, error)) (int, []string, error)
+	IndexTenants(context.Context) error
+	ReserveTenantDatabase(ctx context.Context, databaseName string) (bool, error)
+	ReleaseTenantDatabase(ctx context.Context, databaseName string) error
+
+	AddUser(context.Context, *entity.User) (*entity.User, error)
+
+	ReserveIdempotencyKey(context.Context, *entity.IdempotencyRecord) (bool, error)
+	CompleteIdempotencyKey(context.Context, *entity.IdempotencyRecord) error
+	ReleaseIdempotencyKey(context.Context, *entity.IdempotencyRecord) error
+
+	PurgeDeleted(context.Context, string, time.Time) (int64, error)
+	CreateIdempotencyIndexes(context.Context) error
+	DropDatabase(context.Context) error
 }
 
 func NewDatabaseReader(client interface{}, databaseName string) DBReader {
@@ -63,6 +112,17 @@ func NewDatabaseReader(client interface{}, databaseName string) DBReader {
 	panic("client is not supported")
 }
 
+// NewSharedDatabaseReader works like NewDatabaseReader but only sees the documents of tenantID, in
+// a database shared by several tenants.
+func NewSharedDatabaseReader(client interface{}, databaseName string, tenantID primitive.ObjectID) DBReader {
+	mongoClient, isMongoClient := client.(*mongo.Client)
+	if isMongoClient {
+		return mongodb.NewSharedMongoDbReader(mongoClient, databaseName, tenantID)
+	}
+
+	panic("client is not supported")
+}
+
 func NewDatabaseWriter(client interface{}, databaseName string) DBWriter {
 	mongoClient, isMongoClient := client.(*mongo.Client)
 	if isMongoClient {
@@ -78,3 +138,14 @@ func NewDatabaseWriter(client interface{}, databaseName string) DBWriter {
 
 	panic("client is not supported")
 }
+
+// NewSharedDatabaseWriter works like NewDatabaseWriter but only touches the documents of tenantID,
+// in a database shared by several tenants.
+func NewSharedDatabaseWriter(client interface{}, databaseName string, tenantID primitive.ObjectID) DBWriter {
+	mongoClient, isMongoClient := client.(*mongo.Client)
+	if isMongoClient {
+		return mongodb.NewSharedMongoDbWriter(mongoClient, databaseName, tenantID)
+	}
+
+	panic("client is not supported")
+}
@@ -0,0 +1,186 @@
+// Package migration keeps the schema of tenant databases up to date. Every database records the
+// migrations applied to it in the schema_migrations collection, so each one only runs once.
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const collectionName = "schema_migrations"
+
+type Direction string
+
+const (
+	Up   Direction = "up"
+	Down Direction = "down"
+)
+
+func ParseDirection(value string) (Direction, error) {
+	switch Direction(value) {
+	case Up, Down:
+		return Direction(value), nil
+	default:
+		return "", util.ErrMigrationInvalidDirection
+	}
+}
+
+// Migration changes the schema of a database from Version-1 to Version with Up, and back with Down.
+// Both must be safe to run again on a database where they were partially applied.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context, db *mongo.Database) error
+	Down    func(ctx context.Context, db *mongo.Database) error
+}
+
+type applied struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Step is a migration that was run, or that would be run in a dry run.
+type Step struct {
+	Version   int       `json:"version"`
+	Name      string    `json:"name"`
+	Direction Direction `json:"direction"`
+}
+
+type Result struct {
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Steps []Step `json:"steps"`
+	Error string `json:"error,omitempty"`
+}
+
+type Runner struct {
+	migrations []Migration
+}
+
+// NewRunner returns a runner for the given migrations, whose versions must go from 1 onwards
+// without gaps.
+func NewRunner(migrations []Migration) *Runner {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i, m := range sorted {
+		if m.Version != i+1 {
+			panic(fmt.Sprintf("migration '%s' has version %d, expected %d", m.Name, m.Version, i+1))
+		}
+	}
+
+	return &Runner{
+		migrations: sorted,
+	}
+}
+
+// Latest returns the version a database has once every migration is applied.
+func (r *Runner) Latest() int {
+	return len(r.migrations)
+}
+
+// Version returns the version of the last migration applied to db, 0 when none was.
+func (r *Runner) Version(ctx context.Context, db *mongo.Database) (int, error) {
+	var last applied
+	err := db.Collection(collectionName).FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return last.Version, nil
+}
+
+// Run moves db to the target version, applying the pending migrations when going up and reverting
+// the applied ones when going down. A target of 0 going up means the latest version. When dryRun
+// is set, it only reports the steps it would take.
+func (r *Runner) Run(ctx context.Context, db *mongo.Database, direction Direction, target int, dryRun bool) (*Result, error) {
+	if direction == Up && target == 0 {
+		target = r.Latest()
+	}
+
+	if target < 0 || target > r.Latest() {
+		return nil, fmt.Errorf("%w: %d", util.ErrMigrationInvalidTarget, target)
+	}
+
+	current, err := r.Version(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if current > r.Latest() {
+		return nil, fmt.Errorf("%w: database is at version %d, newer than the latest known %d", util.ErrMigrationUnknownVersion, current, r.Latest())
+	}
+
+	result := &Result{From: current, To: current, Steps: make([]Step, 0)}
+
+	switch direction {
+	case Up:
+		for _, m := range r.migrations[current:max(current, target)] {
+			if err := r.step(ctx, db, m, Up, dryRun, result); err != nil {
+				return result, err
+			}
+		}
+	case Down:
+		for i := current - 1; i >= target; i-- {
+			if err := r.step(ctx, db, r.migrations[i], Down, dryRun, result); err != nil {
+				return result, err
+			}
+		}
+	default:
+		return nil, util.ErrMigrationInvalidDirection
+	}
+
+	return result, nil
+}
+
+func (r *Runner) step(ctx context.Context, db *mongo.Database, m Migration, direction Direction, dryRun bool, result *Result) error {
+	if !dryRun {
+		if err := r.apply(ctx, db, m, direction); err != nil {
+			result.Error = err.Error()
+			return fmt.Errorf("migration %d '%s' %s failed: %w", m.Version, m.Name, direction, err)
+		}
+	}
+
+	result.Steps = append(result.Steps, Step{Version: m.Version, Name: m.Name, Direction: direction})
+	result.To = m.Version
+	if direction == Down {
+		result.To = m.Version - 1
+	}
+
+	return nil
+}
+
+func (r *Runner) apply(ctx context.Context, db *mongo.Database, m Migration, direction Direction) error {
+	migrations := db.Collection(collectionName)
+
+	if direction == Down {
+		if err := m.Down(ctx, db); err != nil {
+			return err
+		}
+
+		_, err := migrations.DeleteOne(ctx, bson.D{{Key: "_id", Value: m.Version}})
+		return err
+	}
+
+	if err := m.Up(ctx, db); err != nil {
+		return err
+	}
+
+	_, err := migrations.InsertOne(ctx, applied{Version: m.Version, Name: m.Name, AppliedAt: time.Now().UTC()})
+	return err
+}
@@ -0,0 +1,63 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestAll_MatchesSchemaVersion(t *testing.T) {
+	if got := NewRunner(All).Latest(); got != database.SchemaVersion {
+		t.Errorf("latest migration is %d but database.SchemaVersion is %d", got, database.SchemaVersion)
+	}
+}
+
+func TestNewRunner_RejectsGaps(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("NewRunner() did not panic with a missing version")
+		}
+	}()
+
+	noop := func(context.Context, *mongo.Database) error { return nil }
+	NewRunner([]Migration{
+		{Version: 1, Name: "first", Up: noop, Down: noop},
+		{Version: 3, Name: "third", Up: noop, Down: noop},
+	})
+}
+
+func TestRunner_Run_RejectsInvalidTargets(t *testing.T) {
+	runner := NewRunner(All)
+
+	for _, target := range []int{-1, runner.Latest() + 1} {
+		// The target is checked before the database is used at all
+		_, err := runner.Run(context.Background(), nil, Up, target, true)
+		if !errors.Is(err, util.ErrMigrationInvalidTarget) {
+			t.Errorf("Run() with target %d error = %v, wantErr %v", target, err, util.ErrMigrationInvalidTarget)
+		}
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Direction
+		wantErr error
+	}{
+		{value: "up", want: Up},
+		{value: "down", want: Down},
+		{value: "sideways", wantErr: util.ErrMigrationInvalidDirection},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDirection(tt.value)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("ParseDirection() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package migration
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/mongodb"
+	"github.com/Neniel/gotennis/lib/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// All are the migrations of tenant databases, oldest first. New ones go at the end and must bump
+// database.SchemaVersion. The indexes of every migration are defined here, as they were when it was
+// written, so that what a migration does never changes.
+var All = []Migration{
+	{
+		Version: 1,
+		Name:    "create_unique_indexes",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, uniqueIndexesV1)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db, map[string][]string{
+				"players": {"government_id_1", "email_1", "alias_1"},
+				"users":   {"government_id_1", "email_1"},
+			})
+		},
+	},
+	{
+		Version: 2,
+		Name:    "add_player_search_terms",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := createIndexes(ctx, db, indexesV2); err != nil {
+				return err
+			}
+
+			return database.NewDatabaseWriter(db.Client(), db.Name()).IndexPlayersForSearch(ctx)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(ctx, db, map[string][]string{"players": {"search_terms_1"}}); err != nil {
+				return err
+			}
+
+			_, err := db.Collection("players").UpdateMany(ctx, bson.D{}, bson.D{{Key: "$unset", Value: bson.D{{Key: "search_terms", Value: ""}}}})
+			return err
+		},
+	},
+	{
+		Version: 3,
+		Name:    "add_user_roles",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("users").UpdateMany(ctx,
+				bson.D{{Key: "role", Value: bson.D{{Key: "$exists", Value: false}}}},
+				bson.D{{Key: "$set", Value: bson.D{{Key: "role", Value: entity.UserRolePlayer}}}},
+			)
+			return err
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection("users").UpdateMany(ctx,
+				bson.D{{Key: "role", Value: entity.UserRolePlayer}},
+				bson.D{{Key: "$unset", Value: bson.D{{Key: "role", Value: ""}}}},
+			)
+			return err
+		},
+	},
+	{
+		Version: 4,
+		Name:    "scope_indexes_by_tenant",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(ctx, db, indexNamesV3); err != nil {
+				return err
+			}
+
+			return createIndexes(ctx, db, indexesV4)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			err := dropIndexes(ctx, db, map[string][]string{
+				"players": {"tenant_id_1_government_id_1", "tenant_id_1_email_1", "tenant_id_1_alias_1", "tenant_id_1_search_terms_1"},
+				"users":   {"tenant_id_1_government_id_1", "tenant_id_1_email_1"},
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := createIndexes(ctx, db, uniqueIndexesV1); err != nil {
+				return err
+			}
+
+			return createIndexes(ctx, db, indexesV2)
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add_idempotency_keys",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, indexesV5)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return db.Collection("idempotency_keys").Drop(ctx)
+		},
+	},
+	{
+		Version: 6,
+		Name:    "ignore_deleted_in_unique_indexes",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Documents stored before soft deletes may have no deleted_at, which the indexes need
+			for _, collection := range []string{"players", "users"} {
+				_, err := db.Collection(collection).UpdateMany(ctx,
+					bson.D{{Key: "deleted_at", Value: bson.D{{Key: "$exists", Value: false}}}},
+					bson.D{{Key: "$set", Value: bson.D{{Key: "deleted_at", Value: nil}}}},
+				)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := dropIndexes(ctx, db, uniqueIndexNamesV5); err != nil {
+				return err
+			}
+
+			return createIndexes(ctx, db, uniqueIndexesV6)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			if err := dropIndexes(ctx, db, uniqueIndexNamesV5); err != nil {
+				return err
+			}
+
+			return createIndexes(ctx, db, map[string][]mongo.IndexModel{
+				"players": {uniqueStringV5("government_id"), uniqueStringV5("email"), uniqueStringV5("alias")},
+				"users":   {uniqueStringV5("government_id"), uniqueStringV5("email")},
+			})
+		},
+	},
+	{
+		Version: 7,
+		Name:    "index_tenant_id",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			return createIndexes(ctx, db, indexesV7)
+		},
+		Down: func(ctx context.Context, db *mongo.Database) error {
+			return dropIndexes(ctx, db, map[string][]string{
+				"categories":    {"tenant_id_1"},
+				"tournaments":   {"tenant_id_1"},
+				"player_merges": {"tenant_id_1"},
+			})
+		},
+	},
+}
+
+// uniqueIndexesV1 are the unique indexes created by the first migration, before they were scoped
+// by tenant.
+var uniqueIndexesV1 = map[string][]mongo.IndexModel{
+	"players": {uniqueStringV1("government_id"), uniqueStringV1("email"), uniqueStringV1("alias")},
+	"users":   {uniqueStringV1("government_id"), uniqueStringV1("email")},
+}
+
+// indexesV2 is the index of the search terms of players, before it was scoped by tenant.
+var indexesV2 = map[string][]mongo.IndexModel{
+	"players": {{Keys: bson.D{{Key: "search_terms", Value: 1}}}},
+}
+
+// indexesV4 are the indexes scoped by tenant, which replace the ones of versions 1 and 2.
+var indexesV4 = map[string][]mongo.IndexModel{
+	"players": {
+		uniqueStringV5("government_id"),
+		uniqueStringV5("email"),
+		uniqueStringV5("alias"),
+		{Keys: bson.D{{Key: mongodb.TenantIDField, Value: 1}, {Key: "search_terms", Value: 1}}},
+	},
+	"users": {uniqueStringV5("government_id"), uniqueStringV5("email")},
+}
+
+// indexesV5 keep idempotency keys unique per tenant and remove them once they expire.
+var indexesV5 = map[string][]mongo.IndexModel{
+	"idempotency_keys": {
+		uniqueStringV5("key"),
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	},
+}
+
+// uniqueIndexesV6 replace the unique indexes of version 5, leaving deleted documents out.
+var uniqueIndexesV6 = map[string][]mongo.IndexModel{
+	"players": {uniqueActiveStringV6("government_id"), uniqueActiveStringV6("email"), uniqueActiveStringV6("alias")},
+	"users":   {uniqueActiveStringV6("government_id"), uniqueActiveStringV6("email")},
+}
+
+// indexesV7 let the documents of a tenant in a shared database be counted without reading them, the
+// rest of the collections having indexes that start with the tenant ID already.
+var indexesV7 = map[string][]mongo.IndexModel{
+	"categories":    {{Keys: bson.D{{Key: mongodb.TenantIDField, Value: 1}}}},
+	"tournaments":   {{Keys: bson.D{{Key: mongodb.TenantIDField, Value: 1}}}},
+	"player_merges": {{Keys: bson.D{{Key: mongodb.TenantIDField, Value: 1}}}},
+}
+
+// indexNamesV3 are the names of the indexes a database has at version 3.
+var indexNamesV3 = map[string][]string{
+	"players": {"government_id_1", "email_1", "alias_1", "search_terms_1"},
+	"users":   {"government_id_1", "email_1"},
+}
+
+// uniqueIndexNamesV5 are the names of the unique indexes a database has at version 5, which also
+// applied to deleted documents.
+var uniqueIndexNamesV5 = map[string][]string{
+	"players": {"tenant_id_1_government_id_1", "tenant_id_1_email_1", "tenant_id_1_alias_1"},
+	"users":   {"tenant_id_1_government_id_1", "tenant_id_1_email_1"},
+}
+
+func uniqueActiveStringV6(field string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys: bson.D{{Key: mongodb.TenantIDField, Value: 1}, {Key: field, Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.D{
+			{Key: field, Value: bson.M{"$type": "string"}},
+			{Key: "deleted_at", Value: bson.M{"$type": "null"}},
+		}),
+	}
+}
+
+func uniqueStringV5(field string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: mongodb.TenantIDField, Value: 1}, {Key: field, Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{field: bson.M{"$type": "string"}}),
+	}
+}
+
+func uniqueStringV1(field string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: field, Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{field: bson.M{"$type": "string"}}),
+	}
+}
+
+func createIndexes(ctx context.Context, db *mongo.Database, indexes map[string][]mongo.IndexModel) error {
+	for collection, models := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func dropIndexes(ctx context.Context, db *mongo.Database, indexes map[string][]string) error {
+	for collection, names := range indexes {
+		for _, name := range names {
+			_, err := db.Collection(collection).Indexes().DropOne(ctx, name)
+
+			var commandErr mongo.CommandError
+			if errors.As(err, &commandErr) && (commandErr.Name == "IndexNotFound" || commandErr.Name == "NamespaceNotFound") {
+				continue
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -12,8 +12,11 @@ package database
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	query "github.com/Neniel/gotennis/lib/database/query"
 	entity "github.com/Neniel/gotennis/lib/entity"
+	util "github.com/Neniel/gotennis/lib/util"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -70,6 +73,21 @@ func (mr *MockDatabaseMockRecorder) AddPlayer(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPlayer", reflect.TypeOf((*MockDatabase)(nil).AddPlayer), arg0, arg1)
 }
 
+// AddPlayers mocks base method.
+func (m *MockDatabase) AddPlayers(arg0 context.Context, arg1 []*entity.Player) ([]*entity.Player, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPlayers", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.Player)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPlayers indicates an expected call of AddPlayers.
+func (mr *MockDatabaseMockRecorder) AddPlayers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPlayers", reflect.TypeOf((*MockDatabase)(nil).AddPlayers), arg0, arg1)
+}
+
 // AddTenant mocks base method.
 func (m *MockDatabase) AddTenant(arg0 context.Context, arg1 *entity.Tenant) (*entity.Tenant, error) {
 	m.ctrl.T.Helper()
@@ -100,75 +118,162 @@ func (mr *MockDatabaseMockRecorder) AddTournament(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTournament", reflect.TypeOf((*MockDatabase)(nil).AddTournament), arg0, arg1)
 }
 
+// AddUser mocks base method.
+func (m *MockDatabase) AddUser(arg0 context.Context, arg1 *entity.User) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUser", arg0, arg1)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddUser indicates an expected call of AddUser.
+func (mr *MockDatabaseMockRecorder) AddUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockDatabase)(nil).AddUser), arg0, arg1)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockDatabase) CompleteIdempotencyKey(arg0 context.Context, arg1 *entity.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockDatabaseMockRecorder) CompleteIdempotencyKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockDatabase)(nil).CompleteIdempotencyKey), arg0, arg1)
+}
+
+// CountPlayers mocks base method.
+func (m *MockDatabase) CountPlayers(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPlayers", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPlayers indicates an expected call of CountPlayers.
+func (mr *MockDatabaseMockRecorder) CountPlayers(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPlayers", reflect.TypeOf((*MockDatabase)(nil).CountPlayers), arg0)
+}
+
+// CountTournaments mocks base method.
+func (m *MockDatabase) CountTournaments(ctx context.Context, from, to time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTournaments", ctx, from, to)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTournaments indicates an expected call of CountTournaments.
+func (mr *MockDatabaseMockRecorder) CountTournaments(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTournaments", reflect.TypeOf((*MockDatabase)(nil).CountTournaments), ctx, from, to)
+}
+
+// CreateIdempotencyIndexes mocks base method.
+func (m *MockDatabase) CreateIdempotencyIndexes(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyIndexes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdempotencyIndexes indicates an expected call of CreateIdempotencyIndexes.
+func (mr *MockDatabaseMockRecorder) CreateIdempotencyIndexes(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyIndexes", reflect.TypeOf((*MockDatabase)(nil).CreateIdempotencyIndexes), arg0)
+}
+
 // DeleteCategory mocks base method.
-func (m *MockDatabase) DeleteCategory(arg0 context.Context, arg1 string) error {
+func (m *MockDatabase) DeleteCategory(arg0 context.Context, arg1 string, arg2 *int64, arg3 entity.CategoryDeletePolicy, arg4 *entity.Category, arg5 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1, arg2, arg3, arg4, arg5)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCategory indicates an expected call of DeleteCategory.
-func (mr *MockDatabaseMockRecorder) DeleteCategory(arg0, arg1 any) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) DeleteCategory(arg0, arg1, arg2, arg3, arg4, arg5 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockDatabase)(nil).DeleteCategory), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockDatabase)(nil).DeleteCategory), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
 // DeletePlayer mocks base method.
-func (m *MockDatabase) DeletePlayer(arg0 context.Context, arg1 string) error {
+func (m *MockDatabase) DeletePlayer(arg0 context.Context, arg1 string, arg2 *int64, arg3 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePlayer", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeletePlayer", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePlayer indicates an expected call of DeletePlayer.
-func (mr *MockDatabaseMockRecorder) DeletePlayer(arg0, arg1 any) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) DeletePlayer(arg0, arg1, arg2, arg3 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlayer", reflect.TypeOf((*MockDatabase)(nil).DeletePlayer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlayer", reflect.TypeOf((*MockDatabase)(nil).DeletePlayer), arg0, arg1, arg2, arg3)
 }
 
 // DeleteTenant mocks base method.
-func (m *MockDatabase) DeleteTenant(arg0 context.Context, arg1 string) error {
+func (m *MockDatabase) DeleteTenant(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTenant", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteTenant", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteTenant indicates an expected call of DeleteTenant.
-func (mr *MockDatabaseMockRecorder) DeleteTenant(arg0, arg1 any) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) DeleteTenant(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTenant", reflect.TypeOf((*MockDatabase)(nil).DeleteTenant), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTenant", reflect.TypeOf((*MockDatabase)(nil).DeleteTenant), arg0, arg1, arg2)
 }
 
 // DeleteTournament mocks base method.
-func (m *MockDatabase) DeleteTournament(arg0 context.Context, arg1 string) error {
+func (m *MockDatabase) DeleteTournament(arg0 context.Context, arg1 string, arg2 *int64, arg3 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTournament", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteTournament", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteTournament indicates an expected call of DeleteTournament.
-func (mr *MockDatabaseMockRecorder) DeleteTournament(arg0, arg1 any) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) DeleteTournament(arg0, arg1, arg2, arg3 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTournament", reflect.TypeOf((*MockDatabase)(nil).DeleteTournament), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTournament", reflect.TypeOf((*MockDatabase)(nil).DeleteTournament), arg0, arg1, arg2, arg3)
+}
+
+// DropDatabase mocks base method.
+func (m *MockDatabase) DropDatabase(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropDatabase", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropDatabase indicates an expected call of DropDatabase.
+func (mr *MockDatabaseMockRecorder) DropDatabase(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropDatabase", reflect.TypeOf((*MockDatabase)(nil).DropDatabase), arg0)
 }
 
 // GetCategories mocks base method.
-func (m *MockDatabase) GetCategories(arg0 context.Context) ([]entity.Category, error) {
+func (m *MockDatabase) GetCategories(arg0 context.Context, arg1 *query.Query) (*query.Page[entity.Category], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCategories", arg0)
-	ret0, _ := ret[0].([]entity.Category)
+	ret := m.ctrl.Call(m, "GetCategories", arg0, arg1)
+	ret0, _ := ret[0].(*query.Page[entity.Category])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCategories indicates an expected call of GetCategories.
-func (mr *MockDatabaseMockRecorder) GetCategories(arg0 any) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) GetCategories(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockDatabase)(nil).GetCategories), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockDatabase)(nil).GetCategories), arg0, arg1)
 }
 
 // GetCategory mocks base method.
@@ -186,6 +291,36 @@ func (mr *MockDatabaseMockRecorder) GetCategory(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockDatabase)(nil).GetCategory), arg0, arg1)
 }
 
+// GetDeletedTournament mocks base method.
+func (m *MockDatabase) GetDeletedTournament(arg0 context.Context, arg1 string) (*entity.Tournament, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedTournament", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Tournament)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedTournament indicates an expected call of GetDeletedTournament.
+func (mr *MockDatabaseMockRecorder) GetDeletedTournament(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedTournament", reflect.TypeOf((*MockDatabase)(nil).GetDeletedTournament), arg0, arg1)
+}
+
+// GetIdempotencyRecord mocks base method.
+func (m *MockDatabase) GetIdempotencyRecord(ctx context.Context, key string) (*entity.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyRecord", ctx, key)
+	ret0, _ := ret[0].(*entity.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyRecord indicates an expected call of GetIdempotencyRecord.
+func (mr *MockDatabaseMockRecorder) GetIdempotencyRecord(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyRecord", reflect.TypeOf((*MockDatabase)(nil).GetIdempotencyRecord), ctx, key)
+}
+
 // GetPlayer mocks base method.
 func (m *MockDatabase) GetPlayer(arg0 context.Context, arg1 string) (*entity.Player, error) {
 	m.ctrl.T.Helper()
@@ -201,19 +336,34 @@ func (mr *MockDatabaseMockRecorder) GetPlayer(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlayer", reflect.TypeOf((*MockDatabase)(nil).GetPlayer), arg0, arg1)
 }
 
-// GetPlayers mocks base method.
-func (m *MockDatabase) GetPlayers(arg0 context.Context) ([]entity.Player, error) {
+// GetPlayerDuplicateCandidates mocks base method.
+func (m *MockDatabase) GetPlayerDuplicateCandidates(arg0 context.Context, arg1 *entity.Player) ([]entity.Player, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPlayers", arg0)
+	ret := m.ctrl.Call(m, "GetPlayerDuplicateCandidates", arg0, arg1)
 	ret0, _ := ret[0].([]entity.Player)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// GetPlayerDuplicateCandidates indicates an expected call of GetPlayerDuplicateCandidates.
+func (mr *MockDatabaseMockRecorder) GetPlayerDuplicateCandidates(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlayerDuplicateCandidates", reflect.TypeOf((*MockDatabase)(nil).GetPlayerDuplicateCandidates), arg0, arg1)
+}
+
+// GetPlayers mocks base method.
+func (m *MockDatabase) GetPlayers(arg0 context.Context, arg1 *query.Query) (*query.Page[entity.Player], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlayers", arg0, arg1)
+	ret0, _ := ret[0].(*query.Page[entity.Player])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // GetPlayers indicates an expected call of GetPlayers.
-func (mr *MockDatabaseMockRecorder) GetPlayers(arg0 any) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) GetPlayers(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlayers", reflect.TypeOf((*MockDatabase)(nil).GetPlayers), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlayers", reflect.TypeOf((*MockDatabase)(nil).GetPlayers), arg0, arg1)
 }
 
 // GetTenant mocks base method.
@@ -231,19 +381,49 @@ func (mr *MockDatabaseMockRecorder) GetTenant(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenant", reflect.TypeOf((*MockDatabase)(nil).GetTenant), arg0, arg1)
 }
 
+// GetTenantByCustomDomain mocks base method.
+func (m *MockDatabase) GetTenantByCustomDomain(ctx context.Context, domain string) (*entity.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantByCustomDomain", ctx, domain)
+	ret0, _ := ret[0].(*entity.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantByCustomDomain indicates an expected call of GetTenantByCustomDomain.
+func (mr *MockDatabaseMockRecorder) GetTenantByCustomDomain(ctx, domain any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantByCustomDomain", reflect.TypeOf((*MockDatabase)(nil).GetTenantByCustomDomain), ctx, domain)
+}
+
+// GetTenantBySlug mocks base method.
+func (m *MockDatabase) GetTenantBySlug(ctx context.Context, slug string) (*entity.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantBySlug", ctx, slug)
+	ret0, _ := ret[0].(*entity.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantBySlug indicates an expected call of GetTenantBySlug.
+func (mr *MockDatabaseMockRecorder) GetTenantBySlug(ctx, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantBySlug", reflect.TypeOf((*MockDatabase)(nil).GetTenantBySlug), ctx, slug)
+}
+
 // GetTenants mocks base method.
-func (m *MockDatabase) GetTenants(arg0 context.Context) ([]entity.Tenant, error) {
+func (m *MockDatabase) GetTenants(arg0 context.Context, arg1 *query.Query) (*query.Page[entity.Tenant], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTenants", arg0)
-	ret0, _ := ret[0].([]entity.Tenant)
+	ret := m.ctrl.Call(m, "GetTenants", arg0, arg1)
+	ret0, _ := ret[0].(*query.Page[entity.Tenant])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTenants indicates an expected call of GetTenants.
-func (mr *MockDatabaseMockRecorder) GetTenants(arg0 any) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) GetTenants(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockDatabase)(nil).GetTenants), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockDatabase)(nil).GetTenants), arg0, arg1)
 }
 
 // GetTournament mocks base method.
@@ -262,285 +442,870 @@ func (mr *MockDatabaseMockRecorder) GetTournament(arg0, arg1 any) *gomock.Call {
 }
 
 // GetTournaments mocks base method.
-func (m *MockDatabase) GetTournaments(arg0 context.Context) ([]entity.Tournament, error) {
+func (m *MockDatabase) GetTournaments(arg0 context.Context, arg1 *query.Query) (*query.Page[entity.Tournament], error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTournaments", arg0)
-	ret0, _ := ret[0].([]entity.Tournament)
+	ret := m.ctrl.Call(m, "GetTournaments", arg0, arg1)
+	ret0, _ := ret[0].(*query.Page[entity.Tournament])
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetTournaments indicates an expected call of GetTournaments.
-func (mr *MockDatabaseMockRecorder) GetTournaments(arg0 any) *gomock.Call {
+func (mr *MockDatabaseMockRecorder) GetTournaments(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTournaments", reflect.TypeOf((*MockDatabase)(nil).GetTournaments), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTournaments", reflect.TypeOf((*MockDatabase)(nil).GetTournaments), arg0, arg1)
 }
 
-// IsAvailable mocks base method.
-func (m *MockDatabase) IsAvailable(arg0 context.Context, arg1, arg2 string) (bool, error) {
+// IndexPlayersForSearch mocks base method.
+func (m *MockDatabase) IndexPlayersForSearch(arg0 context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsAvailable", arg0, arg1, arg2)
-	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "IndexPlayersForSearch", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// IsAvailable indicates an expected call of IsAvailable.
-func (mr *MockDatabaseMockRecorder) IsAvailable(arg0, arg1, arg2 any) *gomock.Call {
+// IndexPlayersForSearch indicates an expected call of IndexPlayersForSearch.
+func (mr *MockDatabaseMockRecorder) IndexPlayersForSearch(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAvailable", reflect.TypeOf((*MockDatabase)(nil).IsAvailable), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexPlayersForSearch", reflect.TypeOf((*MockDatabase)(nil).IndexPlayersForSearch), arg0)
 }
 
-// Login mocks base method.
-func (m *MockDatabase) Login(ctx context.Context, userID, password string) error {
+// IndexTenants mocks base method.
+func (m *MockDatabase) IndexTenants(arg0 context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, userID, password)
+	ret := m.ctrl.Call(m, "IndexTenants", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Login indicates an expected call of Login.
-func (mr *MockDatabaseMockRecorder) Login(ctx, userID, password any) *gomock.Call {
+// IndexTenants indicates an expected call of IndexTenants.
+func (mr *MockDatabaseMockRecorder) IndexTenants(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockDatabase)(nil).Login), ctx, userID, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexTenants", reflect.TypeOf((*MockDatabase)(nil).IndexTenants), arg0)
 }
 
-// UpdateCategory mocks base method.
-func (m *MockDatabase) UpdateCategory(arg0 context.Context, arg1 *entity.Category) (*entity.Category, error) {
+// IsAvailable mocks base method.
+func (m *MockDatabase) IsAvailable(arg0 context.Context, arg1, arg2 string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateCategory", arg0, arg1)
-	ret0, _ := ret[0].(*entity.Category)
+	ret := m.ctrl.Call(m, "IsAvailable", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateCategory indicates an expected call of UpdateCategory.
-func (mr *MockDatabaseMockRecorder) UpdateCategory(arg0, arg1 any) *gomock.Call {
+// IsAvailable indicates an expected call of IsAvailable.
+func (mr *MockDatabaseMockRecorder) IsAvailable(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockDatabase)(nil).UpdateCategory), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAvailable", reflect.TypeOf((*MockDatabase)(nil).IsAvailable), arg0, arg1, arg2)
 }
 
-// UpdatePlayer mocks base method.
-func (m *MockDatabase) UpdatePlayer(arg0 context.Context, arg1 *entity.Player) (*entity.Player, error) {
+// IsEmpty mocks base method.
+func (m *MockDatabase) IsEmpty(arg0 context.Context) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePlayer", arg0, arg1)
-	ret0, _ := ret[0].(*entity.Player)
+	ret := m.ctrl.Call(m, "IsEmpty", arg0)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdatePlayer indicates an expected call of UpdatePlayer.
-func (mr *MockDatabaseMockRecorder) UpdatePlayer(arg0, arg1 any) *gomock.Call {
+// IsEmpty indicates an expected call of IsEmpty.
+func (mr *MockDatabaseMockRecorder) IsEmpty(arg0 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePlayer", reflect.TypeOf((*MockDatabase)(nil).UpdatePlayer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmpty", reflect.TypeOf((*MockDatabase)(nil).IsEmpty), arg0)
 }
 
-// UpdateTournament mocks base method.
-func (m *MockDatabase) UpdateTournament(arg0 context.Context, arg1 *entity.Tournament) (*entity.Tournament, error) {
+// IsTenantDatabaseNameTaken mocks base method.
+func (m *MockDatabase) IsTenantDatabaseNameTaken(ctx context.Context, databaseName string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateTournament", arg0, arg1)
-	ret0, _ := ret[0].(*entity.Tournament)
+	ret := m.ctrl.Call(m, "IsTenantDatabaseNameTaken", ctx, databaseName)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateTournament indicates an expected call of UpdateTournament.
-func (mr *MockDatabaseMockRecorder) UpdateTournament(arg0, arg1 any) *gomock.Call {
+// IsTenantDatabaseNameTaken indicates an expected call of IsTenantDatabaseNameTaken.
+func (mr *MockDatabaseMockRecorder) IsTenantDatabaseNameTaken(ctx, databaseName any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTournament", reflect.TypeOf((*MockDatabase)(nil).UpdateTournament), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTenantDatabaseNameTaken", reflect.TypeOf((*MockDatabase)(nil).IsTenantDatabaseNameTaken), ctx, databaseName)
 }
 
-// MockDBReader is a mock of DBReader interface.
-type MockDBReader struct {
-	ctrl     *gomock.Controller
-	recorder *MockDBReaderMockRecorder
+// IsTenantSlugTaken mocks base method.
+func (m *MockDatabase) IsTenantSlugTaken(ctx context.Context, slug string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTenantSlugTaken", ctx, slug)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// MockDBReaderMockRecorder is the mock recorder for MockDBReader.
-type MockDBReaderMockRecorder struct {
-	mock *MockDBReader
+// IsTenantSlugTaken indicates an expected call of IsTenantSlugTaken.
+func (mr *MockDatabaseMockRecorder) IsTenantSlugTaken(ctx, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTenantSlugTaken", reflect.TypeOf((*MockDatabase)(nil).IsTenantSlugTaken), ctx, slug)
 }
 
-// NewMockDBReader creates a new mock instance.
-func NewMockDBReader(ctrl *gomock.Controller) *MockDBReader {
-	mock := &MockDBReader{ctrl: ctrl}
-	mock.recorder = &MockDBReaderMockRecorder{mock}
-	return mock
+// Login mocks base method.
+func (m *MockDatabase) Login(ctx context.Context, userID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockDBReader) EXPECT() *MockDBReaderMockRecorder {
-	return m.recorder
+// Login indicates an expected call of Login.
+func (mr *MockDatabaseMockRecorder) Login(ctx, userID, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockDatabase)(nil).Login), ctx, userID, password)
 }
 
-// GetCategories mocks base method.
-func (m *MockDBReader) GetCategories(arg0 context.Context) ([]entity.Category, error) {
+// MergePlayers mocks base method.
+func (m *MockDatabase) MergePlayers(arg0 context.Context, arg1 *entity.Player, arg2 *entity.PlayerMerge) (*entity.Player, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCategories", arg0)
-	ret0, _ := ret[0].([]entity.Category)
+	ret := m.ctrl.Call(m, "MergePlayers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entity.Player)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCategories indicates an expected call of GetCategories.
-func (mr *MockDBReaderMockRecorder) GetCategories(arg0 any) *gomock.Call {
+// MergePlayers indicates an expected call of MergePlayers.
+func (mr *MockDatabaseMockRecorder) MergePlayers(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockDBReader)(nil).GetCategories), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergePlayers", reflect.TypeOf((*MockDatabase)(nil).MergePlayers), arg0, arg1, arg2)
 }
 
-// GetCategory mocks base method.
-func (m *MockDBReader) GetCategory(arg0 context.Context, arg1 string) (*entity.Category, error) {
+// PatchCategory mocks base method.
+func (m *MockDatabase) PatchCategory(arg0 context.Context, arg1 *entity.Category, arg2 *util.Patch) (*entity.Category, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCategory", arg0, arg1)
+	ret := m.ctrl.Call(m, "PatchCategory", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*entity.Category)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCategory indicates an expected call of GetCategory.
-func (mr *MockDBReaderMockRecorder) GetCategory(arg0, arg1 any) *gomock.Call {
+// PatchCategory indicates an expected call of PatchCategory.
+func (mr *MockDatabaseMockRecorder) PatchCategory(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockDBReader)(nil).GetCategory), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCategory", reflect.TypeOf((*MockDatabase)(nil).PatchCategory), arg0, arg1, arg2)
 }
 
-// GetPlayer mocks base method.
-func (m *MockDBReader) GetPlayer(arg0 context.Context, arg1 string) (*entity.Player, error) {
+// PatchPlayer mocks base method.
+func (m *MockDatabase) PatchPlayer(arg0 context.Context, arg1 *entity.Player, arg2 *util.Patch) (*entity.Player, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPlayer", arg0, arg1)
+	ret := m.ctrl.Call(m, "PatchPlayer", arg0, arg1, arg2)
 	ret0, _ := ret[0].(*entity.Player)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPlayer indicates an expected call of GetPlayer.
-func (mr *MockDBReaderMockRecorder) GetPlayer(arg0, arg1 any) *gomock.Call {
+// PatchPlayer indicates an expected call of PatchPlayer.
+func (mr *MockDatabaseMockRecorder) PatchPlayer(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlayer", reflect.TypeOf((*MockDBReader)(nil).GetPlayer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchPlayer", reflect.TypeOf((*MockDatabase)(nil).PatchPlayer), arg0, arg1, arg2)
 }
 
-// GetPlayers mocks base method.
-func (m *MockDBReader) GetPlayers(arg0 context.Context) ([]entity.Player, error) {
+// PatchTournament mocks base method.
+func (m *MockDatabase) PatchTournament(arg0 context.Context, arg1 *entity.Tournament, arg2 *util.Patch) (*entity.Tournament, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPlayers", arg0)
-	ret0, _ := ret[0].([]entity.Player)
+	ret := m.ctrl.Call(m, "PatchTournament", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entity.Tournament)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPlayers indicates an expected call of GetPlayers.
-func (mr *MockDBReaderMockRecorder) GetPlayers(arg0 any) *gomock.Call {
+// PatchTournament indicates an expected call of PatchTournament.
+func (mr *MockDatabaseMockRecorder) PatchTournament(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlayers", reflect.TypeOf((*MockDBReader)(nil).GetPlayers), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTournament", reflect.TypeOf((*MockDatabase)(nil).PatchTournament), arg0, arg1, arg2)
 }
 
-// GetTenant mocks base method.
-func (m *MockDBReader) GetTenant(arg0 context.Context, arg1 string) (*entity.Tenant, error) {
+// PurgeDeleted mocks base method.
+func (m *MockDatabase) PurgeDeleted(arg0 context.Context, arg1 string, arg2 time.Time) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTenant", arg0, arg1)
-	ret0, _ := ret[0].(*entity.Tenant)
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTenant indicates an expected call of GetTenant.
-func (mr *MockDBReaderMockRecorder) GetTenant(arg0, arg1 any) *gomock.Call {
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockDatabaseMockRecorder) PurgeDeleted(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenant", reflect.TypeOf((*MockDBReader)(nil).GetTenant), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockDatabase)(nil).PurgeDeleted), arg0, arg1, arg2)
 }
 
-// GetTenants mocks base method.
-func (m *MockDBReader) GetTenants(arg0 context.Context) ([]entity.Tenant, error) {
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockDatabase) ReleaseIdempotencyKey(arg0 context.Context, arg1 *entity.IdempotencyRecord) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTenants", arg0)
-	ret0, _ := ret[0].([]entity.Tenant)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetTenants indicates an expected call of GetTenants.
-func (mr *MockDBReaderMockRecorder) GetTenants(arg0 any) *gomock.Call {
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockDatabaseMockRecorder) ReleaseIdempotencyKey(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockDBReader)(nil).GetTenants), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockDatabase)(nil).ReleaseIdempotencyKey), arg0, arg1)
 }
 
-// GetTournament mocks base method.
-func (m *MockDBReader) GetTournament(arg0 context.Context, arg1 string) (*entity.Tournament, error) {
+// ReleaseTenantDatabase mocks base method.
+func (m *MockDatabase) ReleaseTenantDatabase(ctx context.Context, databaseName string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTournament", arg0, arg1)
-	ret0, _ := ret[0].(*entity.Tournament)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ReleaseTenantDatabase", ctx, databaseName)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetTournament indicates an expected call of GetTournament.
-func (mr *MockDBReaderMockRecorder) GetTournament(arg0, arg1 any) *gomock.Call {
+// ReleaseTenantDatabase indicates an expected call of ReleaseTenantDatabase.
+func (mr *MockDatabaseMockRecorder) ReleaseTenantDatabase(ctx, databaseName any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTournament", reflect.TypeOf((*MockDBReader)(nil).GetTournament), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseTenantDatabase", reflect.TypeOf((*MockDatabase)(nil).ReleaseTenantDatabase), ctx, databaseName)
 }
 
-// GetTournaments mocks base method.
-func (m *MockDBReader) GetTournaments(arg0 context.Context) ([]entity.Tournament, error) {
+// ReserveIdempotencyKey mocks base method.
+func (m *MockDatabase) ReserveIdempotencyKey(arg0 context.Context, arg1 *entity.IdempotencyRecord) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTournaments", arg0)
-	ret0, _ := ret[0].([]entity.Tournament)
+	ret := m.ctrl.Call(m, "ReserveIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetTournaments indicates an expected call of GetTournaments.
-func (mr *MockDBReaderMockRecorder) GetTournaments(arg0 any) *gomock.Call {
+// ReserveIdempotencyKey indicates an expected call of ReserveIdempotencyKey.
+func (mr *MockDatabaseMockRecorder) ReserveIdempotencyKey(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTournaments", reflect.TypeOf((*MockDBReader)(nil).GetTournaments), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIdempotencyKey", reflect.TypeOf((*MockDatabase)(nil).ReserveIdempotencyKey), arg0, arg1)
 }
 
-// IsAvailable mocks base method.
-func (m *MockDBReader) IsAvailable(arg0 context.Context, arg1, arg2 string) (bool, error) {
+// ReserveTenantDatabase mocks base method.
+func (m *MockDatabase) ReserveTenantDatabase(ctx context.Context, databaseName string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsAvailable", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "ReserveTenantDatabase", ctx, databaseName)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// IsAvailable indicates an expected call of IsAvailable.
-func (mr *MockDBReaderMockRecorder) IsAvailable(arg0, arg1, arg2 any) *gomock.Call {
+// ReserveTenantDatabase indicates an expected call of ReserveTenantDatabase.
+func (mr *MockDatabaseMockRecorder) ReserveTenantDatabase(ctx, databaseName any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAvailable", reflect.TypeOf((*MockDBReader)(nil).IsAvailable), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveTenantDatabase", reflect.TypeOf((*MockDatabase)(nil).ReserveTenantDatabase), ctx, databaseName)
 }
 
-// Login mocks base method.
-func (m *MockDBReader) Login(ctx context.Context, userID, password string) error {
+// RestoreCategory mocks base method.
+func (m *MockDatabase) RestoreCategory(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", ctx, userID, password)
+	ret := m.ctrl.Call(m, "RestoreCategory", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Login indicates an expected call of Login.
-func (mr *MockDBReaderMockRecorder) Login(ctx, userID, password any) *gomock.Call {
+// RestoreCategory indicates an expected call of RestoreCategory.
+func (mr *MockDatabaseMockRecorder) RestoreCategory(arg0, arg1 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockDBReader)(nil).Login), ctx, userID, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCategory", reflect.TypeOf((*MockDatabase)(nil).RestoreCategory), arg0, arg1)
 }
 
-// MockDBWriter is a mock of DBWriter interface.
-type MockDBWriter struct {
-	ctrl     *gomock.Controller
-	recorder *MockDBWriterMockRecorder
+// RestorePlayer mocks base method.
+func (m *MockDatabase) RestorePlayer(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePlayer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// MockDBWriterMockRecorder is the mock recorder for MockDBWriter.
-type MockDBWriterMockRecorder struct {
-	mock *MockDBWriter
+// RestorePlayer indicates an expected call of RestorePlayer.
+func (mr *MockDatabaseMockRecorder) RestorePlayer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePlayer", reflect.TypeOf((*MockDatabase)(nil).RestorePlayer), arg0, arg1)
 }
 
-// NewMockDBWriter creates a new mock instance.
-func NewMockDBWriter(ctrl *gomock.Controller) *MockDBWriter {
-	mock := &MockDBWriter{ctrl: ctrl}
-	mock.recorder = &MockDBWriterMockRecorder{mock}
-	return mock
+// RestoreTenant mocks base method.
+func (m *MockDatabase) RestoreTenant(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTenant", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
+// RestoreTenant indicates an expected call of RestoreTenant.
+func (mr *MockDatabaseMockRecorder) RestoreTenant(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTenant", reflect.TypeOf((*MockDatabase)(nil).RestoreTenant), arg0, arg1)
+}
+
+// RestoreTournament mocks base method.
+func (m *MockDatabase) RestoreTournament(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTournament", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTournament indicates an expected call of RestoreTournament.
+func (mr *MockDatabaseMockRecorder) RestoreTournament(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTournament", reflect.TypeOf((*MockDatabase)(nil).RestoreTournament), arg0, arg1)
+}
+
+// RewriteTenantConnectionStrings mocks base method.
+func (m *MockDatabase) RewriteTenantConnectionStrings(ctx context.Context, rewrite func(string, string) (string, error)) (int, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewriteTenantConnectionStrings", ctx, rewrite)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RewriteTenantConnectionStrings indicates an expected call of RewriteTenantConnectionStrings.
+func (mr *MockDatabaseMockRecorder) RewriteTenantConnectionStrings(ctx, rewrite any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewriteTenantConnectionStrings", reflect.TypeOf((*MockDatabase)(nil).RewriteTenantConnectionStrings), ctx, rewrite)
+}
+
+// SearchPlayers mocks base method.
+func (m *MockDatabase) SearchPlayers(arg0 context.Context, arg1 []string, arg2 func(*entity.Player) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPlayers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SearchPlayers indicates an expected call of SearchPlayers.
+func (mr *MockDatabaseMockRecorder) SearchPlayers(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPlayers", reflect.TypeOf((*MockDatabase)(nil).SearchPlayers), arg0, arg1, arg2)
+}
+
+// StorageSize mocks base method.
+func (m *MockDatabase) StorageSize(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StorageSize", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StorageSize indicates an expected call of StorageSize.
+func (mr *MockDatabaseMockRecorder) StorageSize(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageSize", reflect.TypeOf((*MockDatabase)(nil).StorageSize), arg0)
+}
+
+// StreamCategories mocks base method.
+func (m *MockDatabase) StreamCategories(arg0 context.Context, arg1 *query.Query, arg2 func(*entity.Category) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamCategories", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamCategories indicates an expected call of StreamCategories.
+func (mr *MockDatabaseMockRecorder) StreamCategories(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamCategories", reflect.TypeOf((*MockDatabase)(nil).StreamCategories), arg0, arg1, arg2)
+}
+
+// StreamPlayers mocks base method.
+func (m *MockDatabase) StreamPlayers(arg0 context.Context, arg1 *query.Query, arg2 func(*entity.Player) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamPlayers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamPlayers indicates an expected call of StreamPlayers.
+func (mr *MockDatabaseMockRecorder) StreamPlayers(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamPlayers", reflect.TypeOf((*MockDatabase)(nil).StreamPlayers), arg0, arg1, arg2)
+}
+
+// StreamTournaments mocks base method.
+func (m *MockDatabase) StreamTournaments(arg0 context.Context, arg1 *query.Query, arg2 func(*entity.Tournament) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamTournaments", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamTournaments indicates an expected call of StreamTournaments.
+func (mr *MockDatabaseMockRecorder) StreamTournaments(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTournaments", reflect.TypeOf((*MockDatabase)(nil).StreamTournaments), arg0, arg1, arg2)
+}
+
+// UpdateCategory mocks base method.
+func (m *MockDatabase) UpdateCategory(arg0 context.Context, arg1 *entity.Category) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockDatabaseMockRecorder) UpdateCategory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockDatabase)(nil).UpdateCategory), arg0, arg1)
+}
+
+// UpdatePlayer mocks base method.
+func (m *MockDatabase) UpdatePlayer(arg0 context.Context, arg1 *entity.Player) (*entity.Player, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePlayer", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Player)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePlayer indicates an expected call of UpdatePlayer.
+func (mr *MockDatabaseMockRecorder) UpdatePlayer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePlayer", reflect.TypeOf((*MockDatabase)(nil).UpdatePlayer), arg0, arg1)
+}
+
+// UpdateTenant mocks base method.
+func (m *MockDatabase) UpdateTenant(arg0 context.Context, arg1 *entity.Tenant) (*entity.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTenant", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTenant indicates an expected call of UpdateTenant.
+func (mr *MockDatabaseMockRecorder) UpdateTenant(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTenant", reflect.TypeOf((*MockDatabase)(nil).UpdateTenant), arg0, arg1)
+}
+
+// UpdateTournament mocks base method.
+func (m *MockDatabase) UpdateTournament(arg0 context.Context, arg1 *entity.Tournament) (*entity.Tournament, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTournament", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Tournament)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTournament indicates an expected call of UpdateTournament.
+func (mr *MockDatabaseMockRecorder) UpdateTournament(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTournament", reflect.TypeOf((*MockDatabase)(nil).UpdateTournament), arg0, arg1)
+}
+
+// MockDBReader is a mock of DBReader interface.
+type MockDBReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockDBReaderMockRecorder
+}
+
+// MockDBReaderMockRecorder is the mock recorder for MockDBReader.
+type MockDBReaderMockRecorder struct {
+	mock *MockDBReader
+}
+
+// NewMockDBReader creates a new mock instance.
+func NewMockDBReader(ctrl *gomock.Controller) *MockDBReader {
+	mock := &MockDBReader{ctrl: ctrl}
+	mock.recorder = &MockDBReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDBReader) EXPECT() *MockDBReaderMockRecorder {
+	return m.recorder
+}
+
+// CountPlayers mocks base method.
+func (m *MockDBReader) CountPlayers(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPlayers", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPlayers indicates an expected call of CountPlayers.
+func (mr *MockDBReaderMockRecorder) CountPlayers(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPlayers", reflect.TypeOf((*MockDBReader)(nil).CountPlayers), arg0)
+}
+
+// CountTournaments mocks base method.
+func (m *MockDBReader) CountTournaments(ctx context.Context, from, to time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTournaments", ctx, from, to)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTournaments indicates an expected call of CountTournaments.
+func (mr *MockDBReaderMockRecorder) CountTournaments(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTournaments", reflect.TypeOf((*MockDBReader)(nil).CountTournaments), ctx, from, to)
+}
+
+// GetCategories mocks base method.
+func (m *MockDBReader) GetCategories(arg0 context.Context, arg1 *query.Query) (*query.Page[entity.Category], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", arg0, arg1)
+	ret0, _ := ret[0].(*query.Page[entity.Category])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockDBReaderMockRecorder) GetCategories(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockDBReader)(nil).GetCategories), arg0, arg1)
+}
+
+// GetCategory mocks base method.
+func (m *MockDBReader) GetCategory(arg0 context.Context, arg1 string) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockDBReaderMockRecorder) GetCategory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockDBReader)(nil).GetCategory), arg0, arg1)
+}
+
+// GetDeletedTournament mocks base method.
+func (m *MockDBReader) GetDeletedTournament(arg0 context.Context, arg1 string) (*entity.Tournament, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedTournament", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Tournament)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedTournament indicates an expected call of GetDeletedTournament.
+func (mr *MockDBReaderMockRecorder) GetDeletedTournament(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedTournament", reflect.TypeOf((*MockDBReader)(nil).GetDeletedTournament), arg0, arg1)
+}
+
+// GetIdempotencyRecord mocks base method.
+func (m *MockDBReader) GetIdempotencyRecord(ctx context.Context, key string) (*entity.IdempotencyRecord, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyRecord", ctx, key)
+	ret0, _ := ret[0].(*entity.IdempotencyRecord)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyRecord indicates an expected call of GetIdempotencyRecord.
+func (mr *MockDBReaderMockRecorder) GetIdempotencyRecord(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyRecord", reflect.TypeOf((*MockDBReader)(nil).GetIdempotencyRecord), ctx, key)
+}
+
+// GetPlayer mocks base method.
+func (m *MockDBReader) GetPlayer(arg0 context.Context, arg1 string) (*entity.Player, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlayer", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Player)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlayer indicates an expected call of GetPlayer.
+func (mr *MockDBReaderMockRecorder) GetPlayer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlayer", reflect.TypeOf((*MockDBReader)(nil).GetPlayer), arg0, arg1)
+}
+
+// GetPlayerDuplicateCandidates mocks base method.
+func (m *MockDBReader) GetPlayerDuplicateCandidates(arg0 context.Context, arg1 *entity.Player) ([]entity.Player, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlayerDuplicateCandidates", arg0, arg1)
+	ret0, _ := ret[0].([]entity.Player)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlayerDuplicateCandidates indicates an expected call of GetPlayerDuplicateCandidates.
+func (mr *MockDBReaderMockRecorder) GetPlayerDuplicateCandidates(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlayerDuplicateCandidates", reflect.TypeOf((*MockDBReader)(nil).GetPlayerDuplicateCandidates), arg0, arg1)
+}
+
+// GetPlayers mocks base method.
+func (m *MockDBReader) GetPlayers(arg0 context.Context, arg1 *query.Query) (*query.Page[entity.Player], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlayers", arg0, arg1)
+	ret0, _ := ret[0].(*query.Page[entity.Player])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlayers indicates an expected call of GetPlayers.
+func (mr *MockDBReaderMockRecorder) GetPlayers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlayers", reflect.TypeOf((*MockDBReader)(nil).GetPlayers), arg0, arg1)
+}
+
+// GetTenant mocks base method.
+func (m *MockDBReader) GetTenant(arg0 context.Context, arg1 string) (*entity.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenant", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenant indicates an expected call of GetTenant.
+func (mr *MockDBReaderMockRecorder) GetTenant(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenant", reflect.TypeOf((*MockDBReader)(nil).GetTenant), arg0, arg1)
+}
+
+// GetTenantByCustomDomain mocks base method.
+func (m *MockDBReader) GetTenantByCustomDomain(ctx context.Context, domain string) (*entity.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantByCustomDomain", ctx, domain)
+	ret0, _ := ret[0].(*entity.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantByCustomDomain indicates an expected call of GetTenantByCustomDomain.
+func (mr *MockDBReaderMockRecorder) GetTenantByCustomDomain(ctx, domain any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantByCustomDomain", reflect.TypeOf((*MockDBReader)(nil).GetTenantByCustomDomain), ctx, domain)
+}
+
+// GetTenantBySlug mocks base method.
+func (m *MockDBReader) GetTenantBySlug(ctx context.Context, slug string) (*entity.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenantBySlug", ctx, slug)
+	ret0, _ := ret[0].(*entity.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenantBySlug indicates an expected call of GetTenantBySlug.
+func (mr *MockDBReaderMockRecorder) GetTenantBySlug(ctx, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenantBySlug", reflect.TypeOf((*MockDBReader)(nil).GetTenantBySlug), ctx, slug)
+}
+
+// GetTenants mocks base method.
+func (m *MockDBReader) GetTenants(arg0 context.Context, arg1 *query.Query) (*query.Page[entity.Tenant], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenants", arg0, arg1)
+	ret0, _ := ret[0].(*query.Page[entity.Tenant])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenants indicates an expected call of GetTenants.
+func (mr *MockDBReaderMockRecorder) GetTenants(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockDBReader)(nil).GetTenants), arg0, arg1)
+}
+
+// GetTournament mocks base method.
+func (m *MockDBReader) GetTournament(arg0 context.Context, arg1 string) (*entity.Tournament, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTournament", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Tournament)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTournament indicates an expected call of GetTournament.
+func (mr *MockDBReaderMockRecorder) GetTournament(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTournament", reflect.TypeOf((*MockDBReader)(nil).GetTournament), arg0, arg1)
+}
+
+// GetTournaments mocks base method.
+func (m *MockDBReader) GetTournaments(arg0 context.Context, arg1 *query.Query) (*query.Page[entity.Tournament], error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTournaments", arg0, arg1)
+	ret0, _ := ret[0].(*query.Page[entity.Tournament])
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTournaments indicates an expected call of GetTournaments.
+func (mr *MockDBReaderMockRecorder) GetTournaments(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTournaments", reflect.TypeOf((*MockDBReader)(nil).GetTournaments), arg0, arg1)
+}
+
+// IsAvailable mocks base method.
+func (m *MockDBReader) IsAvailable(arg0 context.Context, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAvailable", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAvailable indicates an expected call of IsAvailable.
+func (mr *MockDBReaderMockRecorder) IsAvailable(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAvailable", reflect.TypeOf((*MockDBReader)(nil).IsAvailable), arg0, arg1, arg2)
+}
+
+// IsEmpty mocks base method.
+func (m *MockDBReader) IsEmpty(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEmpty", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEmpty indicates an expected call of IsEmpty.
+func (mr *MockDBReaderMockRecorder) IsEmpty(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmpty", reflect.TypeOf((*MockDBReader)(nil).IsEmpty), arg0)
+}
+
+// IsTenantDatabaseNameTaken mocks base method.
+func (m *MockDBReader) IsTenantDatabaseNameTaken(ctx context.Context, databaseName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTenantDatabaseNameTaken", ctx, databaseName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTenantDatabaseNameTaken indicates an expected call of IsTenantDatabaseNameTaken.
+func (mr *MockDBReaderMockRecorder) IsTenantDatabaseNameTaken(ctx, databaseName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTenantDatabaseNameTaken", reflect.TypeOf((*MockDBReader)(nil).IsTenantDatabaseNameTaken), ctx, databaseName)
+}
+
+// IsTenantSlugTaken mocks base method.
+func (m *MockDBReader) IsTenantSlugTaken(ctx context.Context, slug string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTenantSlugTaken", ctx, slug)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsTenantSlugTaken indicates an expected call of IsTenantSlugTaken.
+func (mr *MockDBReaderMockRecorder) IsTenantSlugTaken(ctx, slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTenantSlugTaken", reflect.TypeOf((*MockDBReader)(nil).IsTenantSlugTaken), ctx, slug)
+}
+
+// Login mocks base method.
+func (m *MockDBReader) Login(ctx context.Context, userID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, userID, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockDBReaderMockRecorder) Login(ctx, userID, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockDBReader)(nil).Login), ctx, userID, password)
+}
+
+// SearchPlayers mocks base method.
+func (m *MockDBReader) SearchPlayers(arg0 context.Context, arg1 []string, arg2 func(*entity.Player) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPlayers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SearchPlayers indicates an expected call of SearchPlayers.
+func (mr *MockDBReaderMockRecorder) SearchPlayers(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPlayers", reflect.TypeOf((*MockDBReader)(nil).SearchPlayers), arg0, arg1, arg2)
+}
+
+// StorageSize mocks base method.
+func (m *MockDBReader) StorageSize(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StorageSize", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StorageSize indicates an expected call of StorageSize.
+func (mr *MockDBReaderMockRecorder) StorageSize(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageSize", reflect.TypeOf((*MockDBReader)(nil).StorageSize), arg0)
+}
+
+// StreamCategories mocks base method.
+func (m *MockDBReader) StreamCategories(arg0 context.Context, arg1 *query.Query, arg2 func(*entity.Category) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamCategories", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamCategories indicates an expected call of StreamCategories.
+func (mr *MockDBReaderMockRecorder) StreamCategories(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamCategories", reflect.TypeOf((*MockDBReader)(nil).StreamCategories), arg0, arg1, arg2)
+}
+
+// StreamPlayers mocks base method.
+func (m *MockDBReader) StreamPlayers(arg0 context.Context, arg1 *query.Query, arg2 func(*entity.Player) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamPlayers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamPlayers indicates an expected call of StreamPlayers.
+func (mr *MockDBReaderMockRecorder) StreamPlayers(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamPlayers", reflect.TypeOf((*MockDBReader)(nil).StreamPlayers), arg0, arg1, arg2)
+}
+
+// StreamTournaments mocks base method.
+func (m *MockDBReader) StreamTournaments(arg0 context.Context, arg1 *query.Query, arg2 func(*entity.Tournament) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamTournaments", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamTournaments indicates an expected call of StreamTournaments.
+func (mr *MockDBReaderMockRecorder) StreamTournaments(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamTournaments", reflect.TypeOf((*MockDBReader)(nil).StreamTournaments), arg0, arg1, arg2)
+}
+
+// MockDBWriter is a mock of DBWriter interface.
+type MockDBWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDBWriterMockRecorder
+}
+
+// MockDBWriterMockRecorder is the mock recorder for MockDBWriter.
+type MockDBWriterMockRecorder struct {
+	mock *MockDBWriter
+}
+
+// NewMockDBWriter creates a new mock instance.
+func NewMockDBWriter(ctrl *gomock.Controller) *MockDBWriter {
+	mock := &MockDBWriter{ctrl: ctrl}
+	mock.recorder = &MockDBWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockDBWriter) EXPECT() *MockDBWriterMockRecorder {
 	return m.recorder
 }
@@ -575,6 +1340,21 @@ func (mr *MockDBWriterMockRecorder) AddPlayer(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPlayer", reflect.TypeOf((*MockDBWriter)(nil).AddPlayer), arg0, arg1)
 }
 
+// AddPlayers mocks base method.
+func (m *MockDBWriter) AddPlayers(arg0 context.Context, arg1 []*entity.Player) ([]*entity.Player, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPlayers", arg0, arg1)
+	ret0, _ := ret[0].([]*entity.Player)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPlayers indicates an expected call of AddPlayers.
+func (mr *MockDBWriterMockRecorder) AddPlayers(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPlayers", reflect.TypeOf((*MockDBWriter)(nil).AddPlayers), arg0, arg1)
+}
+
 // AddTenant mocks base method.
 func (m *MockDBWriter) AddTenant(arg0 context.Context, arg1 *entity.Tenant) (*entity.Tenant, error) {
 	m.ctrl.T.Helper()
@@ -605,60 +1385,350 @@ func (mr *MockDBWriterMockRecorder) AddTournament(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTournament", reflect.TypeOf((*MockDBWriter)(nil).AddTournament), arg0, arg1)
 }
 
+// AddUser mocks base method.
+func (m *MockDBWriter) AddUser(arg0 context.Context, arg1 *entity.User) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddUser", arg0, arg1)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddUser indicates an expected call of AddUser.
+func (mr *MockDBWriterMockRecorder) AddUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddUser", reflect.TypeOf((*MockDBWriter)(nil).AddUser), arg0, arg1)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockDBWriter) CompleteIdempotencyKey(arg0 context.Context, arg1 *entity.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockDBWriterMockRecorder) CompleteIdempotencyKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockDBWriter)(nil).CompleteIdempotencyKey), arg0, arg1)
+}
+
+// CreateIdempotencyIndexes mocks base method.
+func (m *MockDBWriter) CreateIdempotencyIndexes(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyIndexes", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdempotencyIndexes indicates an expected call of CreateIdempotencyIndexes.
+func (mr *MockDBWriterMockRecorder) CreateIdempotencyIndexes(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyIndexes", reflect.TypeOf((*MockDBWriter)(nil).CreateIdempotencyIndexes), arg0)
+}
+
 // DeleteCategory mocks base method.
-func (m *MockDBWriter) DeleteCategory(arg0 context.Context, arg1 string) error {
+func (m *MockDBWriter) DeleteCategory(arg0 context.Context, arg1 string, arg2 *int64, arg3 entity.CategoryDeletePolicy, arg4 *entity.Category, arg5 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteCategory", arg0, arg1, arg2, arg3, arg4, arg5)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteCategory indicates an expected call of DeleteCategory.
-func (mr *MockDBWriterMockRecorder) DeleteCategory(arg0, arg1 any) *gomock.Call {
+func (mr *MockDBWriterMockRecorder) DeleteCategory(arg0, arg1, arg2, arg3, arg4, arg5 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockDBWriter)(nil).DeleteCategory), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockDBWriter)(nil).DeleteCategory), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
 // DeletePlayer mocks base method.
-func (m *MockDBWriter) DeletePlayer(arg0 context.Context, arg1 string) error {
+func (m *MockDBWriter) DeletePlayer(arg0 context.Context, arg1 string, arg2 *int64, arg3 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePlayer", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeletePlayer", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePlayer indicates an expected call of DeletePlayer.
-func (mr *MockDBWriterMockRecorder) DeletePlayer(arg0, arg1 any) *gomock.Call {
+func (mr *MockDBWriterMockRecorder) DeletePlayer(arg0, arg1, arg2, arg3 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlayer", reflect.TypeOf((*MockDBWriter)(nil).DeletePlayer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePlayer", reflect.TypeOf((*MockDBWriter)(nil).DeletePlayer), arg0, arg1, arg2, arg3)
 }
 
 // DeleteTenant mocks base method.
-func (m *MockDBWriter) DeleteTenant(arg0 context.Context, arg1 string) error {
+func (m *MockDBWriter) DeleteTenant(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTenant", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteTenant", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteTenant indicates an expected call of DeleteTenant.
-func (mr *MockDBWriterMockRecorder) DeleteTenant(arg0, arg1 any) *gomock.Call {
+func (mr *MockDBWriterMockRecorder) DeleteTenant(arg0, arg1, arg2 any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTenant", reflect.TypeOf((*MockDBWriter)(nil).DeleteTenant), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTenant", reflect.TypeOf((*MockDBWriter)(nil).DeleteTenant), arg0, arg1, arg2)
 }
 
 // DeleteTournament mocks base method.
-func (m *MockDBWriter) DeleteTournament(arg0 context.Context, arg1 string) error {
+func (m *MockDBWriter) DeleteTournament(arg0 context.Context, arg1 string, arg2 *int64, arg3 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteTournament", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteTournament", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteTournament indicates an expected call of DeleteTournament.
-func (mr *MockDBWriterMockRecorder) DeleteTournament(arg0, arg1 any) *gomock.Call {
+func (mr *MockDBWriterMockRecorder) DeleteTournament(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTournament", reflect.TypeOf((*MockDBWriter)(nil).DeleteTournament), arg0, arg1, arg2, arg3)
+}
+
+// DropDatabase mocks base method.
+func (m *MockDBWriter) DropDatabase(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropDatabase", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropDatabase indicates an expected call of DropDatabase.
+func (mr *MockDBWriterMockRecorder) DropDatabase(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropDatabase", reflect.TypeOf((*MockDBWriter)(nil).DropDatabase), arg0)
+}
+
+// IndexPlayersForSearch mocks base method.
+func (m *MockDBWriter) IndexPlayersForSearch(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexPlayersForSearch", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IndexPlayersForSearch indicates an expected call of IndexPlayersForSearch.
+func (mr *MockDBWriterMockRecorder) IndexPlayersForSearch(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexPlayersForSearch", reflect.TypeOf((*MockDBWriter)(nil).IndexPlayersForSearch), arg0)
+}
+
+// IndexTenants mocks base method.
+func (m *MockDBWriter) IndexTenants(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexTenants", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IndexTenants indicates an expected call of IndexTenants.
+func (mr *MockDBWriterMockRecorder) IndexTenants(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexTenants", reflect.TypeOf((*MockDBWriter)(nil).IndexTenants), arg0)
+}
+
+// MergePlayers mocks base method.
+func (m *MockDBWriter) MergePlayers(arg0 context.Context, arg1 *entity.Player, arg2 *entity.PlayerMerge) (*entity.Player, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergePlayers", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entity.Player)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergePlayers indicates an expected call of MergePlayers.
+func (mr *MockDBWriterMockRecorder) MergePlayers(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergePlayers", reflect.TypeOf((*MockDBWriter)(nil).MergePlayers), arg0, arg1, arg2)
+}
+
+// PatchCategory mocks base method.
+func (m *MockDBWriter) PatchCategory(arg0 context.Context, arg1 *entity.Category, arg2 *util.Patch) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchCategory", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchCategory indicates an expected call of PatchCategory.
+func (mr *MockDBWriterMockRecorder) PatchCategory(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchCategory", reflect.TypeOf((*MockDBWriter)(nil).PatchCategory), arg0, arg1, arg2)
+}
+
+// PatchPlayer mocks base method.
+func (m *MockDBWriter) PatchPlayer(arg0 context.Context, arg1 *entity.Player, arg2 *util.Patch) (*entity.Player, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchPlayer", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entity.Player)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchPlayer indicates an expected call of PatchPlayer.
+func (mr *MockDBWriterMockRecorder) PatchPlayer(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchPlayer", reflect.TypeOf((*MockDBWriter)(nil).PatchPlayer), arg0, arg1, arg2)
+}
+
+// PatchTournament mocks base method.
+func (m *MockDBWriter) PatchTournament(arg0 context.Context, arg1 *entity.Tournament, arg2 *util.Patch) (*entity.Tournament, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchTournament", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*entity.Tournament)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PatchTournament indicates an expected call of PatchTournament.
+func (mr *MockDBWriterMockRecorder) PatchTournament(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTournament", reflect.TypeOf((*MockDBWriter)(nil).PatchTournament), arg0, arg1, arg2)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockDBWriter) PurgeDeleted(arg0 context.Context, arg1 string, arg2 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockDBWriterMockRecorder) PurgeDeleted(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockDBWriter)(nil).PurgeDeleted), arg0, arg1, arg2)
+}
+
+// ReleaseIdempotencyKey mocks base method.
+func (m *MockDBWriter) ReleaseIdempotencyKey(arg0 context.Context, arg1 *entity.IdempotencyRecord) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseIdempotencyKey indicates an expected call of ReleaseIdempotencyKey.
+func (mr *MockDBWriterMockRecorder) ReleaseIdempotencyKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseIdempotencyKey", reflect.TypeOf((*MockDBWriter)(nil).ReleaseIdempotencyKey), arg0, arg1)
+}
+
+// ReleaseTenantDatabase mocks base method.
+func (m *MockDBWriter) ReleaseTenantDatabase(ctx context.Context, databaseName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseTenantDatabase", ctx, databaseName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseTenantDatabase indicates an expected call of ReleaseTenantDatabase.
+func (mr *MockDBWriterMockRecorder) ReleaseTenantDatabase(ctx, databaseName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseTenantDatabase", reflect.TypeOf((*MockDBWriter)(nil).ReleaseTenantDatabase), ctx, databaseName)
+}
+
+// ReserveIdempotencyKey mocks base method.
+func (m *MockDBWriter) ReserveIdempotencyKey(arg0 context.Context, arg1 *entity.IdempotencyRecord) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveIdempotencyKey indicates an expected call of ReserveIdempotencyKey.
+func (mr *MockDBWriterMockRecorder) ReserveIdempotencyKey(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveIdempotencyKey", reflect.TypeOf((*MockDBWriter)(nil).ReserveIdempotencyKey), arg0, arg1)
+}
+
+// ReserveTenantDatabase mocks base method.
+func (m *MockDBWriter) ReserveTenantDatabase(ctx context.Context, databaseName string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveTenantDatabase", ctx, databaseName)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveTenantDatabase indicates an expected call of ReserveTenantDatabase.
+func (mr *MockDBWriterMockRecorder) ReserveTenantDatabase(ctx, databaseName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveTenantDatabase", reflect.TypeOf((*MockDBWriter)(nil).ReserveTenantDatabase), ctx, databaseName)
+}
+
+// RestoreCategory mocks base method.
+func (m *MockDBWriter) RestoreCategory(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreCategory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreCategory indicates an expected call of RestoreCategory.
+func (mr *MockDBWriterMockRecorder) RestoreCategory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreCategory", reflect.TypeOf((*MockDBWriter)(nil).RestoreCategory), arg0, arg1)
+}
+
+// RestorePlayer mocks base method.
+func (m *MockDBWriter) RestorePlayer(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestorePlayer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestorePlayer indicates an expected call of RestorePlayer.
+func (mr *MockDBWriterMockRecorder) RestorePlayer(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestorePlayer", reflect.TypeOf((*MockDBWriter)(nil).RestorePlayer), arg0, arg1)
+}
+
+// RestoreTenant mocks base method.
+func (m *MockDBWriter) RestoreTenant(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTenant", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTenant indicates an expected call of RestoreTenant.
+func (mr *MockDBWriterMockRecorder) RestoreTenant(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTenant", reflect.TypeOf((*MockDBWriter)(nil).RestoreTenant), arg0, arg1)
+}
+
+// RestoreTournament mocks base method.
+func (m *MockDBWriter) RestoreTournament(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTournament", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTournament indicates an expected call of RestoreTournament.
+func (mr *MockDBWriterMockRecorder) RestoreTournament(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTournament", reflect.TypeOf((*MockDBWriter)(nil).RestoreTournament), arg0, arg1)
+}
+
+// RewriteTenantConnectionStrings mocks base method.
+func (m *MockDBWriter) RewriteTenantConnectionStrings(ctx context.Context, rewrite func(string, string) (string, error)) (int, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewriteTenantConnectionStrings", ctx, rewrite)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RewriteTenantConnectionStrings indicates an expected call of RewriteTenantConnectionStrings.
+func (mr *MockDBWriterMockRecorder) RewriteTenantConnectionStrings(ctx, rewrite any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTournament", reflect.TypeOf((*MockDBWriter)(nil).DeleteTournament), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewriteTenantConnectionStrings", reflect.TypeOf((*MockDBWriter)(nil).RewriteTenantConnectionStrings), ctx, rewrite)
 }
 
 // UpdateCategory mocks base method.
@@ -691,6 +1761,21 @@ func (mr *MockDBWriterMockRecorder) UpdatePlayer(arg0, arg1 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePlayer", reflect.TypeOf((*MockDBWriter)(nil).UpdatePlayer), arg0, arg1)
 }
 
+// UpdateTenant mocks base method.
+func (m *MockDBWriter) UpdateTenant(arg0 context.Context, arg1 *entity.Tenant) (*entity.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTenant", arg0, arg1)
+	ret0, _ := ret[0].(*entity.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTenant indicates an expected call of UpdateTenant.
+func (mr *MockDBWriterMockRecorder) UpdateTenant(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTenant", reflect.TypeOf((*MockDBWriter)(nil).UpdateTenant), arg0, arg1)
+}
+
 // UpdateTournament mocks base method.
 func (m *MockDBWriter) UpdateTournament(arg0 context.Context, arg1 *entity.Tournament) (*entity.Tournament, error) {
 	m.ctrl.T.Helper()
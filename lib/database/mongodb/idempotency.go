@@ -0,0 +1,84 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const idempotencyKeysCollection = "idempotency_keys"
+
+// CreateIdempotencyIndexes creates the indexes that keep idempotency keys unique per tenant and
+// remove them once they expire.
+func (mdbw *MongoDbWriter) CreateIdempotencyIndexes(ctx context.Context) error {
+	_, err := mdbw.DB.Collection(idempotencyKeysCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+		uniqueString("key"),
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+// GetIdempotencyRecord returns the record of key, as long as it has not expired.
+func (mdbr *MongoDbReader) GetIdempotencyRecord(ctx context.Context, key string) (*entity.IdempotencyRecord, error) {
+	var record entity.IdempotencyRecord
+	err := mdbr.collection(idempotencyKeysCollection).FindOne(ctx, bson.D{
+		{Key: "key", Value: key},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: time.Now().UTC()}}},
+	}).Decode(&record)
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// ReserveIdempotencyKey stores record, which has not been completed yet, unless another record of
+// its key has not expired, in which case it returns false.
+func (mdbw *MongoDbWriter) ReserveIdempotencyKey(ctx context.Context, record *entity.IdempotencyRecord) (bool, error) {
+	record.ID = primitive.NewObjectID()
+	record.CreatedAt = time.Now().UTC()
+
+	// Expired records are only removed by MongoDB once a minute, so they would still hold the key
+	_, err := mdbw.collection(idempotencyKeysCollection).DeleteMany(ctx, bson.D{
+		{Key: "key", Value: record.Key},
+		{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: record.CreatedAt}}},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	_, err = mdbw.collection(idempotencyKeysCollection).InsertOne(ctx, record)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompleteIdempotencyKey stores the response of the record reserved for its key, and when it
+// expires. Nothing is stored when the record expired meanwhile.
+func (mdbw *MongoDbWriter) CompleteIdempotencyKey(ctx context.Context, record *entity.IdempotencyRecord) error {
+	_, err := mdbw.collection(idempotencyKeysCollection).UpdateOne(ctx, bson.D{{Key: "_id", Value: record.ID}}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status_code", Value: record.StatusCode},
+		{Key: "header", Value: record.Header},
+		{Key: "body", Value: record.Body},
+		{Key: "expires_at", Value: record.ExpiresAt},
+	}}})
+	return err
+}
+
+// ReleaseIdempotencyKey removes record, so that the request can be sent again. A record reserved
+// for the same key after it expired is left as it is.
+func (mdbw *MongoDbWriter) ReleaseIdempotencyKey(ctx context.Context, record *entity.IdempotencyRecord) error {
+	_, err := mdbw.collection(idempotencyKeysCollection).DeleteMany(ctx, bson.D{{Key: "_id", Value: record.ID}})
+	return err
+}
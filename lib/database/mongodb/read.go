@@ -3,18 +3,37 @@ package mongodb
 import (
 	"context"
 	"errors"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/Neniel/gotennis/lib/database/query"
 	"github.com/Neniel/gotennis/lib/entity"
 	"github.com/Neniel/gotennis/lib/security"
+	"github.com/Neniel/gotennis/lib/util"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// notDeleted matches the documents that have not been soft deleted
+var notDeleted = bson.E{Key: "deleted_at", Value: nil}
+
+const (
+	maxDuplicateCandidates = 2000
+	maxSearchCandidates    = 5000
+	searchPrefixLength     = 2
+	streamBatchSize        = 500
 )
 
 type MongoDbReader struct {
 	MongodbClient *mongo.Client
 	DB            *mongo.Database
+	// tenantID is set when the database is shared by several tenants, see scopedCollection
+	tenantID *primitive.ObjectID
 }
 
 func NewMongoDbReader(client *mongo.Client, databaseName string) *MongoDbReader {
@@ -25,62 +44,136 @@ func NewMongoDbReader(client *mongo.Client, databaseName string) *MongoDbReader
 
 }
 
-func (mdbr *MongoDbReader) GetCategories(ctx context.Context) ([]entity.Category, error) {
-	cursor, err := mdbr.DB.Collection("categories").Find(ctx, bson.D{})
+// NewSharedMongoDbReader returns a reader that only sees the documents of tenantID in a database
+// shared by several tenants.
+func NewSharedMongoDbReader(client *mongo.Client, databaseName string, tenantID primitive.ObjectID) *MongoDbReader {
+	reader := NewMongoDbReader(client, databaseName)
+	reader.tenantID = &tenantID
+	return reader
+}
+
+func (mdbr *MongoDbReader) collection(name string) *scopedCollection {
+	return newScopedCollection(mdbr.DB, name, mdbr.tenantID)
+}
+
+func (mdbr *MongoDbReader) GetCategories(ctx context.Context, q *query.Query) (*query.Page[entity.Category], error) {
+	return findPage[entity.Category](ctx, mdbr.collection("categories"), q)
+}
+
+func (mdbr *MongoDbReader) StreamCategories(ctx context.Context, q *query.Query, fn func(*entity.Category) error) error {
+	return stream(ctx, mdbr.collection("categories"), q, fn)
+}
+
+func (mdbr *MongoDbReader) GetCategory(ctx context.Context, id string) (*entity.Category, error) {
+	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
-	var output []entity.Category
-	if err := cursor.All(ctx, &output); err != nil {
+
+	var result entity.Category
+	err = mdbr.collection("categories").FindOne(context.Background(), bson.D{{Key: "_id", Value: _id}, notDeleted}).Decode(&result)
+	if err != nil {
 		return nil, err
 	}
+	return &result, nil
+}
 
-	return output, nil
+func (mdbr *MongoDbReader) GetPlayers(ctx context.Context, q *query.Query) (*query.Page[entity.Player], error) {
+	return findPage[entity.Player](ctx, mdbr.collection("players"), q)
 }
 
-func (mdbr *MongoDbReader) GetCategory(ctx context.Context, id string) (*entity.Category, error) {
+func (mdbr *MongoDbReader) StreamPlayers(ctx context.Context, q *query.Query, fn func(*entity.Player) error) error {
+	return stream(ctx, mdbr.collection("players"), q, fn)
+}
+
+func (mdbr *MongoDbReader) GetPlayer(ctx context.Context, id string) (*entity.Player, error) {
 	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
-	var result entity.Category
-	err = mdbr.DB.Collection("categories").FindOne(context.Background(), bson.D{{Key: "_id", Value: _id}}).Decode(&result)
+	var result entity.Player
+	err = mdbr.collection("players").FindOne(ctx, bson.D{{Key: "_id", Value: _id}, notDeleted}).Decode(&result)
 	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
-func (mdbr *MongoDbReader) GetPlayers(ctx context.Context) ([]entity.Player, error) {
-	cursor, err := mdbr.DB.Collection("players").Find(ctx, bson.D{})
+// SearchPlayers calls fn with the not deleted players that, for every one of the given search
+// terms, have a search term starting with its first two letters, oldest first. Those are the
+// candidates that may match the terms, even with typos after those letters, so they still need to
+// be ranked. Up to maxSearchCandidates of them are read, in batches, so that the ranking does not
+// depend on which of them the database returns first unless there are more.
+func (mdbr *MongoDbReader) SearchPlayers(ctx context.Context, terms []string, fn func(*entity.Player) error) error {
+	prefixes := bson.A{}
+	for _, term := range terms {
+		prefix := []rune(term)
+		prefix = prefix[:min(len(prefix), searchPrefixLength)]
+		prefixes = append(prefixes, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(string(prefix))})
+	}
+
+	filter := bson.D{notDeleted, {Key: "search_terms", Value: bson.D{{Key: "$all", Value: prefixes}}}}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(maxSearchCandidates).SetBatchSize(streamBatchSize)
+	cursor, err := mdbr.collection("players").Find(ctx, filter, opts)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	var output []entity.Player
-	if err := cursor.All(ctx, &output); err != nil {
-		return nil, err
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var player entity.Player
+		if err := cursor.Decode(&player); err != nil {
+			return err
+		}
+
+		if err := fn(&player); err != nil {
+			return err
+		}
 	}
 
-	return output, nil
+	return cursor.Err()
 }
 
-func (mdbr *MongoDbReader) GetPlayer(ctx context.Context, id string) (*entity.Player, error) {
-	_id, err := primitive.ObjectIDFromHex(id)
+// GetPlayerDuplicateCandidates returns the other not deleted players that share the birthdate, the
+// phone number or a word of the last name with player.
+func (mdbr *MongoDbReader) GetPlayerDuplicateCandidates(ctx context.Context, player *entity.Player) ([]entity.Player, error) {
+	conditions := bson.A{
+		bson.D{{Key: "search_terms", Value: bson.D{{Key: "$in", Value: util.SearchTerms(player.LastName)}}}},
+	}
+
+	if player.Birthdate != nil {
+		conditions = append(conditions, bson.D{{Key: "birthdate", Value: player.Birthdate}})
+	}
+
+	if player.PhoneNumber != "" {
+		conditions = append(conditions, bson.D{{Key: "phone_number", Value: player.PhoneNumber}})
+	}
+
+	filter := bson.D{
+		notDeleted,
+		{Key: "_id", Value: bson.D{{Key: "$ne", Value: player.ID}}},
+		{Key: "$or", Value: conditions},
+	}
+
+	cursor, err := mdbr.collection("players").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(maxDuplicateCandidates))
 	if err != nil {
 		return nil, err
 	}
+	defer cursor.Close(ctx)
 
-	var result entity.Player
-	err = mdbr.DB.Collection("players").FindOne(ctx, bson.D{{Key: "_id", Value: _id}}).Decode(&result)
-	if err != nil {
+	players := make([]entity.Player, 0)
+	if err := cursor.All(ctx, &players); err != nil {
 		return nil, err
 	}
-	return &result, nil
+
+	return players, nil
 }
 
+// IsAvailable tells whether no other not deleted player has value in field. Values of deleted players
+// can be taken, in which case those players cannot be restored until the value is free again.
 func (mdbr *MongoDbReader) IsAvailable(ctx context.Context, field string, value string) (bool, error) {
-	result := mdbr.DB.Collection("players").FindOne(context.TODO(), bson.D{{Key: field, Value: value}})
+	result := mdbr.collection("players").FindOne(ctx, bson.D{{Key: field, Value: value}, notDeleted})
 	if errors.Is(result.Err(), mongo.ErrNoDocuments) {
 		return true, nil
 	}
@@ -92,27 +185,41 @@ func (mdbr *MongoDbReader) IsAvailable(ctx context.Context, field string, value
 	return false, nil
 }
 
-func (mdbr *MongoDbReader) GetTournaments(ctx context.Context) ([]entity.Tournament, error) {
-	cursor, err := mdbr.DB.Collection("tournaments").Find(ctx, bson.D{})
+func (mdbr *MongoDbReader) GetTournaments(ctx context.Context, q *query.Query) (*query.Page[entity.Tournament], error) {
+	return findPage[entity.Tournament](ctx, mdbr.collection("tournaments"), q)
+}
+
+func (mdbr *MongoDbReader) StreamTournaments(ctx context.Context, q *query.Query, fn func(*entity.Tournament) error) error {
+	return stream(ctx, mdbr.collection("tournaments"), q, fn)
+}
+
+func (mdbr *MongoDbReader) GetTournament(ctx context.Context, id string) (*entity.Tournament, error) {
+	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
-	var output []entity.Tournament
-	if err := cursor.All(ctx, &output); err != nil {
+
+	var result entity.Tournament
+	err = mdbr.collection("tournaments").FindOne(ctx, bson.D{{Key: "_id", Value: _id}, notDeleted}).Decode(&result)
+	if err != nil {
 		return nil, err
 	}
 
-	return output, nil
+	return &result, nil
 }
 
-func (mdbr *MongoDbReader) GetTournament(ctx context.Context, id string) (*entity.Tournament, error) {
+// GetDeletedTournament returns the tournament as long as it has been deleted and not purged yet.
+func (mdbr *MongoDbReader) GetDeletedTournament(ctx context.Context, id string) (*entity.Tournament, error) {
 	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
 
 	var result entity.Tournament
-	err = mdbr.DB.Collection("tournaments").FindOne(ctx, bson.D{{Key: "_id", Value: _id}}).Decode(&result)
+	err = mdbr.collection("tournaments").FindOne(ctx, bson.D{
+		{Key: "_id", Value: _id},
+		{Key: "deleted_at", Value: bson.D{{Key: "$ne", Value: nil}}},
+	}).Decode(&result)
 	if err != nil {
 		return nil, err
 	}
@@ -120,28 +227,40 @@ func (mdbr *MongoDbReader) GetTournament(ctx context.Context, id string) (*entit
 	return &result, nil
 }
 
-func (mdbr *MongoDbReader) GetTenants(ctx context.Context) ([]entity.Tenant, error) {
-	cursor, err := mdbr.DB.Collection("tenants").Find(ctx, bson.D{})
+func (mdbr *MongoDbReader) GetTenants(ctx context.Context, q *query.Query) (*query.Page[entity.Tenant], error) {
+	return findPage[entity.Tenant](ctx, mdbr.collection("tenants"), q)
+}
 
+func (mdbr *MongoDbReader) GetTenant(ctx context.Context, id string) (*entity.Tenant, error) {
+	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, err
 	}
-	var tenants []entity.Tenant
-	if err := cursor.All(ctx, &tenants); err != nil {
+
+	var result entity.Tenant
+	err = mdbr.collection("tenants").FindOne(ctx, bson.D{{Key: "_id", Value: _id}, notDeleted}).Decode(&result)
+	if err != nil {
 		return nil, err
 	}
 
-	return tenants, nil
+	return &result, nil
 }
 
-func (mdbr *MongoDbReader) GetTenant(ctx context.Context, id string) (*entity.Tenant, error) {
-	_id, err := primitive.ObjectIDFromHex(id)
+// GetTenantByCustomDomain returns the tenant that verified domain.
+func (mdbr *MongoDbReader) GetTenantByCustomDomain(ctx context.Context, domain string) (*entity.Tenant, error) {
+	var result entity.Tenant
+	err := mdbr.collection("tenants").FindOne(ctx, bson.D{{Key: "verified_domains", Value: domain}, notDeleted}).Decode(&result)
 	if err != nil {
 		return nil, err
 	}
 
+	return &result, nil
+}
+
+// GetTenantBySlug returns the tenant whose subdomain is slug.
+func (mdbr *MongoDbReader) GetTenantBySlug(ctx context.Context, slug string) (*entity.Tenant, error) {
 	var result entity.Tenant
-	err = mdbr.DB.Collection("tenants").FindOne(ctx, bson.D{{Key: "_id", Value: _id}}).Decode(&result)
+	err := mdbr.collection("tenants").FindOne(ctx, bson.D{{Key: "slug", Value: slug}, notDeleted}).Decode(&result)
 	if err != nil {
 		return nil, err
 	}
@@ -149,14 +268,279 @@ func (mdbr *MongoDbReader) GetTenant(ctx context.Context, id string) (*entity.Te
 	return &result, nil
 }
 
+// IsTenantSlugTaken tells whether any tenant, even a deleted one that has not been purged yet, has
+// slug as its subdomain.
+func (mdbr *MongoDbReader) IsTenantSlugTaken(ctx context.Context, slug string) (bool, error) {
+	count, err := mdbr.collection("tenants").CountDocuments(ctx, bson.D{{Key: "slug", Value: slug}}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// IsTenantDatabaseNameTaken tells whether any tenant, even a deleted one whose database has not
+// been purged yet, has its data in the database databaseName.
+func (mdbr *MongoDbReader) IsTenantDatabaseNameTaken(ctx context.Context, databaseName string) (bool, error) {
+	count, err := mdbr.collection("tenants").CountDocuments(ctx, bson.D{{Key: "database_name", Value: databaseName}}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// IsEmpty tells whether the database, or the part of it of the tenant when it is shared, has no
+// documents at all.
+func (mdbr *MongoDbReader) IsEmpty(ctx context.Context) (bool, error) {
+	names, err := mdbr.DB.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return false, err
+	}
+
+	for _, name := range names {
+		count, err := mdbr.collection(name).CountDocuments(ctx, bson.D{}, options.Count().SetLimit(1))
+		if err != nil {
+			return false, err
+		}
+
+		if count > 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// CountPlayers returns how many not deleted players there are.
+func (mdbr *MongoDbReader) CountPlayers(ctx context.Context) (int64, error) {
+	return mdbr.collection("players").CountDocuments(ctx, bson.D{notDeleted})
+}
+
+// CountTournaments returns how many not deleted tournaments start in [from, to).
+func (mdbr *MongoDbReader) CountTournaments(ctx context.Context, from time.Time, to time.Time) (int64, error) {
+	return mdbr.collection("tournaments").CountDocuments(ctx, bson.D{
+		notDeleted,
+		{Key: "start_date", Value: bson.D{{Key: "$gte", Value: from}, {Key: "$lt", Value: to}}},
+	})
+}
+
+// StorageSize returns the size in bytes of the documents of the database, deleted ones included, or
+// an estimate of the size of the documents of the tenant when it is shared.
+func (mdbr *MongoDbReader) StorageSize(ctx context.Context) (int64, error) {
+	if mdbr.tenantID == nil {
+		var stats struct {
+			DataSize float64 `bson:"dataSize"`
+		}
+
+		if err := mdbr.DB.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&stats); err != nil {
+			return 0, err
+		}
+
+		return int64(stats.DataSize), nil
+	}
+
+	names, err := mdbr.DB.ListCollectionNames(ctx, bson.D{{Key: "type", Value: "collection"}})
+	if err != nil {
+		return 0, err
+	}
+
+	// Adding up the size of every document of the tenant would read all of them on every write, so
+	// the documents of the tenant are counted through the index on the tenant ID instead, and taken
+	// to be as large as the average document of their collection
+	var size int64
+	for _, name := range names {
+		var stats struct {
+			AvgObjSize float64 `bson:"avgObjSize"`
+		}
+
+		if err := mdbr.DB.RunCommand(ctx, bson.D{{Key: "collStats", Value: name}}).Decode(&stats); err != nil {
+			return 0, err
+		}
+
+		if stats.AvgObjSize == 0 {
+			continue
+		}
+
+		count, err := mdbr.collection(name).CountDocuments(ctx, bson.D{})
+		if err != nil {
+			return 0, err
+		}
+
+		size += int64(stats.AvgObjSize * float64(count))
+	}
+
+	return size, nil
+}
+
 func (mdbr *MongoDbReader) Login(ctx context.Context, userID string, password string) error {
 	user := entity.User{}
 
-	err := mdbr.DB.Collection("users").FindOne(ctx, bson.M{"government_id": userID}).Decode(&user)
+	err := mdbr.collection("users").FindOne(ctx, bson.M{"government_id": userID, "deleted_at": nil}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return util.ErrInvalidCredentials
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := security.CheckPassword(user.Password, password); err != nil {
+		return util.ErrInvalidCredentials
+	}
+
+	return nil
+}
+
+// findPage returns the page of not deleted documents of collection described by q.
+// afterCursor returns the conditions of the documents sorted after the one with sortValue and id.
+// Null and missing values sort before any other, but no comparison operator matches them, so they
+// are matched on their own: after the cursor when descending, and before any other value otherwise.
+func afterCursor(sortBy string, descending bool, operator string, sortValue bson.RawValue, id primitive.ObjectID) bson.A {
+	if sortValue.Type == bsontype.Null {
+		after := bson.A{bson.D{{Key: sortBy, Value: nil}, {Key: "_id", Value: bson.D{{Key: operator, Value: id}}}}}
+		if !descending {
+			after = append(after, bson.D{{Key: sortBy, Value: bson.D{{Key: "$ne", Value: nil}}}})
+		}
+
+		return after
+	}
+
+	after := bson.A{
+		bson.D{{Key: sortBy, Value: bson.D{{Key: operator, Value: sortValue}}}},
+		bson.D{{Key: sortBy, Value: sortValue}, {Key: "_id", Value: bson.D{{Key: operator, Value: id}}}},
+	}
+	if descending {
+		after = append(after, bson.D{{Key: sortBy, Value: nil}})
+	}
+
+	return after
+}
+
+func findPage[T any](ctx context.Context, collection *scopedCollection, q *query.Query) (*query.Page[T], error) {
+	if q == nil {
+		q = &query.Query{}
+	}
+
+	filter := listFilter(q)
+
+	sortBy := q.SortBy
+	if sortBy == "" {
+		sortBy = "_id"
+	}
+
+	direction, operator := 1, "$gt"
+	if q.Descending {
+		direction, operator = -1, "$lt"
+	}
+
+	if q.Cursor != "" {
+		sortValue, id, err := query.DecodeCursor(q.Cursor, sortBy, q.Descending)
+		if err != nil {
+			return nil, err
+		}
+
+		if sortBy == "_id" {
+			filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: operator, Value: id}}})
+		} else {
+			filter = append(filter, bson.E{Key: "$or", Value: afterCursor(sortBy, q.Descending, operator, sortValue, id)})
+		}
+	}
+
+	sort := bson.D{{Key: sortBy, Value: direction}}
+	if sortBy != "_id" {
+		sort = append(sort, bson.E{Key: "_id", Value: direction})
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = query.DefaultLimit
+	}
 
+	// One extra document is requested to know whether there is a next page
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(sort).SetLimit(limit+1))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	page := &query.Page[T]{
+		Items: make([]T, 0),
+	}
+
+	var last bson.Raw
+	for cursor.Next(ctx) {
+		if int64(len(page.Items)) == limit {
+			sortValue, err := last.LookupErr(strings.Split(sortBy, ".")...)
+			if err != nil {
+				sortValue = bson.RawValue{Type: bsontype.Null}
+			}
+
+			page.NextCursor, err = query.EncodeCursor(sortBy, q.Descending, sortValue, last.Lookup("_id").ObjectID())
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		var item T
+		if err := cursor.Decode(&item); err != nil {
+			return nil, err
+		}
+
+		page.Items = append(page.Items, item)
+		last = append(bson.Raw(nil), cursor.Current...)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// stream calls fn with every not deleted document of collection matching the filters of q, in the
+// order of q, decoding one document at a time. It stops at the first error returned by fn.
+func stream[T any](ctx context.Context, collection *scopedCollection, q *query.Query, fn func(*T) error) error {
+	if q == nil {
+		q = &query.Query{}
+	}
+
+	direction := 1
+	if q.Descending {
+		direction = -1
+	}
+
+	sort := bson.D{{Key: "_id", Value: direction}}
+	if q.SortBy != "" && q.SortBy != "_id" {
+		sort = append(bson.D{{Key: q.SortBy, Value: direction}}, sort...)
+	}
+
+	cursor, err := collection.Find(ctx, listFilter(q), options.Find().SetSort(sort).SetBatchSize(streamBatchSize))
 	if err != nil {
 		return err
 	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var item T
+		if err := cursor.Decode(&item); err != nil {
+			return err
+		}
+
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func listFilter(q *query.Query) bson.D {
+	filter := bson.D{notDeleted}
+	for _, f := range q.Filters {
+		filter = append(filter, bson.E{Key: f.Path, Value: f.Value})
+	}
 
-	return security.CheckPassword(user.Password, password)
+	return filter
 }
@@ -0,0 +1,127 @@
+package mongodb
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database/query"
+	"github.com/Neniel/gotennis/lib/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoDbReader_GetPlayers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	lastFilter := func(mt *mtest.T) bson.Raw {
+		events := mt.GetAllStartedEvents()
+		return events[len(events)-1].Command.Lookup("filter").Document()
+	}
+
+	hasCondition := func(filter bson.Raw, want bson.D) bool {
+		wanted, _ := bson.Marshal(want)
+		values, _ := filter.Lookup("$or").Array().Values()
+		for _, value := range values {
+			if bson.Raw(value.Value).String() == bson.Raw(wanted).String() {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	mt.Run("Pages_ascending_from_null_values_to_the_rest", func(mt *mtest.T) {
+		first, second, third := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "tenant.players", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: first}},
+			bson.D{{Key: "_id", Value: second}, {Key: "alias", Value: nil}},
+			bson.D{{Key: "_id", Value: third}, {Key: "alias", Value: "Rafa"}},
+		))
+
+		reader := NewMongoDbReader(mt.Client, "tenant")
+		page, err := reader.GetPlayers(context.Background(), &query.Query{SortBy: "alias", Limit: 2})
+		if err != nil {
+			mt.Fatal(err)
+		}
+
+		if page.NextCursor == "" {
+			mt.Fatal("there is no next page")
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "tenant.players", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: third}, {Key: "alias", Value: "Rafa"}},
+		))
+
+		if _, err := reader.GetPlayers(context.Background(), &query.Query{SortBy: "alias", Limit: 2, Cursor: page.NextCursor}); err != nil {
+			mt.Fatal(err)
+		}
+
+		filter := lastFilter(mt)
+		if !hasCondition(filter, bson.D{{Key: "alias", Value: nil}, {Key: "_id", Value: bson.D{{Key: "$gt", Value: second}}}}) {
+			mt.Errorf("the rest of the players without alias are not matched: %v", filter)
+		}
+
+		if !hasCondition(filter, bson.D{{Key: "alias", Value: bson.D{{Key: "$ne", Value: nil}}}}) {
+			mt.Errorf("the players with alias are not matched: %v", filter)
+		}
+	})
+
+	mt.Run("Pages_descending_from_values_to_null_ones", func(mt *mtest.T) {
+		first, second := primitive.NewObjectID(), primitive.NewObjectID()
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "tenant.players", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: first}, {Key: "alias", Value: "Rafa"}},
+			bson.D{{Key: "_id", Value: second}},
+		))
+
+		reader := NewMongoDbReader(mt.Client, "tenant")
+		page, err := reader.GetPlayers(context.Background(), &query.Query{SortBy: "alias", Descending: true, Limit: 1})
+		if err != nil {
+			mt.Fatal(err)
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "tenant.players", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: second}},
+		))
+
+		if _, err := reader.GetPlayers(context.Background(), &query.Query{SortBy: "alias", Descending: true, Limit: 1, Cursor: page.NextCursor}); err != nil {
+			mt.Fatal(err)
+		}
+
+		if filter := lastFilter(mt); !hasCondition(filter, bson.D{{Key: "alias", Value: nil}}) {
+			mt.Errorf("the players without alias are not matched: %v", filter)
+		}
+	})
+}
+
+func TestMongoDbReader_SearchPlayers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Reads_a_bounded_number_of_candidates_sharing_the_first_letters", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "tenant.players", mtest.FirstBatch))
+
+		reader := NewMongoDbReader(mt.Client, "tenant")
+		if err := reader.SearchPlayers(context.Background(), []string{"rafael", "n"}, func(*entity.Player) error { return nil }); err != nil {
+			mt.Fatal(err)
+		}
+
+		command := mt.GetStartedEvent().Command
+		var patterns []string
+		values, _ := command.Lookup("filter", "search_terms", "$all").Array().Values()
+		for _, value := range values {
+			pattern, _ := value.Regex()
+			patterns = append(patterns, pattern)
+		}
+
+		if !slices.Equal(patterns, []string{"^ra", "^n"}) {
+			mt.Errorf("the candidates are not filtered by the first letters of the terms: %v", patterns)
+		}
+
+		if limit, ok := command.Lookup("limit").AsInt64OK(); !ok || limit != maxSearchCandidates {
+			mt.Errorf("the candidates are not limited: %v", command.Lookup("limit"))
+		}
+	})
+}
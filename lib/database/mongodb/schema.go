@@ -0,0 +1,39 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// uniqueString is a unique index on field, per tenant, that only applies to the documents where it
+// is a string, so that many of them can leave it unset. In databases of a single tenant the
+// documents have no tenant ID, so it is the same as a unique index on field alone.
+func uniqueString(field string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: TenantIDField, Value: 1}, {Key: field, Value: 1}},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{field: bson.M{"$type": "string"}}),
+	}
+}
+
+// DropDatabase drops the whole database or, when it is shared, deletes every document of the tenant.
+func (mdbw *MongoDbWriter) DropDatabase(ctx context.Context) error {
+	if mdbw.tenantID == nil {
+		return mdbw.DB.Drop(ctx)
+	}
+
+	names, err := mdbw.DB.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if _, err := mdbw.collection(name).DeleteMany(ctx, bson.D{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package mongodb
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TenantIDField is the field that tells, in a database shared by several tenants, which tenant
+// every document belongs to.
+const TenantIDField = "tenant_id"
+
+// scopedCollection is the only way readers and writers reach a collection. When the database is
+// shared by several tenants, every filter it is given only matches the documents of tenantID and
+// every document it stores belongs to tenantID, so that no operation can reach another tenant.
+type scopedCollection struct {
+	collection *mongo.Collection
+	tenantID   *primitive.ObjectID
+}
+
+func newScopedCollection(db *mongo.Database, name string, tenantID *primitive.ObjectID) *scopedCollection {
+	return &scopedCollection{
+		collection: db.Collection(name),
+		tenantID:   tenantID,
+	}
+}
+
+func (c *scopedCollection) filter(filter interface{}) interface{} {
+	if c.tenantID == nil {
+		return filter
+	}
+
+	return bson.D{{Key: TenantIDField, Value: *c.tenantID}, {Key: "$and", Value: bson.A{filter}}}
+}
+
+func (c *scopedCollection) document(document interface{}) (interface{}, error) {
+	if c.tenantID == nil {
+		return document, nil
+	}
+
+	// Documents may come already marshalled
+	bs, ok := document.([]byte)
+	if !ok {
+		var err error
+		if bs, err = bson.Marshal(document); err != nil {
+			return nil, err
+		}
+	}
+
+	var fields bson.D
+	if err := bson.Unmarshal(bs, &fields); err != nil {
+		return nil, err
+	}
+
+	scoped := bson.D{{Key: TenantIDField, Value: *c.tenantID}}
+	for _, field := range fields {
+		if field.Key != TenantIDField {
+			scoped = append(scoped, field)
+		}
+	}
+
+	return scoped, nil
+}
+
+func (c *scopedCollection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	return c.collection.Find(ctx, c.filter(filter), opts...)
+}
+
+func (c *scopedCollection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	return c.collection.FindOne(ctx, c.filter(filter), opts...)
+}
+
+// Aggregate runs pipeline on the documents of the tenant only.
+func (c *scopedCollection) Aggregate(ctx context.Context, pipeline mongo.Pipeline) (*mongo.Cursor, error) {
+	if c.tenantID != nil {
+		pipeline = append(mongo.Pipeline{{{Key: "$match", Value: bson.D{{Key: TenantIDField, Value: *c.tenantID}}}}}, pipeline...)
+	}
+
+	return c.collection.Aggregate(ctx, pipeline)
+}
+
+func (c *scopedCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return c.collection.CountDocuments(ctx, c.filter(filter), opts...)
+}
+
+func (c *scopedCollection) InsertOne(ctx context.Context, document interface{}) (*mongo.InsertOneResult, error) {
+	scoped, err := c.document(document)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.collection.InsertOne(ctx, scoped)
+}
+
+func (c *scopedCollection) InsertMany(ctx context.Context, documents []interface{}) (*mongo.InsertManyResult, error) {
+	scoped := make([]interface{}, 0, len(documents))
+	for _, document := range documents {
+		d, err := c.document(document)
+		if err != nil {
+			return nil, err
+		}
+		scoped = append(scoped, d)
+	}
+
+	return c.collection.InsertMany(ctx, scoped)
+}
+
+func (c *scopedCollection) ReplaceOne(ctx context.Context, filter interface{}, replacement interface{}) (*mongo.UpdateResult, error) {
+	scoped, err := c.document(replacement)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.collection.ReplaceOne(ctx, c.filter(filter), scoped)
+}
+
+func (c *scopedCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
+	return c.collection.UpdateOne(ctx, c.filter(filter), update)
+}
+
+func (c *scopedCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}) (*mongo.UpdateResult, error) {
+	return c.collection.UpdateMany(ctx, c.filter(filter), update)
+}
+
+func (c *scopedCollection) DeleteMany(ctx context.Context, filter interface{}) (*mongo.DeleteResult, error) {
+	return c.collection.DeleteMany(ctx, c.filter(filter))
+}
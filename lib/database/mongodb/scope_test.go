@@ -0,0 +1,80 @@
+package mongodb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestScopedCollection_filter(t *testing.T) {
+	tenantID := primitive.NewObjectID()
+	filter := bson.D{{Key: "email", Value: "rafa@example.com"}}
+
+	tests := []struct {
+		name     string
+		tenantID *primitive.ObjectID
+		want     interface{}
+	}{
+		{
+			name: "Unscoped_collections_keep_the_filter",
+			want: filter,
+		},
+		{
+			name:     "Scoped_collections_only_match_the_documents_of_the_tenant",
+			tenantID: &tenantID,
+			want:     bson.D{{Key: TenantIDField, Value: tenantID}, {Key: "$and", Value: bson.A{filter}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &scopedCollection{tenantID: tt.tenantID}
+			if got := c.filter(filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scopedCollection.filter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopedCollection_document(t *testing.T) {
+	tenantID := primitive.NewObjectID()
+	category := entity.NewCategory("Primera")
+
+	marshalled, err := bson.Marshal(category)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A document pretending to belong to another tenant
+	foreign, err := bson.Marshal(bson.D{{Key: "name", Value: "Segunda"}, {Key: TenantIDField, Value: primitive.NewObjectID()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, document := range map[string]interface{}{"struct": category, "marshalled": marshalled, "foreign": foreign} {
+		t.Run(name, func(t *testing.T) {
+			c := &scopedCollection{tenantID: &tenantID}
+			got, err := c.document(document)
+			if err != nil {
+				t.Fatalf("scopedCollection.document() error = %v", err)
+			}
+
+			fields := got.(bson.D)
+			count := 0
+			for _, field := range fields {
+				if field.Key == TenantIDField {
+					count++
+					if field.Value != tenantID {
+						t.Errorf("scopedCollection.document() tenant ID = %v, want %v", field.Value, tenantID)
+					}
+				}
+			}
+
+			if count != 1 {
+				t.Errorf("scopedCollection.document() has %d tenant IDs, want 1", count)
+			}
+		})
+	}
+}
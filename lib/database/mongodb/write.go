@@ -2,8 +2,10 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,11 +14,14 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MongoDbWriter struct {
 	mongodbClient *mongo.Client
 	DB            *mongo.Database
+	// tenantID is set when the database is shared by several tenants, see scopedCollection
+	tenantID *primitive.ObjectID
 }
 
 func NewMongoDbWriter(client *mongo.Client, databaseName string) *MongoDbWriter {
@@ -27,11 +32,23 @@ func NewMongoDbWriter(client *mongo.Client, databaseName string) *MongoDbWriter
 
 }
 
+// NewSharedMongoDbWriter returns a writer that only touches the documents of tenantID in a
+// database shared by several tenants.
+func NewSharedMongoDbWriter(client *mongo.Client, databaseName string, tenantID primitive.ObjectID) *MongoDbWriter {
+	writer := NewMongoDbWriter(client, databaseName)
+	writer.tenantID = &tenantID
+	return writer
+}
+
+func (mdbw *MongoDbWriter) collection(name string) *scopedCollection {
+	return newScopedCollection(mdbw.DB, name, mdbw.tenantID)
+}
+
 func (mdbw *MongoDbWriter) AddCategory(ctx context.Context, category *entity.Category) (*entity.Category, error) {
 	category.ID = primitive.NewObjectID()
 	category.CreatedAt = time.Now().UTC()
 
-	_, err := mdbw.DB.Collection("categories").InsertOne(ctx, category)
+	_, err := mdbw.collection("categories").InsertOne(ctx, category)
 	if err != nil {
 		return nil, err
 	}
@@ -39,14 +56,54 @@ func (mdbw *MongoDbWriter) AddCategory(ctx context.Context, category *entity.Cat
 }
 
 func (mdbw *MongoDbWriter) UpdateCategory(ctx context.Context, category *entity.Category) (*entity.Category, error) {
+	filter := bson.D{{Key: "_id", Value: category.ID}, notDeleted, versionFilter(category.Version)}
 	category.UpdatedAt = util.ToPtr(time.Now().UTC())
+	category.Version++
 
 	updatedCatgory, err := bson.Marshal(&category)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = mdbw.DB.Collection("categories").ReplaceOne(ctx, bson.M{"_id": category.ID}, updatedCatgory)
+	// Players and tournaments embed a full copy of their category, so they are updated
+	// together with it to avoid leaving stale names behind
+	err = mdbw.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		result, err := mdbw.collection("categories").ReplaceOne(sc, filter, updatedCatgory)
+		if err != nil {
+			return err
+		}
+
+		if result.MatchedCount == 0 {
+			return mdbw.notMatched(sc, "categories", category.ID)
+		}
+
+		return mdbw.setEmbeddedCategory(sc, bson.D{{Key: "category._id", Value: category.ID}}, category)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// PatchCategory writes patch, made by applying a merge patch to category, see util.ApplyPatchField.
+func (mdbw *MongoDbWriter) PatchCategory(ctx context.Context, category *entity.Category, patch *util.Patch) (*entity.Category, error) {
+	filter := bson.D{{Key: "_id", Value: category.ID}, notDeleted, versionFilter(category.Version)}
+	category.UpdatedAt = util.ToPtr(time.Now().UTC())
+	category.Version++
+
+	err := mdbw.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		result, err := mdbw.collection("categories").UpdateOne(sc, filter, patchUpdate(patch, bson.D{{Key: "updated_at", Value: category.UpdatedAt}}))
+		if err != nil {
+			return err
+		}
+
+		if result.MatchedCount == 0 {
+			return mdbw.notMatched(sc, "categories", category.ID)
+		}
+
+		return mdbw.setEmbeddedCategory(sc, bson.D{{Key: "category._id", Value: category.ID}}, category)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -54,24 +111,96 @@ func (mdbw *MongoDbWriter) UpdateCategory(ctx context.Context, category *entity.
 	return category, nil
 }
 
-func (mdbw *MongoDbWriter) DeleteCategory(ctx context.Context, id string) error {
+func (mdbw *MongoDbWriter) DeleteCategory(ctx context.Context, id string, version *int64, policy entity.CategoryDeletePolicy, replacement *entity.Category, deletedBy string) error {
 	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	_, err = mdbw.DB.Collection("categories").DeleteOne(ctx, bson.D{{Key: "_id", Value: _id}})
+	referencesFilter := bson.D{{Key: "category._id", Value: _id}}
+
+	// The category is deleted first, so that it stays locked by the transaction while its
+	// references are checked or moved, and the whole delete is rolled back when they fail
+	return mdbw.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		if err := mdbw.softDelete(sc, "categories", _id, version, deletedBy); err != nil {
+			return err
+		}
+
+		switch policy {
+		case entity.CategoryDeletePolicyCascade:
+			return mdbw.setEmbeddedCategory(sc, referencesFilter, nil)
+		case entity.CategoryDeletePolicyReassign:
+			return mdbw.setEmbeddedCategory(sc, referencesFilter, replacement)
+		default:
+			return mdbw.checkCategoryIsNotInUse(sc, referencesFilter)
+		}
+	})
+}
+
+// checkCategoryIsNotInUse fails with util.ErrCategoryIsInUse when any not deleted player or editable
+// tournament matching referencesFilter has the category. Deleted players and tournaments that can no
+// longer be edited keep it, as they do when it is deleted in cascade.
+func (mdbw *MongoDbWriter) checkCategoryIsNotInUse(ctx context.Context, referencesFilter bson.D) error {
+	filters := map[string]bson.D{
+		"players":     append(bson.D{notDeleted}, referencesFilter...),
+		"tournaments": append(bson.D{notDeleted, editableTournament()}, referencesFilter...),
+	}
+
+	for _, collection := range []string{"players", "tournaments"} {
+		count, err := mdbw.collection(collection).CountDocuments(ctx, filters[collection], options.Count().SetLimit(1))
+		if err != nil {
+			return err
+		}
+
+		if count > 0 {
+			return util.ErrCategoryIsInUse
+		}
+	}
+
+	return nil
+}
+
+func (mdbw *MongoDbWriter) RestoreCategory(ctx context.Context, id string) error {
+	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return mdbw.restore(ctx, "categories", _id)
+}
+
+// setEmbeddedCategory replaces the category embedded in every player and editable tournament
+// matching filter. Tournaments that can no longer be edited keep the category they were played in.
+func (mdbw *MongoDbWriter) setEmbeddedCategory(ctx context.Context, filter bson.D, category *entity.Category) error {
+	update := bson.D{
+		{Key: "$set", Value: bson.D{{Key: "category", Value: category}}},
+		{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}},
+	}
+
+	if _, err := mdbw.collection("players").UpdateMany(ctx, filter, update); err != nil {
+		return err
+	}
+
+	_, err := mdbw.collection("tournaments").UpdateMany(ctx, append(bson.D{editableTournament()}, filter...), update)
+	return err
+}
+
+// editableTournament matches the tournaments that entity.Tournament.IsEditable, including the ones
+// stored before statuses were introduced, which have none.
+func editableTournament() bson.E {
+	statuses := bson.A{nil, ""}
+	for _, status := range entity.EditableTournamentStatuses {
+		statuses = append(statuses, status)
+	}
+
+	return bson.E{Key: "status", Value: bson.D{{Key: "$in", Value: statuses}}}
 }
 
 func (mdbw *MongoDbWriter) AddPlayer(ctx context.Context, player *entity.Player) (*entity.Player, error) {
 	player.ID = primitive.NewObjectID()
 	player.CreatedAt = time.Now().UTC()
 	player.TemporaryAccessCode = fmt.Sprintf("%v", rand.Uint32())
+	player.SearchTerms = playerSearchTerms(player)
 
 	session, err := mdbw.DB.Client().StartSession()
 	if err != nil {
@@ -84,21 +213,14 @@ func (mdbw *MongoDbWriter) AddPlayer(ctx context.Context, player *entity.Player)
 			return err
 		}
 
-		if _, err := mdbw.DB.Collection("players").InsertOne(sc, player); err != nil {
+		if _, err := mdbw.collection("players").InsertOne(sc, player); err != nil {
 			if err := session.AbortTransaction(sc); err != nil {
 				return err
 			}
 			return err
 		}
 
-		if _, err := mdbw.DB.Collection("users").InsertOne(sc, &entity.User{
-			ID:                  player.ID,
-			GovernmentID:        player.GovernmentID,
-			Email:               player.Email,
-			Alias:               player.Alias,
-			TemporaryAccessCode: player.TemporaryAccessCode,
-			CreatedAt:           player.CreatedAt,
-		}); err != nil {
+		if _, err := mdbw.collection("users").InsertOne(sc, playerUser(player)); err != nil {
 			if err := session.AbortTransaction(sc); err != nil {
 				return err
 			}
@@ -120,55 +242,204 @@ func (mdbw *MongoDbWriter) AddPlayer(ctx context.Context, player *entity.Player)
 	return player, nil
 }
 
+// AddPlayers works like AddPlayer for several players at once. Either all of them are added or,
+// when any fails, none.
+func (mdbw *MongoDbWriter) AddPlayers(ctx context.Context, players []*entity.Player) ([]*entity.Player, error) {
+	newPlayers := make([]interface{}, 0, len(players))
+	newUsers := make([]interface{}, 0, len(players))
+	for _, player := range players {
+		player.ID = primitive.NewObjectID()
+		player.CreatedAt = time.Now().UTC()
+		player.TemporaryAccessCode = fmt.Sprintf("%v", rand.Uint32())
+		player.SearchTerms = playerSearchTerms(player)
+
+		newPlayers = append(newPlayers, player)
+		newUsers = append(newUsers, playerUser(player))
+	}
+
+	err := mdbw.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		if _, err := mdbw.collection("players").InsertMany(sc, newPlayers); err != nil {
+			return err
+		}
+
+		_, err := mdbw.collection("users").InsertMany(sc, newUsers)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return players, nil
+}
+
 func (mdbw *MongoDbWriter) UpdatePlayer(ctx context.Context, player *entity.Player) (*entity.Player, error) {
+	filter := bson.D{{Key: "_id", Value: player.ID}, notDeleted, versionFilter(player.Version)}
 	player.UpdatedAt = util.ToPtr(time.Now().UTC())
+	player.SearchTerms = playerSearchTerms(player)
+	player.Version++
 
 	updatedPlayer, err := bson.Marshal(&player)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = mdbw.DB.Collection("players").ReplaceOne(ctx, bson.M{"_id": player.ID}, updatedPlayer)
+	result, err := mdbw.collection("players").ReplaceOne(ctx, filter, updatedPlayer)
 	if err != nil {
-		if e, ok := err.(mongo.WriteException); ok {
-			for _, ee := range e.WriteErrors {
-				if strings.Contains(ee.Message, "government_id_1") {
-					return nil, &util.AppError{Message: "government_id has already been assigned to another player"}
-				}
+		return nil, playerWriteError(err)
+	}
 
-				if strings.Contains(ee.Message, "email_1") {
-					return nil, &util.AppError{Message: "email has already been assigned to another player"}
-				}
+	if result.MatchedCount == 0 {
+		return nil, mdbw.notMatched(ctx, "players", player.ID)
+	}
 
-				if strings.Contains(ee.Message, "alias_1") {
-					return nil, &util.AppError{Message: "alias has already been assigned to another player"}
-				}
-			}
-		}
+	return player, nil
+}
 
-		return nil, err
+// PatchPlayer writes patch, made by applying a merge patch to player, see util.ApplyPatchField.
+func (mdbw *MongoDbWriter) PatchPlayer(ctx context.Context, player *entity.Player, patch *util.Patch) (*entity.Player, error) {
+	filter := bson.D{{Key: "_id", Value: player.ID}, notDeleted, versionFilter(player.Version)}
+	player.UpdatedAt = util.ToPtr(time.Now().UTC())
+	player.SearchTerms = playerSearchTerms(player)
+	player.Version++
+
+	result, err := mdbw.collection("players").UpdateOne(ctx, filter, patchUpdate(patch, bson.D{
+		{Key: "updated_at", Value: player.UpdatedAt},
+		{Key: "search_terms", Value: player.SearchTerms},
+	}))
+	if err != nil {
+		return nil, playerWriteError(err)
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, mdbw.notMatched(ctx, "players", player.ID)
 	}
 
 	return player, nil
 }
 
-func (mdbw *MongoDbWriter) DeletePlayer(ctx context.Context, id string) error {
+// playerWriteError translates the errors of the unique indexes of players.
+func playerWriteError(err error) error {
+	if e, ok := err.(mongo.WriteException); ok {
+		for _, ee := range e.WriteErrors {
+			if strings.Contains(ee.Message, "government_id_1") {
+				return util.ErrPlayerGovernmentIDIsTaken
+			}
+
+			if strings.Contains(ee.Message, "email_1") {
+				return util.ErrPlayerEmailIsTaken
+			}
+
+			if strings.Contains(ee.Message, "alias_1") {
+				return util.ErrPlayerAliasIsTaken
+			}
+		}
+	}
+
+	return err
+}
+
+func (mdbw *MongoDbWriter) DeletePlayer(ctx context.Context, id string, version *int64, deletedBy string) error {
 	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	_, err = mdbw.DB.Collection("players").DeleteOne(ctx, bson.D{{Key: "_id", Value: _id}})
+	// Every player has a paired user (see AddPlayer) that must not outlive it
+	return mdbw.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		if err := mdbw.softDelete(sc, "players", _id, version, deletedBy); err != nil {
+			return err
+		}
+
+		if err := mdbw.softDelete(sc, "users", _id, nil, deletedBy); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (mdbw *MongoDbWriter) RestorePlayer(ctx context.Context, id string) error {
+	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// Another player may have taken the government ID, email or alias of the player meanwhile
+	return mdbw.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		if err := mdbw.restore(sc, "players", _id); err != nil {
+			return playerWriteError(err)
+		}
+
+		if err := mdbw.restore(sc, "users", _id); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return playerWriteError(err)
+		}
+
+		return nil
+	})
+}
+
+// MergePlayers stores survivor, deletes the duplicate player of merge together with its user and
+// records merge as the audit trail of the operation.
+func (mdbw *MongoDbWriter) MergePlayers(ctx context.Context, survivor *entity.Player, merge *entity.PlayerMerge) (*entity.Player, error) {
+	filter := bson.D{{Key: "_id", Value: survivor.ID}, notDeleted, versionFilter(survivor.Version)}
+	survivor.UpdatedAt = util.ToPtr(time.Now().UTC())
+	survivor.SearchTerms = playerSearchTerms(survivor)
+	survivor.Version++
+
+	merge.ID = primitive.NewObjectID()
+	merge.SurvivorID = survivor.ID
+	merge.MergedAt = time.Now().UTC()
+
+	var mergedBy string
+	if merge.MergedBy != nil {
+		mergedBy = *merge.MergedBy
+	}
+
+	err := mdbw.withTransaction(ctx, func(sc mongo.SessionContext) error {
+		result, err := mdbw.collection("players").ReplaceOne(sc, filter, survivor)
+		if err != nil {
+			return err
+		}
+
+		if result.MatchedCount == 0 {
+			return mdbw.notMatched(sc, "players", survivor.ID)
+		}
+
+		if err := mdbw.softDelete(sc, "players", merge.Duplicate.ID, nil, mergedBy); err != nil {
+			return err
+		}
+
+		if err := mdbw.softDelete(sc, "users", merge.Duplicate.ID, nil, mergedBy); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+
+		_, err = mdbw.collection("player_merges").InsertOne(sc, merge)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return survivor, nil
+}
+
+func (mdbw *MongoDbWriter) AddUser(ctx context.Context, user *entity.User) (*entity.User, error) {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+	user.CreatedAt = time.Now().UTC()
+
+	_, err := mdbw.collection("users").InsertOne(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
 }
 
 func (mdbw *MongoDbWriter) AddTournament(ctx context.Context, tournament *entity.Tournament) (*entity.Tournament, error) {
 	tournament.ID = primitive.NewObjectID()
-	_, err := mdbw.DB.Collection("tournaments").InsertOne(ctx, tournament)
+	_, err := mdbw.collection("tournaments").InsertOne(ctx, tournament)
 	if err != nil {
 		return nil, err
 	}
@@ -177,55 +448,544 @@ func (mdbw *MongoDbWriter) AddTournament(ctx context.Context, tournament *entity
 }
 
 func (mdbw *MongoDbWriter) UpdateTournament(ctx context.Context, tournament *entity.Tournament) (*entity.Tournament, error) {
+	filter := bson.D{{Key: "_id", Value: tournament.ID}, notDeleted, versionFilter(tournament.Version)}
+	tournament.Version++
+
 	updatedTournament, err := bson.Marshal(&tournament)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = mdbw.DB.Collection("tournaments").ReplaceOne(ctx, bson.M{"_id": tournament.ID}, updatedTournament)
+	result, err := mdbw.collection("tournaments").ReplaceOne(ctx, filter, updatedTournament)
 	if err != nil {
 		return nil, err
 	}
 
+	if result.MatchedCount == 0 {
+		return nil, mdbw.notMatched(ctx, "tournaments", tournament.ID)
+	}
+
+	return tournament, nil
+}
+
+// PatchTournament writes patch, made by applying a merge patch to tournament, see util.ApplyPatchField.
+func (mdbw *MongoDbWriter) PatchTournament(ctx context.Context, tournament *entity.Tournament, patch *util.Patch) (*entity.Tournament, error) {
+	filter := bson.D{{Key: "_id", Value: tournament.ID}, notDeleted, versionFilter(tournament.Version)}
+	tournament.Version++
+
+	result, err := mdbw.collection("tournaments").UpdateOne(ctx, filter, patchUpdate(patch, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, mdbw.notMatched(ctx, "tournaments", tournament.ID)
+	}
+
 	return tournament, nil
 }
 
-func (mdbw *MongoDbWriter) DeleteTournament(ctx context.Context, id string) error {
+func (mdbw *MongoDbWriter) DeleteTournament(ctx context.Context, id string, version *int64, deletedBy string) error {
 	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	_, err = mdbw.DB.Collection("tournaments").DeleteOne(ctx, bson.D{{Key: "_id", Value: _id}})
+	return mdbw.softDelete(ctx, "tournaments", _id, version, deletedBy)
+}
+
+func (mdbw *MongoDbWriter) RestoreTournament(ctx context.Context, id string) error {
+	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return mdbw.restore(ctx, "tournaments", _id)
 }
 
 func (mdbw *MongoDbWriter) AddTenant(ctx context.Context, tenant *entity.Tenant) (*entity.Tenant, error) {
-	tenant.ID = primitive.NewObjectID()
+	if tenant.ID.IsZero() {
+		tenant.ID = primitive.NewObjectID()
+	}
 	tenant.CreatedAt = time.Now().UTC()
+	tenant.VerifiedDomains = tenant.VerifiedDomainNames()
+	tenant.DedicatedDatabase = tenant.DedicatedDatabaseName()
+
+	_, err := mdbw.collection("tenants").InsertOne(ctx, tenant)
+	if err != nil {
+		return nil, tenantWriteError(err)
+	}
+
+	return tenant, nil
+}
 
-	_, err := mdbw.DB.Collection("tenants").InsertOne(ctx, tenant)
+// UpdateTenant replaces tenant as long as it has not been written since it was read, which is told
+// by its UpdatedAt, and fails with util.ErrTenantIsModified otherwise.
+func (mdbw *MongoDbWriter) UpdateTenant(ctx context.Context, tenant *entity.Tenant) (*entity.Tenant, error) {
+	readUpdatedAt := tenant.UpdatedAt
+	updated := *tenant
+	// MongoDB keeps milliseconds only, and the next write has to match it
+	updated.UpdatedAt = util.ToPtr(time.Now().UTC().Truncate(time.Millisecond))
+	updated.VerifiedDomains = updated.VerifiedDomainNames()
+	updated.DedicatedDatabase = updated.DedicatedDatabaseName()
+
+	document, err := bson.Marshal(&updated)
 	if err != nil {
 		return nil, err
 	}
 
+	result, err := mdbw.collection("tenants").ReplaceOne(ctx, bson.D{
+		{Key: "_id", Value: tenant.ID},
+		notDeleted,
+		{Key: "updated_at", Value: readUpdatedAt},
+	}, document)
+	if err != nil {
+		return nil, tenantWriteError(err)
+	}
+
+	if result.MatchedCount == 0 {
+		if err := mdbw.notMatched(ctx, "tenants", tenant.ID); !errors.Is(err, util.ErrPreconditionFailed) {
+			return nil, err
+		}
+
+		return nil, util.ErrTenantIsModified
+	}
+
+	*tenant = updated
 	return tenant, nil
 }
 
-func (mdbw *MongoDbWriter) DeleteTenant(ctx context.Context, id string) error {
+func tenantWriteError(err error) error {
+	if e, ok := err.(mongo.WriteException); ok {
+		for _, ee := range e.WriteErrors {
+			if strings.Contains(ee.Message, "slug_1") {
+				return util.ErrTenantSlugIsTaken
+			}
+
+			if strings.Contains(ee.Message, "verified_domains_1") {
+				return util.ErrTenantCustomDomainIsTaken
+			}
+
+			if strings.Contains(ee.Message, "dedicated_database_1") {
+				return util.ErrTenantDatabaseNameIsTaken
+			}
+		}
+	}
+
+	return err
+}
+
+func (mdbw *MongoDbWriter) DeleteTenant(ctx context.Context, id string, deletedBy string) error {
+	_id, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	return mdbw.softDelete(ctx, "tenants", _id, nil, deletedBy)
+}
+
+func (mdbw *MongoDbWriter) RestoreTenant(ctx context.Context, id string) error {
 	_id, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	_, err = mdbw.DB.Collection("tenants").DeleteOne(ctx, bson.D{{Key: "_id", Value: _id}})
+	return mdbw.restore(ctx, "tenants", _id)
+}
+
+// RewriteTenantConnectionStrings replaces the connection string of every tenant, deleted ones
+// included, with the one returned by rewrite for its ID. A tenant whose connection string changes
+// while it is rewritten is left as it is and its ID is returned among the skipped ones. It returns
+// how many tenants were rewritten before the first error.
+func (mdbw *MongoDbWriter) RewriteTenantConnectionStrings(ctx context.Context, rewrite func(id string, connectionString string) (string, error)) (int, []string, error) {
+	tenants := mdbw.collection("tenants")
+
+	cursor, err := tenants.Find(ctx, bson.D{}, options.Find().SetProjection(bson.D{{Key: "mongo_db_connection_string", Value: 1}}))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rewritten := 0
+	skipped := make([]string, 0)
+	for cursor.Next(ctx) {
+		var tenant entity.Tenant
+		if err := cursor.Decode(&tenant); err != nil {
+			return rewritten, skipped, err
+		}
+
+		connectionString, err := rewrite(tenant.ID.Hex(), tenant.MongoDBConnectionString)
+		if err != nil {
+			return rewritten, skipped, fmt.Errorf("error when rewriting connection string of tenant '%s': %w", tenant.ID.Hex(), err)
+		}
+
+		result, err := tenants.UpdateOne(ctx, bson.D{
+			{Key: "_id", Value: tenant.ID},
+			{Key: "mongo_db_connection_string", Value: tenant.MongoDBConnectionString},
+		}, bson.D{
+			{Key: "$set", Value: bson.D{
+				{Key: "mongo_db_connection_string", Value: connectionString},
+				// so that tenants read before are not written back with the previous connection string
+				{Key: "updated_at", Value: time.Now().UTC().Truncate(time.Millisecond)},
+			}},
+		})
+		if err != nil {
+			return rewritten, skipped, err
+		}
+
+		if result.MatchedCount == 0 {
+			skipped = append(skipped, tenant.ID.Hex())
+			continue
+		}
+
+		rewritten++
+	}
+
+	return rewritten, skipped, cursor.Err()
+}
+
+const (
+	tenantDatabaseReservationsCollection = "tenant_database_reservations"
+	tenantDatabaseReservationTTL         = time.Hour
+)
+
+// IndexTenants fills the slug and the verified domains of the tenants stored before they were
+// introduced, and then creates the indexes that keep them unique. Slugs are made from the names of
+// the tenants, numbered when they clash, and a domain verified by several tenants is only kept for
+// the oldest one. Deleted tenants keep theirs until they are purged.
+func (mdbw *MongoDbWriter) IndexTenants(ctx context.Context) error {
+	tenants := mdbw.collection("tenants")
+
+	cursor, err := tenants.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return err
+	}
+
+	var all []entity.Tenant
+	if err := cursor.All(ctx, &all); err != nil {
+		return err
+	}
+
+	slugs := make(map[string]bool)
+	domains := make(map[string]bool)
+	for _, tenant := range all {
+		slugs[tenant.Slug] = tenant.Slug != ""
+		for _, domain := range tenant.VerifiedDomains {
+			domains[domain] = true
+		}
+	}
+
+	for _, tenant := range all {
+		set := bson.D{}
+
+		if tenant.Slug == "" {
+			base := util.Slug(tenant.Name)
+			if base == "" {
+				base = "tenant"
+			}
+
+			slug := base
+			for i := 2; slugs[slug]; i++ {
+				suffix := fmt.Sprintf("-%d", i)
+				slug = strings.TrimRight(base[:min(len(base), 63-len(suffix))], "-") + suffix
+			}
+
+			slugs[slug] = true
+			set = append(set, bson.E{Key: "slug", Value: slug})
+		}
+
+		if tenant.VerifiedDomains == nil {
+			var verified []string
+			for _, domain := range tenant.VerifiedDomainNames() {
+				if !domains[domain] {
+					domains[domain] = true
+					verified = append(verified, domain)
+				}
+			}
+
+			if verified != nil {
+				set = append(set, bson.E{Key: "verified_domains", Value: verified})
+			}
+		}
+
+		if tenant.DedicatedDatabase == "" && tenant.DedicatedDatabaseName() != "" {
+			set = append(set, bson.E{Key: "dedicated_database", Value: tenant.DedicatedDatabaseName()})
+		}
+
+		if len(set) == 0 {
+			continue
+		}
+
+		if _, err := tenants.UpdateOne(ctx, bson.D{{Key: "_id", Value: tenant.ID}}, bson.D{{Key: "$set", Value: set}}); err != nil {
+			return err
+		}
+	}
+
+	_, err = mdbw.DB.Collection("tenants").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		uniqueString("slug"),
+		{
+			Keys:    bson.D{{Key: "verified_domains", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"verified_domains": bson.M{"$exists": true}}),
+		},
+		{
+			Keys:    bson.D{{Key: "dedicated_database", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"dedicated_database": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = mdbw.DB.Collection(tenantDatabaseReservationsCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	return err
+}
+
+// ReserveTenantDatabase holds databaseName while a tenant is provisioned in it, so that no other
+// tenant is provisioned in it meanwhile, and returns false when it is held already. Reservations
+// expire after tenantDatabaseReservationTTL, in case they are never released.
+func (mdbw *MongoDbWriter) ReserveTenantDatabase(ctx context.Context, databaseName string) (bool, error) {
+	reservations := mdbw.collection(tenantDatabaseReservationsCollection)
+	now := time.Now().UTC()
+
+	// Expired reservations are only removed by MongoDB once a minute
+	_, err := reservations.DeleteMany(ctx, bson.D{
+		{Key: "_id", Value: databaseName},
+		{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	_, err = reservations.InsertOne(ctx, bson.D{
+		{Key: "_id", Value: databaseName},
+		{Key: "expires_at", Value: now.Add(tenantDatabaseReservationTTL)},
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ReleaseTenantDatabase removes the reservation of databaseName made by ReserveTenantDatabase.
+func (mdbw *MongoDbWriter) ReleaseTenantDatabase(ctx context.Context, databaseName string) error {
+	_, err := mdbw.collection(tenantDatabaseReservationsCollection).DeleteMany(ctx, bson.D{{Key: "_id", Value: databaseName}})
+	return err
+}
+
+// IndexPlayersForSearch fills the search terms of the players stored before they were introduced.
+// Their index is created by the migrations.
+func (mdbw *MongoDbWriter) IndexPlayersForSearch(ctx context.Context) error {
+	players := mdbw.collection("players")
+
+	cursor, err := players.Find(ctx, bson.D{{Key: "search_terms", Value: bson.D{{Key: "$exists", Value: false}}}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var player entity.Player
+		if err := cursor.Decode(&player); err != nil {
+			return err
+		}
+
+		_, err := players.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: player.ID}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "search_terms", Value: playerSearchTerms(&player)}}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+func (mdbw *MongoDbWriter) PurgeDeleted(ctx context.Context, collection string, deletedBefore time.Time) (int64, error) {
+	result, err := mdbw.collection(collection).DeleteMany(ctx, bson.D{{Key: "deleted_at", Value: bson.D{{Key: "$lt", Value: deletedBefore}}}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// softDelete flags a document as deleted so that readers stop returning it. It will be permanently
+// deleted by PurgeDeleted once its retention period is over. When version is set, the document is
+// only deleted if it still has that version.
+func (mdbw *MongoDbWriter) softDelete(ctx context.Context, collection string, _id primitive.ObjectID, version *int64, deletedBy string) error {
+	var deletedByPtr *string
+	if deletedBy != "" {
+		deletedByPtr = util.ToPtr(deletedBy)
+	}
+
+	filter := bson.D{{Key: "_id", Value: _id}, {Key: "deleted_at", Value: nil}}
+	if version != nil {
+		filter = append(filter, versionFilter(*version))
+	}
+
+	result, err := mdbw.collection(collection).UpdateOne(ctx, filter, versionedUpdate(collection, bson.D{
+		{Key: "deleted_at", Value: time.Now().UTC()},
+		{Key: "deleted_by", Value: deletedByPtr},
+	}))
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		if version != nil {
+			return mdbw.notMatched(ctx, collection, _id)
+		}
+
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (mdbw *MongoDbWriter) restore(ctx context.Context, collection string, _id primitive.ObjectID) error {
+	result, err := mdbw.collection(collection).UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: _id}, {Key: "deleted_at", Value: bson.D{{Key: "$ne", Value: nil}}}},
+		versionedUpdate(collection, bson.D{
+			{Key: "deleted_at", Value: nil},
+			{Key: "deleted_by", Value: nil},
+		}),
+	)
 	if err != nil {
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
 	return nil
 }
+
+// versionedCollections are the collections whose documents have a version, which every write
+// increases so that clients can update them conditionally, see lib/etag.
+var versionedCollections = map[string]bool{
+	"categories":  true,
+	"players":     true,
+	"tournaments": true,
+}
+
+// versionFilter matches the documents with the given version. Documents stored before versions were
+// introduced have none, which is version 0.
+func versionFilter(version int64) bson.E {
+	if version == 0 {
+		return bson.E{Key: "version", Value: bson.D{{Key: "$in", Value: bson.A{int64(0), nil}}}}
+	}
+
+	return bson.E{Key: "version", Value: version}
+}
+
+// versionedUpdate sets fields, increasing the version of the document when collection has them.
+func versionedUpdate(collection string, fields bson.D) bson.D {
+	update := bson.D{{Key: "$set", Value: fields}}
+	if versionedCollections[collection] {
+		update = append(update, bson.E{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}})
+	}
+
+	return update
+}
+
+// patchUpdate returns the update that writes patch together with fields, the ones the writer keeps
+// up to date by itself, and increases the version of the document.
+func patchUpdate(patch *util.Patch, fields bson.D) bson.D {
+	names := make([]string, 0, len(patch.Set))
+	for name := range patch.Set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	set := make(bson.D, 0, len(patch.Set)+len(fields))
+	for _, name := range names {
+		set = append(set, bson.E{Key: name, Value: patch.Set[name]})
+	}
+	set = append(set, fields...)
+
+	update := bson.D{{Key: "$inc", Value: bson.D{{Key: "version", Value: 1}}}}
+	if len(set) > 0 {
+		update = append(update, bson.E{Key: "$set", Value: set})
+	}
+
+	if len(patch.Unset) > 0 {
+		unset := make(bson.D, 0, len(patch.Unset))
+		for _, name := range patch.Unset {
+			unset = append(unset, bson.E{Key: name, Value: ""})
+		}
+		update = append(update, bson.E{Key: "$unset", Value: unset})
+	}
+
+	return update
+}
+
+// notMatched tells why a conditional write of the document _id matched nothing: either it has been
+// written in between, so its version is another, or it does not exist.
+func (mdbw *MongoDbWriter) notMatched(ctx context.Context, collection string, _id primitive.ObjectID) error {
+	count, err := mdbw.collection(collection).CountDocuments(ctx, bson.D{{Key: "_id", Value: _id}, notDeleted}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return util.ErrPreconditionFailed
+}
+
+// withTransaction runs fn inside a transaction, committing it when fn succeeds and aborting it otherwise.
+func (mdbw *MongoDbWriter) withTransaction(ctx context.Context, fn func(sc mongo.SessionContext) error) error {
+	session, err := mdbw.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	return mongo.WithSession(ctx, session, func(sc mongo.SessionContext) error {
+		if err := session.StartTransaction(); err != nil {
+			return err
+		}
+
+		if err := fn(sc); err != nil {
+			if abortErr := session.AbortTransaction(sc); abortErr != nil {
+				return abortErr
+			}
+			return err
+		}
+
+		return session.CommitTransaction(sc)
+	})
+}
+
+func playerSearchTerms(player *entity.Player) []string {
+	var alias string
+	if player.Alias != nil {
+		alias = *player.Alias
+	}
+
+	return util.SearchTerms(player.FirstName, player.MiddleName, player.LastName, alias, player.Email)
+}
+
+// playerUser returns the user that allows player to log in.
+func playerUser(player *entity.Player) *entity.User {
+	return &entity.User{
+		ID:                  player.ID,
+		GovernmentID:        player.GovernmentID,
+		Email:               player.Email,
+		Alias:               player.Alias,
+		TemporaryAccessCode: player.TemporaryAccessCode,
+		Role:                entity.UserRolePlayer,
+		CreatedAt:           player.CreatedAt,
+	}
+}
@@ -0,0 +1,100 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/entity"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoDbWriter_UpdateCategory(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Renames_the_category_embedded_in_players_and_editable_tournaments", func(mt *mtest.T) {
+		category := entity.NewCategory("Primera")
+		category.Name = "Primera A"
+
+		// categories, players and tournaments are updated, and then the transaction is committed
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+			mtest.CreateSuccessResponse(),
+		)
+
+		writer := NewMongoDbWriter(mt.Client, "tenant")
+		if _, err := writer.UpdateCategory(context.Background(), category); err != nil {
+			mt.Fatal(err)
+		}
+
+		updates := make(map[string]bson.Raw)
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName == "update" {
+				updates[event.Command.Lookup("update").StringValue()] = event.Command.Lookup("updates").Array().Index(0).Value().Document()
+			}
+		}
+
+		for _, collection := range []string{"players", "tournaments"} {
+			update, ok := updates[collection]
+			if !ok {
+				mt.Fatalf("%s were not updated", collection)
+			}
+
+			if got := update.Lookup("u", "$set", "category", "name").StringValue(); got != "Primera A" {
+				mt.Errorf("category of %s = %v, want %v", collection, got, "Primera A")
+			}
+
+			if !update.Lookup("multi").Boolean() {
+				mt.Errorf("only one of the %s was updated", collection)
+			}
+		}
+
+		if deletedAt, err := updates["categories"].LookupErr("q", "deleted_at"); err != nil || deletedAt.Type != bson.TypeNull {
+			mt.Errorf("deleted categories can be updated: %v", updates["categories"].Lookup("q"))
+		}
+
+		if _, err := updates["tournaments"].LookupErr("q", "status", "$in"); err != nil {
+			mt.Errorf("tournaments that can no longer be edited were updated too: %v", updates["tournaments"].Lookup("q"))
+		}
+	})
+}
+
+func TestMongoDbWriter_DeleteCategory(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Blocks_only_on_active_players_and_editable_tournaments", func(mt *mtest.T) {
+		category := entity.NewCategory("Primera")
+
+		// the category is deleted, players and tournaments are counted, and then the transaction is committed
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}},
+			mtest.CreateCursorResponse(0, "tenant.players", mtest.FirstBatch),
+			mtest.CreateCursorResponse(0, "tenant.tournaments", mtest.FirstBatch),
+			mtest.CreateSuccessResponse(),
+		)
+
+		writer := NewMongoDbWriter(mt.Client, "tenant")
+		if err := writer.DeleteCategory(context.Background(), category.ID.Hex(), nil, entity.CategoryDeletePolicyBlock, nil, "admin"); err != nil {
+			mt.Fatal(err)
+		}
+
+		matches := make(map[string]bson.Raw)
+		for _, event := range mt.GetAllStartedEvents() {
+			if event.CommandName == "aggregate" {
+				matches[event.Command.Lookup("aggregate").StringValue()] = event.Command.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match").Document()
+			}
+		}
+
+		for _, collection := range []string{"players", "tournaments"} {
+			if deletedAt, err := matches[collection].LookupErr("deleted_at"); err != nil || deletedAt.Type != bson.TypeNull {
+				mt.Errorf("deleted %s block the delete: %v", collection, matches[collection])
+			}
+		}
+
+		if _, err := matches["tournaments"].LookupErr("status", "$in"); err != nil {
+			mt.Errorf("tournaments that can no longer be edited block the delete: %v", matches["tournaments"])
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package query
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultLimit int64 = 50
+	MaxLimit     int64 = 200
+)
+
+type FieldType int
+
+const (
+	String FieldType = iota
+	ObjectID
+)
+
+// Field describes a field of a collection that can be used to filter or sort a list.
+type Field struct {
+	// Path is the path of the field in the stored document, e.g. "category._id"
+	Path string
+	Type FieldType
+}
+
+// Schema lists, by query parameter name, the fields a list endpoint can be filtered and sorted by.
+type Schema struct {
+	Filters map[string]Field
+	Sorts   map[string]Field
+}
+
+type Filter struct {
+	Path  string
+	Value interface{}
+}
+
+type Query struct {
+	Filters []Filter
+	// SortBy is the path of the field to sort by. Results are always sorted by _id as well so
+	// that every item has a stable position to resume from.
+	SortBy     string
+	Descending bool
+	Limit      int64
+	Cursor     string
+}
+
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// Parse builds a Query out of the query parameters of a list request:
+//
+//	?first_name=Rafael&sort=-created_at&limit=20&cursor=...
+//
+// Only the fields declared in schema are accepted.
+func Parse(values url.Values, schema Schema) (*Query, error) {
+	q := &Query{
+		Limit: DefaultLimit,
+	}
+
+	for name, vs := range values {
+		switch name {
+		case "limit":
+			limit, err := strconv.ParseInt(values.Get(name), 10, 64)
+			if err != nil || limit <= 0 || limit > MaxLimit {
+				return nil, util.ErrQueryInvalidLimit
+			}
+			q.Limit = limit
+		case "cursor":
+			q.Cursor = values.Get(name)
+		case "sort":
+			sort := values.Get(name)
+			q.Descending = strings.HasPrefix(sort, "-")
+			field, ok := schema.Sorts[strings.TrimPrefix(sort, "-")]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", util.ErrQueryUnknownSort, sort)
+			}
+			q.SortBy = field.Path
+		default:
+			field, ok := schema.Filters[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", util.ErrQueryUnknownFilter, name)
+			}
+
+			value, err := field.parse(vs[0])
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", util.ErrQueryInvalidFilterValue, name)
+			}
+
+			q.Filters = append(q.Filters, Filter{Path: field.Path, Value: value})
+		}
+	}
+
+	return q, nil
+}
+
+func (f Field) parse(value string) (interface{}, error) {
+	switch f.Type {
+	case ObjectID:
+		return primitive.ObjectIDFromHex(value)
+	default:
+		return value, nil
+	}
+}
+
+type cursor struct {
+	SortBy     string             `bson:"sort_by"`
+	Descending bool               `bson:"descending"`
+	SortValue  bson.RawValue      `bson:"sort_value"`
+	ID         primitive.ObjectID `bson:"id"`
+}
+
+// cursorSortValueTypes are the types of the sort values a cursor can hold: scalars only, which are
+// compared as they are rather than run as a query.
+var cursorSortValueTypes = map[bsontype.Type]bool{
+	bson.TypeString:     true,
+	bson.TypeInt32:      true,
+	bson.TypeInt64:      true,
+	bson.TypeDouble:     true,
+	bson.TypeDecimal128: true,
+	bson.TypeDateTime:   true,
+	bson.TypeTimestamp:  true,
+	bson.TypeBoolean:    true,
+	bson.TypeObjectID:   true,
+	bson.TypeNull:       true,
+}
+
+// EncodeCursor returns an opaque cursor pointing right after the document with the given id and
+// value of the sort field, in the given sort direction.
+func EncodeCursor(sortBy string, descending bool, sortValue bson.RawValue, id primitive.ObjectID) (string, error) {
+	bs, err := bson.Marshal(&cursor{SortBy: sortBy, Descending: descending, SortValue: sortValue, ID: id})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bs), nil
+}
+
+// DecodeCursor is the opposite of EncodeCursor. It fails if the cursor was issued for a different
+// sort field or direction, or if its sort value is not a scalar.
+func DecodeCursor(value string, sortBy string, descending bool) (bson.RawValue, primitive.ObjectID, error) {
+	bs, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return bson.RawValue{}, primitive.NilObjectID, util.ErrQueryInvalidCursor
+	}
+
+	var c cursor
+	if err := bson.Unmarshal(bs, &c); err != nil || c.SortBy != sortBy || c.Descending != descending {
+		return bson.RawValue{}, primitive.NilObjectID, util.ErrQueryInvalidCursor
+	}
+
+	if !cursorSortValueTypes[c.SortValue.Type] || c.SortValue.Validate() != nil {
+		return bson.RawValue{}, primitive.NilObjectID, util.ErrQueryInvalidCursor
+	}
+
+	return c.SortValue, c.ID, nil
+}
+
+// SetPaginationHeaders advertises the next page of a list response, if any, through the
+// X-Next-Cursor and Link headers.
+func SetPaginationHeaders(w http.ResponseWriter, r *http.Request, nextCursor string) {
+	if nextCursor == "" {
+		return
+	}
+
+	next := *r.URL
+	values := next.Query()
+	values.Set("cursor", nextCursor)
+	next.RawQuery = values.Encode()
+
+	w.Header().Set("X-Next-Cursor", nextCursor)
+	w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.RequestURI()))
+}
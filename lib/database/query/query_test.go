@@ -0,0 +1,125 @@
+package query
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var testSchema = Schema{
+	Filters: map[string]Field{
+		"name":        {Path: "name", Type: String},
+		"category_id": {Path: "category._id", Type: ObjectID},
+	},
+	Sorts: map[string]Field{
+		"name": {Path: "name"},
+	},
+}
+
+func TestParse(t *testing.T) {
+	categoryID, _ := primitive.ObjectIDFromHex("663d70d88264adea5d7d29bb")
+
+	tests := []struct {
+		name    string
+		values  url.Values
+		want    *Query
+		wantErr error
+	}{
+		{
+			name:   "Defaults",
+			values: url.Values{},
+			want:   &Query{Limit: DefaultLimit},
+		},
+		{
+			name: "Filters_sort_limit_and_cursor",
+			values: url.Values{
+				"category_id": {"663d70d88264adea5d7d29bb"},
+				"sort":        {"-name"},
+				"limit":       {"10"},
+				"cursor":      {"abc"},
+			},
+			want: &Query{
+				Filters:    []Filter{{Path: "category._id", Value: categoryID}},
+				SortBy:     "name",
+				Descending: true,
+				Limit:      10,
+				Cursor:     "abc",
+			},
+		},
+		{
+			name:    "Limit_is_too_big",
+			values:  url.Values{"limit": {"1000"}},
+			wantErr: util.ErrQueryInvalidLimit,
+		},
+		{
+			name:    "Unknown_filter",
+			values:  url.Values{"password": {"secret"}},
+			wantErr: util.ErrQueryUnknownFilter,
+		},
+		{
+			name:    "Unknown_sort",
+			values:  url.Values{"sort": {"created_at"}},
+			wantErr: util.ErrQueryUnknownSort,
+		},
+		{
+			name:    "Invalid_object_id",
+			values:  url.Values{"category_id": {"invalid"}},
+			wantErr: util.ErrQueryInvalidFilterValue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.values, testSchema)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursor(t *testing.T) {
+	id := primitive.NewObjectID()
+	_, bs, _ := bson.MarshalValue("Rafael")
+	sortValue := bson.RawValue{Type: bson.TypeString, Value: bs}
+
+	cursor, err := EncodeCursor("name", true, sortValue, id)
+	if err != nil {
+		t.Fatalf("EncodeCursor() error = %v", err)
+	}
+
+	gotValue, gotID, err := DecodeCursor(cursor, "name", true)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if gotValue.StringValue() != "Rafael" || gotID != id {
+		t.Errorf("DecodeCursor() = %v, %v, want %v, %v", gotValue, gotID, "Rafael", id)
+	}
+
+	if _, _, err := DecodeCursor(cursor, "created_at", true); !errors.Is(err, util.ErrQueryInvalidCursor) {
+		t.Errorf("DecodeCursor() with another sort field error = %v, want %v", err, util.ErrQueryInvalidCursor)
+	}
+
+	if _, _, err := DecodeCursor(cursor, "name", false); !errors.Is(err, util.ErrQueryInvalidCursor) {
+		t.Errorf("DecodeCursor() with another sort direction error = %v, want %v", err, util.ErrQueryInvalidCursor)
+	}
+
+	// A forged cursor whose sort value is a query operator rather than a value
+	_, bs, _ = bson.MarshalValue(bson.D{{Key: "$ne", Value: nil}})
+	forged, err := EncodeCursor("name", true, bson.RawValue{Type: bson.TypeEmbeddedDocument, Value: bs}, id)
+	if err != nil {
+		t.Fatalf("EncodeCursor() error = %v", err)
+	}
+
+	if _, _, err := DecodeCursor(forged, "name", true); !errors.Is(err, util.ErrQueryInvalidCursor) {
+		t.Errorf("DecodeCursor() with a document as sort value error = %v, want %v", err, util.ErrQueryInvalidCursor)
+	}
+}
@@ -0,0 +1,120 @@
+// Package quota enforces the limits of the plan of a tenant before its data grows.
+//
+// Limits are checked before writing rather than together with the write, so requests of the same
+// tenant served at the same time may go slightly over them, by as much as they add. Storage is
+// estimated for tenants in shared databases, see database.DBReader.StorageSize.
+package quota
+
+import (
+	"context"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+// Usage is what a tenant uses of its plan.
+type Usage struct {
+	Players             int64 `json:"players"`
+	TournamentsThisYear int64 `json:"tournaments_this_year"`
+	StorageBytes        int64 `json:"storage_bytes"`
+}
+
+type Checker struct {
+	DBReader database.DBReader
+	Plan     entity.Plan
+}
+
+func NewChecker(dbReader database.DBReader, plan entity.Plan) *Checker {
+	return &Checker{
+		DBReader: dbReader,
+		Plan:     plan,
+	}
+}
+
+// CheckPlayers returns util.ErrPlanPlayersLimitReached when adding players would go over the plan.
+func (c *Checker) CheckPlayers(ctx context.Context, adding int64) error {
+	if c.Plan.MaxPlayers == 0 {
+		return c.CheckStorage(ctx)
+	}
+
+	players, err := c.DBReader.CountPlayers(ctx)
+	if err != nil {
+		return err
+	}
+
+	if players+adding > c.Plan.MaxPlayers {
+		return util.ErrPlanPlayersLimitReached
+	}
+
+	return c.CheckStorage(ctx)
+}
+
+// CheckTournaments returns util.ErrPlanTournamentsLimitReached when adding tournaments that start in
+// year would go over the plan.
+func (c *Checker) CheckTournaments(ctx context.Context, year int, adding int64) error {
+	if c.Plan.MaxTournamentsPerYear == 0 {
+		return c.CheckStorage(ctx)
+	}
+
+	from, to := yearRange(year)
+	tournaments, err := c.DBReader.CountTournaments(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	if tournaments+adding > c.Plan.MaxTournamentsPerYear {
+		return util.ErrPlanTournamentsLimitReached
+	}
+
+	return c.CheckStorage(ctx)
+}
+
+// CheckStorage returns util.ErrPlanStorageLimitReached once the tenant uses all of its storage.
+func (c *Checker) CheckStorage(ctx context.Context) error {
+	if c.Plan.MaxStorageBytes == 0 {
+		return nil
+	}
+
+	size, err := c.DBReader.StorageSize(ctx)
+	if err != nil {
+		return err
+	}
+
+	if size >= c.Plan.MaxStorageBytes {
+		return util.ErrPlanStorageLimitReached
+	}
+
+	return nil
+}
+
+// Usage returns what the tenant uses as of now.
+func (c *Checker) Usage(ctx context.Context, now time.Time) (*Usage, error) {
+	players, err := c.DBReader.CountPlayers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := yearRange(now.Year())
+	tournaments, err := c.DBReader.CountTournaments(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := c.DBReader.StorageSize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Usage{
+		Players:             players,
+		TournamentsThisYear: tournaments,
+		StorageBytes:        size,
+	}, nil
+}
+
+func yearRange(year int) (time.Time, time.Time) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(1, 0, 0)
+}
@@ -0,0 +1,69 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestChecker_CheckPlayers(t *testing.T) {
+	plan := entity.Plan{MaxPlayers: 10, MaxStorageBytes: 1000}
+
+	tests := []struct {
+		name    string
+		players int64
+		adding  int64
+		size    int64
+		wantErr error
+	}{
+		{name: "below the limit", players: 8, adding: 2, size: 10},
+		{name: "over the limit", players: 9, adding: 2, wantErr: util.ErrPlanPlayersLimitReached},
+		{name: "storage is full", players: 1, adding: 1, size: 1000, wantErr: util.ErrPlanStorageLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dbReaderMock := database.NewMockDBReader(gomock.NewController(t))
+			dbReaderMock.EXPECT().CountPlayers(gomock.Any()).Return(tt.players, nil)
+			dbReaderMock.EXPECT().StorageSize(gomock.Any()).Return(tt.size, nil).MaxTimes(1)
+
+			err := NewChecker(dbReaderMock, plan).CheckPlayers(context.Background(), tt.adding)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckPlayers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChecker_CheckTournaments_CountsTheYear(t *testing.T) {
+	dbReaderMock := database.NewMockDBReader(gomock.NewController(t))
+	dbReaderMock.EXPECT().CountTournaments(gomock.Any(),
+		time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC),
+	).Return(int64(12), nil)
+
+	err := NewChecker(dbReaderMock, entity.Plan{MaxTournamentsPerYear: 12}).CheckTournaments(context.Background(), 2026, 1)
+	if !errors.Is(err, util.ErrPlanTournamentsLimitReached) {
+		t.Errorf("CheckTournaments() error = %v, wantErr %v", err, util.ErrPlanTournamentsLimitReached)
+	}
+}
+
+func TestChecker_UnlimitedPlanDoesNotCount(t *testing.T) {
+	// The mock fails the test on any call
+	dbReaderMock := database.NewMockDBReader(gomock.NewController(t))
+	checker := NewChecker(dbReaderMock, entity.Plan{})
+
+	if err := checker.CheckPlayers(context.Background(), 1000); err != nil {
+		t.Errorf("CheckPlayers() error = %v", err)
+	}
+
+	if err := checker.CheckTournaments(context.Background(), 2026, 1000); err != nil {
+		t.Errorf("CheckTournaments() error = %v", err)
+	}
+}
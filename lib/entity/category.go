@@ -10,8 +10,11 @@ import (
 type Category struct {
 	ID        primitive.ObjectID `bson:"_id" json:"id"`
 	Name      string             `bson:"name" json:"name"`
+	Version   int64              `bson:"version" json:"version"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt *time.Time         `bson:"updated_at" json:"updated_at"`
+	DeletedAt *time.Time         `bson:"deleted_at" json:"deleted_at"`
+	DeletedBy *string            `bson:"deleted_by" json:"deleted_by"`
 }
 
 func NewCategory(name string) *Category {
@@ -27,3 +30,25 @@ func (c *Category) MarshalBinary() ([]byte, error) {
 func (c *Category) UnmarshalBinary(data []byte) error {
 	return json.Unmarshal(data, c)
 }
+
+// CategoryDeletePolicy decides what happens to the players and tournaments that embed a
+// category when the category is deleted.
+type CategoryDeletePolicy string
+
+const (
+	// CategoryDeletePolicyBlock refuses to delete a category that is still in use.
+	CategoryDeletePolicyBlock CategoryDeletePolicy = "block"
+	// CategoryDeletePolicyCascade removes the category from every player and editable tournament.
+	CategoryDeletePolicyCascade CategoryDeletePolicy = "cascade"
+	// CategoryDeletePolicyReassign moves every player and editable tournament to another category.
+	CategoryDeletePolicyReassign CategoryDeletePolicy = "reassign"
+)
+
+func (p CategoryDeletePolicy) IsValid() bool {
+	switch p {
+	case CategoryDeletePolicyBlock, CategoryDeletePolicyCascade, CategoryDeletePolicyReassign:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IdempotencyRecord is the response to a request sent with an Idempotency-Key, kept until ExpiresAt
+// to be replayed when the request is retried. Fingerprint tells the request apart from others sent
+// with the same key, and StatusCode is zero while the first request is being served.
+type IdempotencyRecord struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	Key         string             `bson:"key" json:"key"`
+	Fingerprint string             `bson:"fingerprint" json:"fingerprint"`
+	StatusCode  int                `bson:"status_code" json:"status_code"`
+	Header      http.Header        `bson:"header" json:"header"`
+	Body        []byte             `bson:"body" json:"body"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	ExpiresAt   time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+func (r *IdempotencyRecord) IsCompleted() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,30 @@
+package entity
+
+// Plan holds the limits of what the tenants of a tier can use. Zero means unlimited.
+type Plan struct {
+	MaxPlayers            int64 `json:"max_players"`
+	MaxTournamentsPerYear int64 `json:"max_tournaments_per_year"`
+	MaxAPICallsPerMinute  int64 `json:"max_api_calls_per_minute"`
+	MaxStorageBytes       int64 `json:"max_storage_bytes"`
+}
+
+// Plans are the plans of every tier.
+var Plans = map[TenantTier]Plan{
+	TenantTierShared: {
+		MaxPlayers:            200,
+		MaxTournamentsPerYear: 12,
+		MaxAPICallsPerMinute:  120,
+		MaxStorageBytes:       100 << 20,
+	},
+	TenantTierStandard: {
+		MaxPlayers:            5000,
+		MaxTournamentsPerYear: 100,
+		MaxAPICallsPerMinute:  1200,
+		MaxStorageBytes:       5 << 30,
+	},
+	TenantTierDiamond: {},
+}
+
+func (t *Tenant) GetPlan() Plan {
+	return Plans[t.GetTier()]
+}
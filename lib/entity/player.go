@@ -10,6 +10,7 @@ import (
 type Player struct {
 	ID                  primitive.ObjectID `bson:"_id" json:"id"`
 	GovernmentID        string             `bson:"government_id" json:"government_id"`
+	Country             string             `bson:"country" json:"country"`
 	FirstName           string             `bson:"first_name" json:"first_name"`
 	MiddleName          string             `bson:"middle_name" json:"middle_name"`
 	LastName            string             `bson:"last_name" json:"last_name"`
@@ -20,12 +21,17 @@ type Player struct {
 	TemporaryAccessCode string             `bson:"temporary_access_code" json:"-"`
 	Password            string             `bson:"password" json:"-"`
 	Category            *Category          `bson:"category" json:"category"`
+	Version             int64              `bson:"version" json:"version"`
 	CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt           *time.Time         `bson:"updated_at" json:"updated_at"`
+	DeletedAt           *time.Time         `bson:"deleted_at" json:"deleted_at"`
+	DeletedBy           *string            `bson:"deleted_by" json:"deleted_by"`
+	SearchTerms         []string           `bson:"search_terms" json:"-"`
 }
 
 func NewPlayer(
 	governmentID string,
+	country string,
 	firstName string,
 	middleName string,
 	lastName string,
@@ -36,6 +42,7 @@ func NewPlayer(
 ) *Player {
 	return &Player{
 		GovernmentID: governmentID,
+		Country:      country,
 		FirstName:    firstName,
 		MiddleName:   middleName,
 		LastName:     lastName,
@@ -47,6 +54,26 @@ func NewPlayer(
 	}
 }
 
+// MergeFrom fills the fields of the player that have not been set with the values of duplicate.
+// Government ID, email and alias are unique per player, so they are never copied.
+func (p *Player) MergeFrom(duplicate *Player) {
+	if p.MiddleName == "" {
+		p.MiddleName = duplicate.MiddleName
+	}
+
+	if p.Birthdate == nil {
+		p.Birthdate = duplicate.Birthdate
+	}
+
+	if p.PhoneNumber == "" {
+		p.PhoneNumber = duplicate.PhoneNumber
+	}
+
+	if p.Category == nil {
+		p.Category = duplicate.Category
+	}
+}
+
 func (p *Player) MarshalBinary() ([]byte, error) {
 	return json.Marshal(p)
 }
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PlayerMerge records that Duplicate was merged into the player identified by SurvivorID. The
+// players are stored as they were right before the merge so that it can be reviewed later.
+type PlayerMerge struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	SurvivorID primitive.ObjectID `bson:"survivor_id" json:"survivor_id"`
+	Survivor   Player             `bson:"survivor" json:"survivor"`
+	Duplicate  Player             `bson:"duplicate" json:"duplicate"`
+	MergedAt   time.Time          `bson:"merged_at" json:"merged_at"`
+	MergedBy   *string            `bson:"merged_by" json:"merged_by"`
+}
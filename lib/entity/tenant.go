@@ -6,16 +6,144 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type TenantTier string
+
+const (
+	// TenantTierStandard tenants have their own database and are served by the shared deployments
+	TenantTierStandard TenantTier = "standard"
+	// TenantTierDiamond tenants have their own database and their own deployments of the services
+	TenantTierDiamond TenantTier = "diamond"
+	// TenantTierShared tenants share their database with other tenants and are served by the
+	// shared deployments
+	TenantTierShared TenantTier = "shared"
+)
+
+func (t TenantTier) IsValid() bool {
+	return t == TenantTierStandard || t == TenantTierDiamond || t == TenantTierShared
+}
+
+type TenantStatus string
+
+const (
+	TenantStatusActive     TenantStatus = "active"
+	TenantStatusSuspended  TenantStatus = "suspended"
+	TenantStatusOffboarded TenantStatus = "offboarded"
+)
+
+// tenantTransitions lists, for every status, the statuses a tenant can move to.
+// Offboarded tenants are final.
+var tenantTransitions = map[TenantStatus][]TenantStatus{
+	TenantStatusActive:     {TenantStatusSuspended, TenantStatusOffboarded},
+	TenantStatusSuspended:  {TenantStatusActive, TenantStatusOffboarded},
+	TenantStatusOffboarded: {},
+}
+
+func (s TenantStatus) IsValid() bool {
+	_, ok := tenantTransitions[s]
+	return ok
+}
+
+// Tenant is a club using the app. Slug is its unique subdomain below the base domain, see util.Slug.
+// VerifiedDomains mirrors its verified CustomDomains and DedicatedDatabase its DatabaseName unless
+// it is shared, so that the database keeps them unique.
 type Tenant struct {
 	ID                      primitive.ObjectID `bson:"_id" json:"id"`
 	Name                    string             `bson:"name" json:"name"`
+	Slug                    string             `bson:"slug" json:"slug"`
 	PhoneNumber             string             `bson:"phone_number" json:"phone_number"`
 	Email                   string             `bson:"email" json:"email"`
-	Tier                    string             `bson:"tier" json:"tier"`
+	Tier                    TenantTier         `bson:"tier" json:"tier"`
 	MongoDBConnectionString string             `bson:"mongo_db_connection_string" json:"-"`
 	DatabaseName            string             `bson:"database_name" json:"database_name"`
+	Status                  TenantStatus       `bson:"status" json:"status"`
+	StatusReason            *string            `bson:"status_reason" json:"status_reason"`
+	StatusChangedAt         *time.Time         `bson:"status_changed_at" json:"status_changed_at"`
+	CustomDomains           []CustomDomain     `bson:"custom_domains" json:"custom_domains"`
+	VerifiedDomains         []string           `bson:"verified_domains,omitempty" json:"-"`
+	DedicatedDatabase       string             `bson:"dedicated_database,omitempty" json:"-"`
 	CreatedBy               string             `bson:"created_by" json:"created_by"`
 	CreatedAt               time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt               *time.Time         `bson:"updated_at" json:"updated_at"`
 	UpdatedBy               *string            `bson:"updated_by" json:"updated_by"`
+	DeletedAt               *time.Time         `bson:"deleted_at" json:"deleted_at"`
+	DeletedBy               *string            `bson:"deleted_by" json:"deleted_by"`
+}
+
+// CustomDomain is a domain of its own the tenant is served at, e.g. club.example.com. It is only
+// used once the owner of the domain proves it by publishing VerificationToken in a TXT record at
+// VerificationRecord.
+type CustomDomain struct {
+	Domain            string     `bson:"domain" json:"domain"`
+	VerificationToken string     `bson:"verification_token" json:"verification_token"`
+	VerifiedAt        *time.Time `bson:"verified_at" json:"verified_at"`
+}
+
+func (d *CustomDomain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+func (d *CustomDomain) VerificationRecord() string {
+	return "_gotennis-verification." + d.Domain
+}
+
+// VerifiedDomainNames returns the custom domains of the tenant that have been verified.
+func (t *Tenant) VerifiedDomainNames() []string {
+	var domains []string
+	for _, d := range t.CustomDomains {
+		if d.IsVerified() {
+			domains = append(domains, d.Domain)
+		}
+	}
+
+	return domains
+}
+
+// DedicatedDatabaseName returns the name of the database of the tenant when it holds no other
+// tenant, and an empty one otherwise.
+func (t *Tenant) DedicatedDatabaseName() string {
+	if t.HasSharedDatabase() {
+		return ""
+	}
+
+	return t.DatabaseName
+}
+
+// GetTier returns the tier of the tenant. Tenants stored before tiers were validated may have none
+// and are considered standard.
+func (t *Tenant) GetTier() TenantTier {
+	if t.Tier == "" {
+		return TenantTierStandard
+	}
+
+	return t.Tier
+}
+
+// IsDedicated reports whether the tenant is served by its own deployments of the services.
+func (t *Tenant) IsDedicated() bool {
+	return t.GetTier() == TenantTierDiamond
+}
+
+// HasSharedDatabase reports whether the database of the tenant holds other tenants too.
+func (t *Tenant) HasSharedDatabase() bool {
+	return t.GetTier() == TenantTierShared
+}
+
+// GetStatus returns the current status of the tenant. Tenants stored before statuses were
+// introduced have none and are considered active.
+func (t *Tenant) GetStatus() TenantStatus {
+	if t.Status == "" {
+		return TenantStatusActive
+	}
+
+	return t.Status
+}
+
+func (t *Tenant) CanTransitionTo(status TenantStatus) bool {
+	for _, s := range tenantTransitions[t.GetStatus()] {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
 }
@@ -1,11 +1,41 @@
 package entity
 
 import (
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+type TournamentStatus string
+
+const (
+	TournamentStatusDraft         TournamentStatus = "draft"
+	TournamentStatusEntriesOpen   TournamentStatus = "entries_open"
+	TournamentStatusEntriesClosed TournamentStatus = "entries_closed"
+	TournamentStatusDrawPublished TournamentStatus = "draw_published"
+	TournamentStatusInProgress    TournamentStatus = "in_progress"
+	TournamentStatusCompleted     TournamentStatus = "completed"
+	TournamentStatusCancelled     TournamentStatus = "cancelled"
+)
+
+// tournamentTransitions lists, for every status, the statuses a tournament can move to.
+// Completed and cancelled tournaments are final.
+var tournamentTransitions = map[TournamentStatus][]TournamentStatus{
+	TournamentStatusDraft:         {TournamentStatusEntriesOpen, TournamentStatusCancelled},
+	TournamentStatusEntriesOpen:   {TournamentStatusDraft, TournamentStatusEntriesClosed, TournamentStatusCancelled},
+	TournamentStatusEntriesClosed: {TournamentStatusEntriesOpen, TournamentStatusDrawPublished, TournamentStatusCancelled},
+	TournamentStatusDrawPublished: {TournamentStatusEntriesClosed, TournamentStatusInProgress, TournamentStatusCancelled},
+	TournamentStatusInProgress:    {TournamentStatusCompleted, TournamentStatusCancelled},
+	TournamentStatusCompleted:     {},
+	TournamentStatusCancelled:     {},
+}
+
+func (s TournamentStatus) IsValid() bool {
+	_, ok := tournamentTransitions[s]
+	return ok
+}
+
 type Tournament struct {
 	ID        primitive.ObjectID `bson:"_id" json:"id"`
 	Name      string             `bson:"name" json:"name"`
@@ -13,4 +43,41 @@ type Tournament struct {
 	StartDate time.Time          `bson:"start_date" json:"start_date"`
 	EndDate   time.Time          `bson:"end_date" json:"end_date"`
 	Category  *Category          `bson:"category" json:"category"`
+	Status    TournamentStatus   `bson:"status" json:"status"`
+	Version   int64              `bson:"version" json:"version"`
+	DeletedAt *time.Time         `bson:"deleted_at" json:"deleted_at"`
+	DeletedBy *string            `bson:"deleted_by" json:"deleted_by"`
+}
+
+// GetStatus returns the current status of the tournament. Tournaments stored before
+// statuses were introduced have none and are considered drafts.
+func (t *Tournament) GetStatus() TournamentStatus {
+	if t.Status == "" {
+		return TournamentStatusDraft
+	}
+
+	return t.Status
+}
+
+func (t *Tournament) CanTransitionTo(status TournamentStatus) bool {
+	for _, s := range tournamentTransitions[t.GetStatus()] {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EditableTournamentStatuses are the statuses of the tournaments whose details can still be changed.
+var EditableTournamentStatuses = []TournamentStatus{TournamentStatusDraft, TournamentStatusEntriesOpen}
+
+// IsEditable reports whether the tournament details (name, dates, category...) can still be changed.
+func (t *Tournament) IsEditable() bool {
+	return slices.Contains(EditableTournamentStatuses, t.GetStatus())
+}
+
+func (t *Tournament) CanBeDeleted() bool {
+	status := t.GetStatus()
+	return status == TournamentStatusDraft || status == TournamentStatusCancelled
 }
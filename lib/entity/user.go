@@ -6,6 +6,11 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const (
+	UserRoleAdmin  = "admin"
+	UserRolePlayer = "player"
+)
+
 type User struct {
 	ID                  primitive.ObjectID `bson:"_id" json:"id"`
 	CustomerID          primitive.ObjectID `bson:"customer_id" json:"customer_id"`
@@ -14,8 +19,11 @@ type User struct {
 	Alias               *string            `bson:"alias" json:"alias"`
 	TemporaryAccessCode string             `bson:"temporary_access_code" json:"-"`
 	Password            string             `bson:"password" json:"-"`
+	Role                string             `bson:"role" json:"role"`
 	CreatedBy           string             `bson:"created_by" json:"created_by"`
 	CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt           *time.Time         `bson:"updated_at" json:"updated_at"`
 	UpdatedBy           *string            `bson:"updated_by" json:"updated_by"`
+	DeletedAt           *time.Time         `bson:"deleted_at" json:"deleted_at"`
+	DeletedBy           *string            `bson:"deleted_by" json:"deleted_by"`
 }
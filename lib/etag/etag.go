@@ -0,0 +1,60 @@
+// Package etag lets clients update resources without overwriting the changes of others. Every
+// player, category and tournament has a version, increased whenever it is written, which is
+// returned as its ETag:
+//
+//	GET /players/663d70d88264adea5d7d29bb
+//	ETag: "3"
+//
+// Sending it back in If-Match makes the update fail with 412 Precondition Failed when the resource
+// has been written in between:
+//
+//	PUT /players/663d70d88264adea5d7d29bb
+//	If-Match: "3"
+package etag
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+const (
+	Header        = "ETag"
+	IfMatchHeader = "If-Match"
+)
+
+// Format returns the ETag of a resource with the given version.
+func Format(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// Set adds the ETag of a resource with the given version to w.
+func Set(w http.ResponseWriter, version int64) {
+	w.Header().Set(Header, Format(version))
+}
+
+// IfMatch returns the version in the If-Match header of r, nil when there is none or it is *, which
+// matches any version. Weak ETags never match, as If-Match compares them strongly.
+func IfMatch(r *http.Request) (*int64, error) {
+	value := strings.TrimSpace(r.Header.Get(IfMatchHeader))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(value, "W/") {
+		return nil, util.ErrPreconditionFailed
+	}
+
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return nil, util.ErrIfMatchIsInvalid
+	}
+
+	version, err := strconv.ParseInt(value[1:len(value)-1], 10, 64)
+	if err != nil || version < 0 {
+		return nil, util.ErrIfMatchIsInvalid
+	}
+
+	return &version, nil
+}
@@ -0,0 +1,54 @@
+package etag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+func TestSet(t *testing.T) {
+	w := httptest.NewRecorder()
+	Set(w, 3)
+
+	if got := w.Header().Get(Header); got != `"3"` {
+		t.Errorf("ETag = %v, want %v", got, `"3"`)
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    *int64
+		wantErr error
+	}{
+		{"no header", "", nil, nil},
+		{"any version", "*", nil, nil},
+		{"version", `"3"`, util.ToPtr(int64(3)), nil},
+		{"weak etag", `W/"3"`, nil, util.ErrPreconditionFailed},
+		{"unquoted", "3", nil, util.ErrIfMatchIsInvalid},
+		{"not a version", `"abc"`, nil, util.ErrIfMatchIsInvalid},
+		{"negative version", `"-1"`, nil, util.ErrIfMatchIsInvalid},
+		{"several etags", `"3", "4"`, nil, util.ErrIfMatchIsInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/players/1", nil)
+			if tt.ifMatch != "" {
+				r.Header.Set(IfMatchHeader, tt.ifMatch)
+			}
+
+			got, err := IfMatch(r)
+			if err != tt.wantErr {
+				t.Fatalf("IfMatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("IfMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
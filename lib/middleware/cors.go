@@ -7,9 +7,7 @@ func CORSMiddleware(next http.Handler) http.Handler {
 		w.Header().Add("Access-Control-Allow-Origin", "*")
 		w.Header().Add("Access-Control-Allow-Methods", "*")
 		w.Header().Add("Access-Control-Allow-Headers", "*")
-		w.Header().Add("Access-Control-Expose-Headers", "X-Tenant-ID")
-
-		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("Access-Control-Expose-Headers", "X-Tenant-ID, X-Next-Cursor, Link, Content-Disposition, X-RateLimit-Limit, X-RateLimit-Remaining, Retry-After, Idempotent-Replayed, ETag")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
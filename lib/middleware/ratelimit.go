@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/problem"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+// RateLimitMiddleware rejects with 429 Too Many Requests the requests of the tenant in the
+// X-Tenant-ID header once it makes more than limit requests in the current minute. A limit of zero
+// means unlimited. Requests are counted by each instance of the service on its own.
+func RateLimitMiddleware(next http.Handler, limit func(tenantID string) int64) http.Handler {
+	var (
+		mu     sync.Mutex
+		window time.Time
+		counts = make(map[string]int64)
+	)
+
+	f := func(w http.ResponseWriter, r *http.Request) {
+		tenantID := r.Header.Get("X-Tenant-ID")
+		if tenantID == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowed := limit(tenantID)
+		if allowed == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		now := time.Now()
+		mu.Lock()
+		if current := now.Truncate(time.Minute); !current.Equal(window) {
+			window = current
+			counts = make(map[string]int64)
+		}
+		counts[tenantID]++
+		count := counts[tenantID]
+		reset := window.Add(time.Minute)
+		mu.Unlock()
+
+		w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(allowed, 10))
+		w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(max(0, allowed-count), 10))
+
+		if count > allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(reset.Sub(now).Seconds())+1))
+			problem.Write(w, r, fmt.Errorf("%w: %d", util.ErrPlanAPICallsLimitReached, allowed))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(f)
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Neniel/gotennis/lib/problem"
+)
+
+// TenantMiddleware rejects the requests of the tenant in the X-Tenant-ID header when check returns
+// why it cannot be served, e.g. with 403 Forbidden because it is suspended.
+func TenantMiddleware(next http.Handler, check func(tenantID string) error) http.Handler {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		if tenantID := r.Header.Get("X-Tenant-ID"); tenantID != "" {
+			if err := check(tenantID); err != nil {
+				problem.Write(w, r, err)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(f)
+}
@@ -0,0 +1,122 @@
+// Package problem reports errors to clients as RFC 7807 problem details:
+//
+//	HTTP/1.1 404 Not Found
+//	Content-Type: application/problem+json
+//
+//	{"type":"about:blank","title":"Not Found","status":404,"detail":"resource does not exist","instance":"/players/663d70d88264adea5d7d29bb","code":"not_found"}
+//
+// Code is the one of the util.AppError behind the problem, which clients can rely on. Requests with
+// fields that are not valid are reported with all of them in Errors, see util.ValidationError.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const ContentType = "application/problem+json"
+
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// Errors are the fields of the request that are not valid, if any
+	Errors []util.FieldError `json:"errors,omitempty"`
+}
+
+var statuses = map[util.ErrorKind]int{
+	util.ErrorKindValidation:         http.StatusBadRequest,
+	util.ErrorKindUnauthorized:       http.StatusUnauthorized,
+	util.ErrorKindPaymentRequired:    http.StatusPaymentRequired,
+	util.ErrorKindForbidden:          http.StatusForbidden,
+	util.ErrorKindNotFound:           http.StatusNotFound,
+	util.ErrorKindConflict:           http.StatusConflict,
+	util.ErrorKindPreconditionFailed: http.StatusPreconditionFailed,
+	util.ErrorKindUnprocessable:      http.StatusUnprocessableEntity,
+	util.ErrorKindTooManyRequests:    http.StatusTooManyRequests,
+	util.ErrorKindUnavailable:        http.StatusServiceUnavailable,
+	util.ErrorKindInternal:           http.StatusInternalServerError,
+}
+
+// AppError returns the util.AppError err is or wraps, translating the errors of the database driver
+// and of the decoding of request bodies. Any other error is util.ErrInternal.
+func AppError(err error) *util.AppError {
+	var appError *util.AppError
+	var syntaxError *json.SyntaxError
+	var unmarshalTypeError *json.UnmarshalTypeError
+	var maxBytesError *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &appError):
+		return appError
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return util.ErrNotFound
+	case errors.Is(err, primitive.ErrInvalidHex):
+		return util.ErrInvalidID
+	case mongo.IsDuplicateKeyError(err):
+		return util.ErrDuplicateKey
+	case errors.As(err, &syntaxError),
+		errors.As(err, &unmarshalTypeError),
+		errors.As(err, &maxBytesError),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF):
+		return util.ErrRequestBodyIsInvalid
+	default:
+		return util.ErrInternal
+	}
+}
+
+// StatusCode returns the HTTP status err is reported with.
+func StatusCode(err error) int {
+	return statuses[AppError(err).Kind]
+}
+
+// New returns the problem err is reported as. The detail is the message of err when it wraps a
+// util.AppError, since it may add details to it, and the one of the util.AppError it is translated
+// to otherwise, so that internal errors are never shown.
+func New(r *http.Request, err error) *Problem {
+	appError := AppError(err)
+	status := statuses[appError.Kind]
+
+	detail := appError.Message
+	switch {
+	case errors.As(err, new(*util.AppError)):
+		detail = err.Error()
+	case appError == util.ErrRequestBodyIsInvalid:
+		detail = appError.Message + ": " + err.Error()
+	}
+
+	p := &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     appError.Code,
+	}
+
+	var validationError *util.ValidationError
+	if errors.As(err, &validationError) {
+		p.Errors = validationError.Errors
+	}
+
+	return p
+}
+
+// Write reports err to the client as a problem.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := New(r, err)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
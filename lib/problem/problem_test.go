@@ -0,0 +1,71 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{"app error", util.ErrPlayerEmailIsTaken, http.StatusConflict, "player_email_is_taken", util.ErrPlayerEmailIsTaken.Message},
+		{"wrapped app error", fmt.Errorf("%w: 60", util.ErrPlanAPICallsLimitReached), http.StatusTooManyRequests, "plan_api_calls_limit_reached", util.ErrPlanAPICallsLimitReached.Message + ": 60"},
+		{"no documents", mongo.ErrNoDocuments, http.StatusNotFound, "not_found", util.ErrNotFound.Message},
+		{"invalid hex", primitive.ErrInvalidHex, http.StatusBadRequest, "invalid_id", util.ErrInvalidID.Message},
+		{"invalid body", json.Unmarshal([]byte("{"), &struct{}{}), http.StatusBadRequest, "request_body_is_invalid", util.ErrRequestBodyIsInvalid.Message + ": unexpected end of JSON input"},
+		{"validation error", validationError(), http.StatusBadRequest, "validation_failed", util.ErrPlayerEmailIsEmpty.Message + "; " + util.ErrPlayerLastNameIsEmpty.Message},
+		{"internal error", errors.New("connection refused"), http.StatusInternalServerError, "internal_error", util.ErrInternal.Message},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Write(w, httptest.NewRequest(http.MethodGet, "/players/1", nil), tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", w.Code, tt.wantStatus)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != ContentType {
+				t.Errorf("Content-Type = %v, want %v", got, ContentType)
+			}
+
+			var p Problem
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+
+			if p.Code != tt.wantCode || p.Detail != tt.wantDetail || p.Status != tt.wantStatus || p.Instance != "/players/1" {
+				t.Errorf("problem = %+v", p)
+			}
+		})
+	}
+}
+
+func validationError() error {
+	var v util.Validator
+	v.Add("email", util.ErrPlayerEmailIsEmpty)
+	v.Add("last_name", util.ErrPlayerLastNameIsEmpty)
+	return v.Err()
+}
+
+func TestNew_ValidationError(t *testing.T) {
+	p := New(httptest.NewRequest(http.MethodPost, "/players", nil), validationError())
+
+	if len(p.Errors) != 2 || p.Errors[0].Field != "email" || p.Errors[1].Code != "player_last_name_is_empty" {
+		t.Errorf("Errors = %+v", p.Errors)
+	}
+}
@@ -0,0 +1,251 @@
+package security
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+// Values are encrypted with a data key of their own, which is stored next to them encrypted with a
+// master key:
+//
+//	enc:v2:<master key id>:<encrypted data key>:<encrypted value>
+//
+// Both are bound to the owner of the value, e.g. the ID of a tenant, so that they cannot be copied
+// to another owner. Values encrypted as enc:v1 are not bound to any owner.
+const (
+	encryptedPrefix   = "enc:v2:"
+	encryptedPrefixV1 = "enc:v1:"
+)
+
+const keySize = 32
+
+// KeyProvider holds the master keys. New values are encrypted with the current one, while the
+// others are kept to decrypt the values encrypted before a rotation.
+type KeyProvider interface {
+	CurrentKey() (id string, key []byte)
+	Key(id string) ([]byte, error)
+}
+
+// Keyring is a KeyProvider whose first key is the current one.
+type Keyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewKeyring parses keys written as <id>:<base64 key>, one per line or separated by commas. Empty
+// lines and the ones starting with # are skipped.
+func NewKeyring(keys string) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string][]byte)}
+
+	scanner := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(keys, ",", "\n")))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(line, ":")
+		if !ok || id == "" {
+			return nil, util.ErrEncryptionKeyIsInvalid
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != keySize {
+			return nil, util.ErrEncryptionKeyIsInvalid
+		}
+
+		if keyring.current == "" {
+			keyring.current = id
+		}
+		keyring.keys[id] = key
+	}
+
+	if keyring.current == "" {
+		return nil, util.ErrEncryptionKeyIsInvalid
+	}
+
+	return keyring, nil
+}
+
+// NewKeyringFromFile reads the keys of the keyring from a file, see NewKeyring.
+func NewKeyringFromFile(path string) (*Keyring, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyring(string(bs))
+}
+
+// KeyProviderFromEnvironment returns the keyring in the MASTER_KEYS environment variable or, for
+// development, the one in the file at MASTER_KEYFILE. It returns nil when neither is set, so that
+// values are stored as they are.
+func KeyProviderFromEnvironment() (KeyProvider, error) {
+	if keys := os.Getenv("MASTER_KEYS"); keys != "" {
+		keyring, err := NewKeyring(keys)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading MASTER_KEYS environment variable: %w", err)
+		}
+
+		return keyring, nil
+	}
+
+	if path := os.Getenv("MASTER_KEYFILE"); path != "" {
+		keyring, err := NewKeyringFromFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading keyfile in MASTER_KEYFILE environment variable: %w", err)
+		}
+
+		return keyring, nil
+	}
+
+	return nil, nil
+}
+
+func (k *Keyring) CurrentKey() (string, []byte) {
+	return k.current, k.keys[k.current]
+}
+
+func (k *Keyring) Key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", util.ErrEncryptionKeyNotFound, id)
+	}
+
+	return key, nil
+}
+
+// IsEncrypted reports whether value was returned by Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix) || strings.HasPrefix(value, encryptedPrefixV1)
+}
+
+// Encrypt encrypts value of owner with a new data key, encrypted in turn with the current key of
+// keys. value is returned as it is when keys is nil.
+func Encrypt(keys KeyProvider, value string, owner string) (string, error) {
+	if keys == nil {
+		return value, nil
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+
+	id, masterKey := keys.CurrentKey()
+	encryptedDataKey, err := seal(masterKey, dataKey, []byte(owner))
+	if err != nil {
+		return "", err
+	}
+
+	encryptedValue, err := seal(dataKey, []byte(value), []byte(owner))
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + id + ":" +
+		base64.StdEncoding.EncodeToString(encryptedDataKey) + ":" +
+		base64.StdEncoding.EncodeToString(encryptedValue), nil
+}
+
+// Decrypt returns the value of owner encrypted by Encrypt with any of the keys of keys. Values that
+// are not encrypted are returned as they are, so that they can be encrypted later on.
+func Decrypt(keys KeyProvider, value string, owner string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	if keys == nil {
+		return "", util.ErrEncryptionKeyNotFound
+	}
+
+	additionalData := []byte(owner)
+	encrypted, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		encrypted, additionalData = strings.TrimPrefix(value, encryptedPrefixV1), nil
+	}
+
+	parts := strings.Split(encrypted, ":")
+	if len(parts) != 3 {
+		return "", util.ErrEncryptedValueIsInvalid
+	}
+
+	masterKey, err := keys.Key(parts[0])
+	if err != nil {
+		return "", err
+	}
+
+	encryptedDataKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", util.ErrEncryptedValueIsInvalid
+	}
+
+	encryptedValue, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", util.ErrEncryptedValueIsInvalid
+	}
+
+	dataKey, err := open(masterKey, encryptedDataKey, additionalData)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataKey, encryptedValue, additionalData)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// seal encrypts plaintext with AES-GCM, authenticating additionalData with it, the nonce going
+// before the ciphertext.
+func seal(key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(key []byte, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, util.ErrEncryptedValueIsInvalid
+	}
+
+	plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], additionalData)
+	if err != nil {
+		return nil, util.ErrEncryptedValueIsInvalid
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
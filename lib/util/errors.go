@@ -1,19 +1,164 @@
 package util
 
-import "errors"
+// Errors that do not belong to any entity, mostly the ones of the database driver and the request
+// decoding, translated by the handlers before reporting them, see lib/problem.
+var ErrNotFound = NewError(ErrorKindNotFound, "not_found", "resource does not exist")
+var ErrInvalidID = NewError(ErrorKindValidation, "invalid_id", "id must be a hex string of 24 characters")
+var ErrDuplicateKey = NewError(ErrorKindConflict, "duplicate_key", "resource has a unique value that is already taken")
+var ErrRequestBodyIsInvalid = NewError(ErrorKindValidation, "request_body_is_invalid", "request body is not valid JSON for this request")
+var ErrTenantHeaderIsInvalid = NewError(ErrorKindUnauthorized, "tenant_header_is_invalid", "invalid value in header X-Tenant-ID")
+var ErrTenantNameHeaderIsInvalid = NewError(ErrorKindUnauthorized, "tenant_name_header_is_invalid", "invalid value in header X-Tenant-Name")
+var ErrInvalidCredentials = NewError(ErrorKindUnauthorized, "invalid_credentials", "username or password is not valid")
+var ErrInternal = NewError(ErrorKindInternal, "internal_error", "request could not be completed")
+var ErrValidationFailed = NewError(ErrorKindValidation, "validation_failed", "request has fields that are not valid")
+var ErrIfMatchIsInvalid = NewError(ErrorKindValidation, "if_match_is_invalid", "header If-Match must be * or an ETag returned by the API")
+var ErrPreconditionFailed = NewError(ErrorKindPreconditionFailed, "precondition_failed", "resource has been modified since it was read, get it again to have its current ETag")
 
-var ErrCategoryNameIsEmpty = errors.New("field 'name' of category is empty")
+var ErrCategoryNameIsEmpty = NewError(ErrorKindValidation, "category_name_is_empty", "field 'name' of category is empty")
+var ErrCategoryIDIsEmpty = NewError(ErrorKindValidation, "category_id_is_empty", "category ID is required for update")
+var ErrCategoryIDMismatch = NewError(ErrorKindValidation, "category_id_mismatch", "provided category ID does not match the ID of the category to be updated")
+var ErrCategoryInvalidDeletePolicy = NewError(ErrorKindValidation, "category_invalid_delete_policy", "delete policy must be one of 'block', 'cascade' or 'reassign'")
+var ErrCategoryReassignToIsEmpty = NewError(ErrorKindValidation, "category_reassign_to_is_empty", "field 'reassign_to' is required when delete policy is 'reassign'")
+var ErrCategoryReassignToIsSameCategory = NewError(ErrorKindValidation, "category_reassign_to_is_same_category", "field 'reassign_to' must be different from the category being deleted")
+var ErrCategoryReassignToNotFound = NewError(ErrorKindNotFound, "category_reassign_to_not_found", "category referenced by 'reassign_to' does not exist")
+var ErrCategoryIsInUse = NewError(ErrorKindConflict, "category_is_in_use", "category is still assigned to players or tournaments")
 
-var ErrPlayerGovernmentIDIsEmpty = errors.New("field 'governemnt_id' of player is empty")
-var ErrPlayerEmailIsEmpty = errors.New("field 'email' of player is empty")
-var ErrPlayerFirstNameIsEmpty = errors.New("field 'first_name' of player is empty")
-var ErrPlayerLastNameIsEmpty = errors.New("field 'last_name' of player is empty")
-var ErrPlayerAliasIsEmpty = errors.New("field 'alias' of player is empty")
-var ErrPlayerBirthdateIsEmpty = errors.New("field 'birthdate' of player has not been set")
-var ErrPlayerBirthdateIsFutureDate = errors.New("field 'birthdate' of player has not occurred yet. Is the player comming from the future? :)")
+var ErrPlayerGovernmentIDIsEmpty = NewError(ErrorKindValidation, "player_government_id_is_empty", "field 'governemnt_id' of player is empty")
+var ErrPlayerEmailIsEmpty = NewError(ErrorKindValidation, "player_email_is_empty", "field 'email' of player is empty")
+var ErrPlayerEmailIsInvalid = NewError(ErrorKindValidation, "player_email_is_invalid", "field 'email' of player is not a valid email")
+var ErrPlayerPhoneNumberIsInvalid = NewError(ErrorKindValidation, "player_phone_number_is_invalid", "field 'phone_number' of player must be in E.164 format, e.g. +5491155555555")
+var ErrPlayerCountryIsInvalid = NewError(ErrorKindValidation, "player_country_is_invalid", "field 'country' of player must be an ISO 3166-1 alpha-2 code, e.g. AR")
+var ErrPlayerGovernmentIDIsInvalid = NewError(ErrorKindValidation, "player_government_id_is_invalid", "field 'government_id' of player is not valid for its country")
+var ErrPlayerFirstNameIsEmpty = NewError(ErrorKindValidation, "player_first_name_is_empty", "field 'first_name' of player is empty")
+var ErrPlayerLastNameIsEmpty = NewError(ErrorKindValidation, "player_last_name_is_empty", "field 'last_name' of player is empty")
+var ErrPlayerAliasIsEmpty = NewError(ErrorKindValidation, "player_alias_is_empty", "field 'alias' of player is empty")
+var ErrPlayerBirthdateIsEmpty = NewError(ErrorKindValidation, "player_birthdate_is_empty", "field 'birthdate' of player has not been set")
+var ErrPlayerBirthdateIsFutureDate = NewError(ErrorKindValidation, "player_birthdate_is_future_date", "field 'birthdate' of player has not occurred yet. Is the player comming from the future? :)")
+var ErrPlayerSearchQueryIsEmpty = NewError(ErrorKindValidation, "player_search_query_is_empty", "search query 'q' has no words to search for")
+var ErrPlayerMergeDuplicateIDIsEmpty = NewError(ErrorKindValidation, "player_merge_duplicate_id_is_empty", "field 'duplicate_id' of merge is empty")
+var ErrPlayerMergeWithItself = NewError(ErrorKindValidation, "player_merge_with_itself", "a player cannot be merged with itself")
+var ErrPlayerIDIsEmpty = NewError(ErrorKindValidation, "player_id_is_empty", "player ID is required for update")
+var ErrPlayerIDMismatch = NewError(ErrorKindValidation, "player_id_mismatch", "provided player ID does not match the ID of the player to be updated")
+var ErrPlayerGovernmentIDIsTaken = NewError(ErrorKindConflict, "player_government_id_is_taken", "government_id has already been assigned to another player")
+var ErrPlayerEmailIsTaken = NewError(ErrorKindConflict, "player_email_is_taken", "email has already been assigned to another player")
+var ErrPlayerAliasIsTaken = NewError(ErrorKindConflict, "player_alias_is_taken", "alias has already been assigned to another player")
+var ErrPlayerCategoryIDIsEmpty = NewError(ErrorKindValidation, "player_category_id_is_empty", "field 'category.id' of player is empty")
+var ErrPlayerCategoryNotFound = NewError(ErrorKindNotFound, "player_category_not_found", "category of player does not exist")
+var ErrPlayerImportDryRunIsInvalid = NewError(ErrorKindValidation, "player_import_dry_run_is_invalid", "query parameter 'dry_run' must be true or false")
 
+var ErrImportInvalidFile = NewError(ErrorKindValidation, "import_invalid_file", "import file cannot be read")
+var ErrImportFileIsEmpty = NewError(ErrorKindValidation, "import_file_is_empty", "import file has no header")
+var ErrImportMissingColumn = NewError(ErrorKindValidation, "import_missing_column", "import file is missing a required column")
+var ErrImportUnsupportedFormat = NewError(ErrorKindValidation, "import_unsupported_format", "import file must be CSV (text/csv) or XLSX (application/vnd.openxmlformats-officedocument.spreadsheetml.sheet)")
+var ErrImportInvalidBirthdate = NewError(ErrorKindValidation, "import_invalid_birthdate", "field 'birthdate' of player must be a date as YYYY-MM-DD")
+var ErrImportDuplicatedInFile = NewError(ErrorKindValidation, "import_duplicated_in_file", "another row of the import file has the same value")
+
+var ErrTournamentIDIsEmpty = NewError(ErrorKindValidation, "tournament_id_is_empty", "tournament ID is required for update")
+var ErrTournamentIDMismatch = NewError(ErrorKindValidation, "tournament_id_mismatch", "provided tournament ID does not match the ID of the tournament to be updated")
+var ErrTournamentNameIsEmpty = NewError(ErrorKindValidation, "tournament_name_is_empty", "field 'name' of tournament is empty")
+var ErrTournamentStartDateIsEmpty = NewError(ErrorKindValidation, "tournament_start_date_is_empty", "field 'start_date' of tournament has not been set")
+var ErrTournamentEndDateIsEmpty = NewError(ErrorKindValidation, "tournament_end_date_is_empty", "field 'end_date' of tournament has not been set")
+var ErrTournamentEndDateIsBeforeStartDate = NewError(ErrorKindValidation, "tournament_end_date_is_before_start_date", "field 'end_date' of tournament is before its 'start_date'")
+var ErrTournamentCategoryIDIsEmpty = NewError(ErrorKindValidation, "tournament_category_id_is_empty", "field 'category.id' of tournament is empty")
+var ErrTournamentCategoryNotFound = NewError(ErrorKindNotFound, "tournament_category_not_found", "category of tournament does not exist")
+var ErrTournamentInvalidStatus = NewError(ErrorKindValidation, "tournament_invalid_status", "field 'status' of tournament has an invalid value")
+var ErrTournamentInvalidStatusTransition = NewError(ErrorKindConflict, "tournament_invalid_status_transition", "tournament cannot move from its current status to the requested one")
+var ErrTournamentIsNotEditable = NewError(ErrorKindConflict, "tournament_is_not_editable", "tournament cannot be edited in its current status")
+var ErrTournamentCannotBeDeleted = NewError(ErrorKindConflict, "tournament_cannot_be_deleted", "only draft or cancelled tournaments can be deleted")
+
+var ErrQueryInvalidLimit = NewError(ErrorKindValidation, "query_invalid_limit", "query parameter 'limit' must be a number between 1 and 200")
+var ErrQueryInvalidCursor = NewError(ErrorKindValidation, "query_invalid_cursor", "query parameter 'cursor' is not valid for this list")
+var ErrQueryUnknownFilter = NewError(ErrorKindValidation, "query_unknown_filter", "list cannot be filtered by the given field")
+var ErrQueryUnknownSort = NewError(ErrorKindValidation, "query_unknown_sort", "list cannot be sorted by the given field")
+var ErrQueryInvalidFilterValue = NewError(ErrorKindValidation, "query_invalid_filter_value", "filter has an invalid value")
+
+var ErrExportInvalidFormat = NewError(ErrorKindValidation, "export_invalid_format", "query parameter 'format' must be 'csv' or 'ndjson'")
+
+var ErrTenantNameIsEmpty = NewError(ErrorKindValidation, "tenant_name_is_empty", "field 'name' of tenant is empty")
+var ErrTenantEmailIsEmpty = NewError(ErrorKindValidation, "tenant_email_is_empty", "field 'email' of tenant is empty")
+var ErrTenantEmailIsInvalid = NewError(ErrorKindValidation, "tenant_email_is_invalid", "field 'email' of tenant is not a valid email")
+var ErrTenantPhoneNumberIsInvalid = NewError(ErrorKindValidation, "tenant_phone_number_is_invalid", "field 'phone_number' of tenant must be in E.164 format, e.g. +5491155555555")
+var ErrTenantInvalidTier = NewError(ErrorKindValidation, "tenant_invalid_tier", "field 'tier' of tenant must be 'standard', 'diamond' or 'shared'")
+var ErrTenantAdminGovernmentIDIsEmpty = NewError(ErrorKindValidation, "tenant_admin_government_id_is_empty", "field 'admin.government_id' of tenant is empty")
+var ErrTenantAdminEmailIsInvalid = NewError(ErrorKindValidation, "tenant_admin_email_is_invalid", "field 'admin.email' of tenant is not a valid email")
+var ErrTenantAdminPasswordIsTooShort = NewError(ErrorKindValidation, "tenant_admin_password_is_too_short", "field 'admin.password' of tenant must have at least 8 characters")
+var ErrTenantDatabaseIsNotEmpty = NewError(ErrorKindConflict, "tenant_database_is_not_empty", "database of tenant must be new or empty")
+var ErrTenantLookupIsNotAvailable = NewError(ErrorKindUnavailable, "tenant_lookup_is_not_available", "tenant of the requested host could not be looked up, try again later")
+var ErrTenantDatabaseIsNotAvailable = NewError(ErrorKindUnavailable, "tenant_database_is_not_available", "database of tenant is not available")
+var ErrTenantIDIsEmpty = NewError(ErrorKindValidation, "tenant_id_is_empty", "tenant ID is required for update")
+var ErrTenantIDMismatch = NewError(ErrorKindValidation, "tenant_id_mismatch", "provided tenant ID does not match the ID of the tenant to be updated")
+var ErrTenantDatabaseNameIsNotAllowed = NewError(ErrorKindValidation, "tenant_database_name_is_not_allowed", "field 'database_name' of tenant cannot be set for the 'shared' tier, nor be the database of shared tenants")
+var ErrTenantDatabaseNameIsTaken = NewError(ErrorKindConflict, "tenant_database_name_is_taken", "database_name is already used by another tenant")
+var ErrTenantSlugIsInvalid = NewError(ErrorKindValidation, "tenant_slug_is_invalid", "field 'slug' of tenant must be a domain label, e.g. club-norte, and must be set when 'name' has no letters nor digits")
+var ErrTenantSlugIsTaken = NewError(ErrorKindConflict, "tenant_slug_is_taken", "slug is already used by another tenant")
+var ErrTenantDatabaseNameIsImmutable = NewError(ErrorKindValidation, "tenant_database_name_is_immutable", "field 'database_name' of tenant cannot be changed")
+var ErrTenantSharedTierIsImmutable = NewError(ErrorKindValidation, "tenant_shared_tier_is_immutable", "field 'tier' of tenant cannot move it into or out of the 'shared' tier")
+var ErrTenantIsModified = NewError(ErrorKindConflict, "tenant_is_modified", "tenant has been modified since it was read, get it again and retry")
+var ErrTenantInvalidStatusTransition = NewError(ErrorKindConflict, "tenant_invalid_status_transition", "tenant cannot move from its current status to the requested one")
+var ErrTenantIsSuspended = NewError(ErrorKindForbidden, "tenant_is_suspended", "tenant is suspended, its requests cannot be served until it is reactivated")
+var ErrTenantIsOffboarded = NewError(ErrorKindForbidden, "tenant_is_offboarded", "tenant has been offboarded, its requests cannot be served anymore")
+
+var ErrTenantCustomDomainIsInvalid = NewError(ErrorKindValidation, "tenant_custom_domain_is_invalid", "field 'custom_domains' of tenant must hold domain names only, e.g. club.example.com")
+var ErrTenantCustomDomainNotFound = NewError(ErrorKindNotFound, "tenant_custom_domain_not_found", "tenant does not have the custom domain")
+var ErrTenantCustomDomainIsTaken = NewError(ErrorKindConflict, "tenant_custom_domain_is_taken", "custom domain is already verified by another tenant")
+var ErrTenantCustomDomainIsNotVerified = NewError(ErrorKindConflict, "tenant_custom_domain_is_not_verified", "custom domain could not be verified, its TXT record does not hold the verification token")
+
+var ErrPlanPlayersLimitReached = NewError(ErrorKindPaymentRequired, "plan_players_limit_reached", "tenant has reached the number of players allowed by its plan")
+var ErrPlanTournamentsLimitReached = NewError(ErrorKindPaymentRequired, "plan_tournaments_limit_reached", "tenant has reached the number of tournaments per year allowed by its plan")
+var ErrPlanStorageLimitReached = NewError(ErrorKindPaymentRequired, "plan_storage_limit_reached", "tenant has reached the storage allowed by its plan")
+var ErrPlanAPICallsLimitReached = NewError(ErrorKindTooManyRequests, "plan_api_calls_limit_reached", "tenant has made more requests per minute than allowed by its plan")
+
+var ErrBackupInvalidArchive = NewError(ErrorKindValidation, "backup_invalid_archive", "backup archive is not valid")
+var ErrBackupChecksumMismatch = NewError(ErrorKindValidation, "backup_checksum_mismatch", "backup archive is corrupted, checksum does not match")
+var ErrBackupIncompatible = NewError(ErrorKindUnprocessable, "backup_incompatible", "backup archive is not compatible")
+var ErrBackupTargetIsNotEmpty = NewError(ErrorKindConflict, "backup_target_is_not_empty", "backup can only be restored into a new or empty database")
+
+var ErrEncryptionKeyNotFound = NewError(ErrorKindInternal, "encryption_key_not_found", "encryption key is not known")
+var ErrEncryptionKeyIsInvalid = NewError(ErrorKindInternal, "encryption_key_is_invalid", "encryption keys must be 32 bytes long, base64 encoded, with an id without ':'")
+var ErrEncryptedValueIsInvalid = NewError(ErrorKindInternal, "encrypted_value_is_invalid", "encrypted value is not valid")
+
+var ErrMigrationInvalidDirection = NewError(ErrorKindValidation, "migration_invalid_direction", "migration direction must be 'up' or 'down'")
+var ErrMigrationInvalidTarget = NewError(ErrorKindValidation, "migration_invalid_target", "migration target version does not exist")
+var ErrMigrationUnknownVersion = NewError(ErrorKindConflict, "migration_unknown_version", "database schema version is not known")
+var ErrMigrationDryRunIsInvalid = NewError(ErrorKindValidation, "migration_dry_run_is_invalid", "query parameter 'dry_run' must be true or false")
+
+var ErrIdempotencyKeyIsInvalid = NewError(ErrorKindValidation, "idempotency_key_is_invalid", "header Idempotency-Key must have between 1 and 255 characters")
+var ErrIdempotencyKeyIsReused = NewError(ErrorKindUnprocessable, "idempotency_key_is_reused", "Idempotency-Key has already been used with a different request")
+var ErrIdempotencyKeyIsInProgress = NewError(ErrorKindConflict, "idempotency_key_is_in_progress", "a request with the same Idempotency-Key is still being served")
+
+// ErrorKind tells what went wrong with an AppError, which decides how it is reported, e.g. with
+// which HTTP status.
+type ErrorKind string
+
+const (
+	ErrorKindValidation         ErrorKind = "validation"
+	ErrorKindUnauthorized       ErrorKind = "unauthorized"
+	ErrorKindPaymentRequired    ErrorKind = "payment_required"
+	ErrorKindForbidden          ErrorKind = "forbidden"
+	ErrorKindNotFound           ErrorKind = "not_found"
+	ErrorKindConflict           ErrorKind = "conflict"
+	ErrorKindPreconditionFailed ErrorKind = "precondition_failed"
+	ErrorKindUnprocessable      ErrorKind = "unprocessable"
+	ErrorKindTooManyRequests    ErrorKind = "too_many_requests"
+	ErrorKindUnavailable        ErrorKind = "unavailable"
+	ErrorKindInternal           ErrorKind = "internal"
+)
+
+// AppError is an error of the domain. Code is stable, so clients can rely on it rather than on
+// Message. AppErrors are compared by identity, so they are declared once and wrapped with
+// fmt.Errorf to add details.
 type AppError struct {
-	Message string `json:"message"`
+	Kind    ErrorKind `json:"kind"`
+	Code    string    `json:"code"`
+	Message string    `json:"message"`
+}
+
+func NewError(kind ErrorKind, code string, message string) *AppError {
+	return &AppError{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
 }
 
 func (a *AppError) Error() string {
@@ -0,0 +1,125 @@
+package util
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "csv"
+	ExportFormatNDJSON ExportFormat = "ndjson"
+)
+
+// ParseExportFormat returns the format named by value, which defaults to CSV.
+func ParseExportFormat(value string) (ExportFormat, error) {
+	switch ExportFormat(value) {
+	case "", ExportFormatCSV:
+		return ExportFormatCSV, nil
+	case ExportFormatNDJSON:
+		return ExportFormatNDJSON, nil
+	default:
+		return "", ErrExportInvalidFormat
+	}
+}
+
+func (f ExportFormat) ContentType() string {
+	if f == ExportFormatNDJSON {
+		return "application/x-ndjson"
+	}
+
+	return "text/csv"
+}
+
+// SetExportHeaders makes the response be downloaded as a file named after name and format.
+func SetExportHeaders(w http.ResponseWriter, name string, format ExportFormat) {
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+string(format)))
+}
+
+// Exporter writes items one by one, so that exports never hold more than one item in memory.
+// CSV files start with header and have a line per item as returned by record, while NDJSON files
+// have the JSON of each item on its own line. CSV cells that spreadsheets would run as formulas are
+// written as text, see csvCell.
+type Exporter[T any] struct {
+	format  ExportFormat
+	header  []string
+	record  func(*T) []string
+	csv     *csv.Writer
+	json    *json.Encoder
+	started bool
+}
+
+func NewExporter[T any](w io.Writer, format ExportFormat, header []string, record func(*T) []string) *Exporter[T] {
+	return &Exporter[T]{
+		format: format,
+		header: header,
+		record: record,
+		csv:    csv.NewWriter(w),
+		json:   json.NewEncoder(w),
+	}
+}
+
+func (e *Exporter[T]) Write(item *T) error {
+	if e.format == ExportFormatNDJSON {
+		return e.json.Encode(item)
+	}
+
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	record := e.record(item)
+	for i, cell := range record {
+		record[i] = csvCell(cell)
+	}
+
+	return e.csv.Write(record)
+}
+
+// csvCell prefixes cell with an apostrophe when it starts like a formula, so that spreadsheets show
+// it as it is instead of running it.
+func csvCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@", rune(cell[0])) {
+		return "'" + cell
+	}
+
+	return cell
+}
+
+// UnescapeCSVCell removes the apostrophe csvCell adds, so that exported files can be read back.
+func UnescapeCSVCell(cell string) string {
+	if len(cell) > 1 && cell[0] == '\'' && strings.ContainsRune("=+-@", rune(cell[1])) {
+		return cell[1:]
+	}
+
+	return cell
+}
+
+// Flush writes whatever is pending, including the CSV header when there were no items at all.
+func (e *Exporter[T]) Flush() error {
+	if e.format == ExportFormatNDJSON {
+		return nil
+	}
+
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func (e *Exporter[T]) writeHeader() error {
+	if e.started {
+		return nil
+	}
+
+	e.started = true
+	return e.csv.Write(e.header)
+}
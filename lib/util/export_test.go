@@ -0,0 +1,33 @@
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExporter_Write(t *testing.T) {
+	var b strings.Builder
+	e := NewExporter(&b, ExportFormatCSV, []string{"name", "phone_number"}, func(record *[]string) []string { return *record })
+
+	for _, record := range [][]string{
+		{`=HYPERLINK("http://example.com","Rafa")`, "+5491155555555"},
+		{"-1", "@SUM(A1)"},
+		{"Rafael", ""},
+	} {
+		if err := e.Write(&record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := e.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "name,phone_number\n" +
+		`"'=HYPERLINK(""http://example.com"",""Rafa"")",'+5491155555555` + "\n" +
+		"'-1,'@SUM(A1)\n" +
+		"Rafael,\n"
+	if b.String() != want {
+		t.Errorf("Exporter.Write() wrote %q, want %q", b.String(), want)
+	}
+}
@@ -0,0 +1,222 @@
+package util
+
+import (
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+var countryCode = regexp.MustCompile(`^[A-Z]{2}$`)
+
+var domainLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// IsEmail tells whether value is a bare email address, e.g. "rafa@example.com", without a name.
+func IsEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value
+}
+
+// IsPhoneNumber tells whether value is a phone number in E.164 format, e.g. "+5491155555555".
+// Spaces, dashes, dots and parentheses are allowed to group its digits.
+func IsPhoneNumber(value string) bool {
+	return e164.MatchString(stripSeparators(value, " -.()"))
+}
+
+// IsCountryCode tells whether value is an ISO 3166-1 alpha-2 country code, e.g. "AR".
+func IsCountryCode(value string) bool {
+	return countryCode.MatchString(value)
+}
+
+// IsDomainLabel tells whether value is a single label of a domain name in lower case, e.g. "club-norte".
+func IsDomainLabel(value string) bool {
+	return domainLabel.MatchString(value)
+}
+
+// IsDomainName tells whether value is a domain name in lower case with at least two labels, e.g.
+// "club.example.com".
+func IsDomainName(value string) bool {
+	labels := strings.Split(value, ".")
+	if len(labels) < 2 || len(value) > 253 {
+		return false
+	}
+
+	for _, label := range labels {
+		if !IsDomainLabel(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Slug turns value into a domain label, e.g. "Club Náutico Núñez" into "club-nautico-nunez": it is
+// put in lower case without accents, and every run of other characters than letters and digits
+// becomes a single hyphen. It returns "" when value has no letters nor digits.
+func Slug(value string) string {
+	var b strings.Builder
+	separated := false
+	for _, r := range strings.ToLower(value) {
+		if folded, ok := accents[r]; ok {
+			r = folded
+		}
+
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			separated = true
+			continue
+		}
+
+		if separated && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		separated = false
+		b.WriteRune(r)
+	}
+
+	slug := b.String()
+	if len(slug) > 63 {
+		slug = strings.TrimRight(slug[:63], "-")
+	}
+
+	return slug
+}
+
+// governmentIDs check the government IDs of the countries whose format is known, once their
+// separators have been removed.
+var governmentIDs = map[string]func(id string) bool{
+	"AR": isArgentinianDNI,
+	"BR": isBrazilianCPF,
+	"CL": isChileanRUT,
+	"ES": isSpanishDNI,
+	"US": isSocialSecurityNumber,
+	"UY": isUruguayanCI,
+}
+
+// IsGovernmentID tells whether id is a valid government ID of country. IDs of countries whose
+// format is not known are only required not to be blank.
+func IsGovernmentID(country string, id string) bool {
+	isValid, ok := governmentIDs[country]
+	if !ok {
+		return strings.TrimSpace(id) != ""
+	}
+
+	return isValid(strings.ToUpper(stripSeparators(id, " .-")))
+}
+
+func stripSeparators(value string, separators string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(separators, r) {
+			return -1
+		}
+		return r
+	}, value)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return value != ""
+}
+
+// isArgentinianDNI checks a DNI, 7 or 8 digits, e.g. 30.123.456.
+func isArgentinianDNI(id string) bool {
+	return isDigits(id) && len(id) >= 7 && len(id) <= 8
+}
+
+// isUruguayanCI checks a cédula de identidad, up to 7 digits followed by a check digit, e.g.
+// 1.234.567-2.
+func isUruguayanCI(id string) bool {
+	if !isDigits(id) || len(id) < 7 || len(id) > 8 {
+		return false
+	}
+
+	number := strings.Repeat("0", 8-len(id)) + id[:len(id)-1]
+	sum := 0
+	for i, weight := range []int{2, 9, 8, 7, 6, 3, 4} {
+		sum += int(number[i]-'0') * weight
+	}
+
+	return int(id[len(id)-1]-'0') == (10-sum%10)%10
+}
+
+// isChileanRUT checks a RUT, 7 or 8 digits followed by a check digit or K, e.g. 12.345.678-5.
+func isChileanRUT(id string) bool {
+	if len(id) < 8 || len(id) > 9 || !isDigits(id[:len(id)-1]) {
+		return false
+	}
+
+	sum, weight := 0, 2
+	for i := len(id) - 2; i >= 0; i-- {
+		sum += int(id[i]-'0') * weight
+		weight++
+		if weight > 7 {
+			weight = 2
+		}
+	}
+
+	var check string
+	switch remainder := 11 - sum%11; remainder {
+	case 11:
+		check = "0"
+	case 10:
+		check = "K"
+	default:
+		check = strconv.Itoa(remainder)
+	}
+
+	return id[len(id)-1:] == check
+}
+
+// isBrazilianCPF checks a CPF, 9 digits followed by two check digits, e.g. 529.982.247-25.
+func isBrazilianCPF(id string) bool {
+	if !isDigits(id) || len(id) != 11 || strings.Count(id, id[:1]) == len(id) {
+		return false
+	}
+
+	for _, length := range []int{9, 10} {
+		sum := 0
+		for i := 0; i < length; i++ {
+			sum += int(id[i]-'0') * (length + 1 - i)
+		}
+
+		check := sum * 10 % 11 % 10
+		if int(id[length]-'0') != check {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isSpanishDNI checks a DNI, 8 digits followed by a check letter, or a NIE, whose first digit is
+// replaced by X, Y or Z, e.g. 12345678-Z or X1234567-L.
+func isSpanishDNI(id string) bool {
+	if len(id) != 9 {
+		return false
+	}
+
+	number := strings.NewReplacer("X", "0", "Y", "1", "Z", "2").Replace(id[:1]) + id[1:8]
+	if !isDigits(number) {
+		return false
+	}
+
+	n, _ := strconv.Atoi(number)
+	return id[8] == "TRWAGMYFPDXBNJZSQVHLCKE"[n%23]
+}
+
+// isSocialSecurityNumber checks a SSN, 9 digits without an area of 000, 666 or 9xx, a group of 00
+// or a serial of 0000, e.g. 123-45-6789.
+func isSocialSecurityNumber(id string) bool {
+	if !isDigits(id) || len(id) != 9 {
+		return false
+	}
+
+	area, group, serial := id[:3], id[3:5], id[5:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
@@ -0,0 +1,110 @@
+package util
+
+import "testing"
+
+func TestIsGovernmentID(t *testing.T) {
+	tests := []struct {
+		country string
+		id      string
+		want    bool
+	}{
+		{"AR", "30.123.456", true},
+		{"AR", "30123456789", false},
+		{"UY", "1.234.567-2", true},
+		{"UY", "1.234.567-3", false},
+		{"CL", "12.345.678-5", true},
+		{"CL", "12.345.678-K", false},
+		{"BR", "529.982.247-25", true},
+		{"BR", "111.111.111-11", false},
+		{"ES", "12345678Z", true},
+		{"ES", "X1234567L", true},
+		{"ES", "12345678A", false},
+		{"US", "123-45-6789", true},
+		{"US", "666-45-6789", false},
+		{"FR", "any value", true},
+		{"FR", " ", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsGovernmentID(tt.country, tt.id); got != tt.want {
+			t.Errorf("IsGovernmentID(%v, %v) = %v, want %v", tt.country, tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestIsPhoneNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"+5491155555555", true},
+		{"+54 9 11 5555-5555", true},
+		{"+1 (212) 555-0100", true},
+		{"1155555555", false},
+		{"+0 000 000 000", false},
+		{"+54 11 5555 5555 5555 5", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPhoneNumber(tt.value); got != tt.want {
+			t.Errorf("IsPhoneNumber(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIsEmail(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"rafa@example.com", true},
+		{"Rafa <rafa@example.com>", false},
+		{"rafa", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsEmail(tt.value); got != tt.want {
+			t.Errorf("IsEmail(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIsDomainName(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"club.example.com", true},
+		{"club-norte.example.com", true},
+		{"example", false},
+		{"-club.example.com", false},
+		{"club..example.com", false},
+		{"Club.example.com", false},
+		{"club_norte.example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsDomainName(tt.value); got != tt.want {
+			t.Errorf("IsDomainName(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Club Norte", "club-norte"},
+		{"Club Náutico Núñez", "club-nautico-nunez"},
+		{"  ¡Tenis & Pádel!  ", "tenis-padel"},
+		{"club-norte", "club-norte"},
+		{"¡¡¡", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Slug(tt.value); got != tt.want {
+			t.Errorf("Slug(%v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
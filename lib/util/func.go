@@ -2,10 +2,10 @@ package util
 
 import "time"
 
-func ToPtr[T uint64 | uint32 | string | time.Time](v T) *T {
+func ToPtr[T int64 | uint64 | uint32 | string | time.Time](v T) *T {
 	return &v
 }
 
-func FromPtr[T uint64 | uint32 | string | time.Time](v *T) T {
+func FromPtr[T int64 | uint64 | uint32 | string | time.Time](v *T) T {
 	return *v
 }
@@ -0,0 +1,58 @@
+package util
+
+import "encoding/json"
+
+// PatchField is a field of a JSON merge patch (RFC 7396). Present tells whether the patch has the
+// field at all, in which case Null tells whether it is null, which removes the field, or Value is
+// its new value. Fields left out of the patch are not changed.
+type PatchField[T any] struct {
+	Present bool
+	Null    bool
+	Value   T
+}
+
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Present = true
+	if string(data) == "null" {
+		var zero T
+		f.Null, f.Value = true, zero
+		return nil
+	}
+
+	f.Null = false
+	return json.Unmarshal(data, &f.Value)
+}
+
+// Patch is the partial update of a document made from a merge patch: the fields in Set take their
+// values and the ones in Unset are removed. Fields are named as in the document.
+type Patch struct {
+	Set   map[string]interface{}
+	Unset []string
+}
+
+func NewPatch() *Patch {
+	return &Patch{
+		Set: map[string]interface{}{},
+	}
+}
+
+// IsEmpty tells whether the patch changes nothing.
+func (p *Patch) IsEmpty() bool {
+	return len(p.Set) == 0 && len(p.Unset) == 0
+}
+
+// ApplyPatchField applies field to target, the value it has in the entity being patched, and adds
+// it, named name, to p. Nothing is done when field is not in the merge patch.
+func ApplyPatchField[T any](p *Patch, name string, field PatchField[T], target *T) {
+	if !field.Present {
+		return
+	}
+
+	*target = field.Value
+	if field.Null {
+		p.Unset = append(p.Unset, name)
+		return
+	}
+
+	p.Set[name] = field.Value
+}
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPatchField_UnmarshalJSON(t *testing.T) {
+	var patch struct {
+		Name       PatchField[string]  `json:"name"`
+		MiddleName PatchField[string]  `json:"middle_name"`
+		Alias      PatchField[*string] `json:"alias"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"name":"Rafael","alias":null}`), &patch); err != nil {
+		t.Fatal(err)
+	}
+
+	if !patch.Name.Present || patch.Name.Null || patch.Name.Value != "Rafael" {
+		t.Errorf("Name = %+v", patch.Name)
+	}
+
+	if patch.MiddleName.Present {
+		t.Errorf("MiddleName = %+v, want it not present", patch.MiddleName)
+	}
+
+	if !patch.Alias.Present || !patch.Alias.Null || patch.Alias.Value != nil {
+		t.Errorf("Alias = %+v", patch.Alias)
+	}
+}
+
+func TestApplyPatchField(t *testing.T) {
+	name, middleName, lastName := "Rafa", "Nadal", "Parera"
+
+	p := NewPatch()
+	ApplyPatchField(p, "name", PatchField[string]{Present: true, Value: "Rafael"}, &name)
+	ApplyPatchField(p, "middle_name", PatchField[string]{Present: true, Null: true}, &middleName)
+	ApplyPatchField(p, "last_name", PatchField[string]{}, &lastName)
+
+	if name != "Rafael" || middleName != "" || lastName != "Parera" {
+		t.Errorf("fields = %q, %q, %q", name, middleName, lastName)
+	}
+
+	want := &Patch{Set: map[string]interface{}{"name": "Rafael"}, Unset: []string{"middle_name"}}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("patch = %+v, want %+v", p, want)
+	}
+
+	if p.IsEmpty() || !NewPatch().IsEmpty() {
+		t.Errorf("IsEmpty() is wrong")
+	}
+}
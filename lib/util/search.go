@@ -0,0 +1,126 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+var accents = map[rune]rune{
+	'á': 'a', 'à': 'a', 'ä': 'a', 'â': 'a', 'ã': 'a',
+	'é': 'e', 'è': 'e', 'ë': 'e', 'ê': 'e',
+	'í': 'i', 'ì': 'i', 'ï': 'i', 'î': 'i',
+	'ó': 'o', 'ò': 'o', 'ö': 'o', 'ô': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'ü': 'u', 'û': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
+
+// SearchTerms splits values into lowercase words without accents, so that "Núñez" and
+// "nunez" produce the same term. Duplicated words are returned once.
+func SearchTerms(values ...string) []string {
+	terms := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, value := range values {
+		words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+
+		for _, word := range words {
+			term := strings.Map(func(r rune) rune {
+				if folded, ok := accents[r]; ok {
+					return folded
+				}
+				return r
+			}, word)
+
+			if !seen[term] {
+				seen[term] = true
+				terms = append(terms, term)
+			}
+		}
+	}
+
+	return terms
+}
+
+// SearchScore ranks how well terms match every word of query (both as returned by SearchTerms).
+// A word scores more when it is equal to a term than when it is a prefix of it, and less when it
+// only matches with typos. It returns 0 when any word of query does not match at all.
+func SearchScore(query []string, terms []string) float64 {
+	score := 0.0
+	for _, word := range query {
+		best := 0.0
+		for _, term := range terms {
+			best = max(best, wordScore(word, term))
+		}
+
+		if best == 0 {
+			return 0
+		}
+
+		score += best
+	}
+
+	return score
+}
+
+func wordScore(word string, term string) float64 {
+	if word == term {
+		return 1
+	}
+
+	if strings.HasPrefix(term, word) {
+		return 0.8
+	}
+
+	w, t := []rune(word), []rune(term)
+	typos := allowedTypos(len(w))
+	if typos == 0 {
+		return 0
+	}
+
+	if d := levenshtein(w, t); d <= typos {
+		return 0.6 - 0.1*float64(d)
+	}
+
+	// The word might be an incomplete term that has typos as well
+	if len(t) > len(w) {
+		if d := levenshtein(w, t[:len(w)]); d <= typos {
+			return 0.4 - 0.1*float64(d)
+		}
+	}
+
+	return 0
+}
+
+func allowedTypos(length int) int {
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
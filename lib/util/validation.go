@@ -0,0 +1,73 @@
+package util
+
+import "strings"
+
+// FieldError tells why a field of a request is not valid. Field is its path in the JSON of the
+// request, e.g. "admin.email" or "custom_domains[1]".
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+
+	err *AppError
+}
+
+// ValidationError holds every field of a request that is not valid, so that all of them are
+// reported at once. It wraps ErrValidationFailed and the AppErrors of its fields, so errors.Is
+// tells whether a given field failed.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldError := range e.Errors {
+		messages = append(messages, fieldError.Message)
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors)+1)
+	errs = append(errs, ErrValidationFailed)
+	for _, fieldError := range e.Errors {
+		errs = append(errs, fieldError.err)
+	}
+
+	return errs
+}
+
+// Validator collects the errors of the fields of a request:
+//
+//	var v util.Validator
+//	v.Check(r.Name != "", "name", util.ErrCategoryNameIsEmpty)
+//	return v.Err()
+type Validator struct {
+	errors []FieldError
+}
+
+// Check adds err for field unless ok.
+func (v *Validator) Check(ok bool, field string, err *AppError) {
+	if !ok {
+		v.Add(field, err)
+	}
+}
+
+func (v *Validator) Add(field string, err *AppError) {
+	v.errors = append(v.errors, FieldError{Field: field, Code: err.Code, Message: err.Message, err: err})
+}
+
+// Valid tells whether no errors have been added.
+func (v *Validator) Valid() bool {
+	return len(v.errors) == 0
+}
+
+// Err returns a *ValidationError with the errors added, nil when there are none.
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+
+	return &ValidationError{Errors: v.errors}
+}
@@ -0,0 +1,35 @@
+package util
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidator_Err(t *testing.T) {
+	var v Validator
+	if err := v.Err(); err != nil {
+		t.Fatalf("Err() = %v, want nil", err)
+	}
+
+	v.Check(true, "name", ErrCategoryNameIsEmpty)
+	v.Check(false, "email", ErrPlayerEmailIsEmpty)
+	v.Add("phone_number", ErrPlayerPhoneNumberIsInvalid)
+
+	err := v.Err()
+	if !errors.Is(err, ErrValidationFailed) || !errors.Is(err, ErrPlayerEmailIsEmpty) || !errors.Is(err, ErrPlayerPhoneNumberIsInvalid) {
+		t.Errorf("Err() = %v, want it to wrap the errors of its fields", err)
+	}
+
+	if errors.Is(err, ErrCategoryNameIsEmpty) {
+		t.Errorf("Err() = %v, want it not to wrap the errors of valid fields", err)
+	}
+
+	var validationError *ValidationError
+	if !errors.As(err, &validationError) || len(validationError.Errors) != 2 {
+		t.Fatalf("Err() = %v, want two field errors", err)
+	}
+
+	if got := validationError.Errors[1]; got.Field != "phone_number" || got.Code != "player_phone_number_is_invalid" {
+		t.Errorf("Errors[1] = %+v", got)
+	}
+}
@@ -0,0 +1,11 @@
+package util
+
+// CheckVersion returns ErrPreconditionFailed when expected, the version a client based its write
+// on, is set and is not version, the current one of the resource.
+func CheckVersion(expected *int64, version int64) error {
+	if expected != nil && *expected != version {
+		return ErrPreconditionFailed
+	}
+
+	return nil
+}
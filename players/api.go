@@ -1,21 +1,28 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
-	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
 
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/Neniel/gotennis/players/usecase"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/Neniel/gotennis/lib/app"
-	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
+	"github.com/Neniel/gotennis/lib/etag"
 	"github.com/Neniel/gotennis/lib/log"
 	"github.com/Neniel/gotennis/lib/middleware"
+	"github.com/Neniel/gotennis/lib/problem"
 	"github.com/Neniel/gotennis/lib/telemetry/grafana"
+	"github.com/Neniel/gotennis/lib/util"
 )
 
 type Usecases struct {
@@ -26,6 +33,12 @@ type Usecases struct {
 	UpdatePlayer          usecase.UpdatePlayer
 	PartiallyUpdatePlayer usecase.PartialltUpdatePlayer
 	DeletePlayer          usecase.DeletePlayer
+	SearchPlayers         usecase.SearchPlayers
+	FindPlayerDuplicates  usecase.FindPlayerDuplicates
+	MergePlayers          usecase.MergePlayers
+	ImportPlayers         usecase.ImportPlayers
+	ExportPlayers         usecase.ExportPlayers
+	RestorePlayer         usecase.RestorePlayer
 }
 
 type PlayerMicroservice struct {
@@ -54,16 +67,26 @@ func (api *APIServer) Run() {
 
 	mux.HandleFunc("GET /ping", api.pingHandler)
 	mux.HandleFunc("GET /players", api.listPlayers)
+	mux.HandleFunc("GET /players/export", api.exportPlayers)
+	mux.HandleFunc("GET /players/search", api.searchPlayers)
 	mux.HandleFunc("GET /players/{id}", api.getPlayer)
-	mux.HandleFunc("POST /players", api.addPlayer)
+	mux.Handle("POST /players", api.PlayerMicroservice.App.GetIdempotency().Middleware(http.HandlerFunc(api.addPlayer)))
+	mux.HandleFunc("POST /players/import", api.importPlayers)
 	mux.HandleFunc("PUT /players/{id}", api.updatePlayer)
 	mux.HandleFunc("PATCH /players/{id}", api.partiallyUpdatePlayer)
 	mux.HandleFunc("DELETE /players/{id}", api.deletePlayer)
+	mux.HandleFunc("POST /players/{id}/restore", api.restorePlayer)
+	mux.HandleFunc("GET /players/{id}/duplicates", api.findPlayerDuplicates)
+	mux.HandleFunc("POST /players/{id}/merge", api.mergePlayers)
 
 	log.Logger.Error(
 		http.ListenAndServe(
 			os.Getenv("APP_PORT"),
-			middleware.CORSMiddleware(mux),
+			middleware.CORSMiddleware(
+				api.PlayerMicroservice.App.GetTenantResolver().Middleware(
+					middleware.TenantMiddleware(middleware.RateLimitMiddleware(mux, api.PlayerMicroservice.App.APICallsPerMinute), api.PlayerMicroservice.App.CheckTenant),
+				),
+			),
 		).Error())
 }
 
@@ -76,6 +99,13 @@ func (api *APIServer) pingHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (api *APIServer) listPlayers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	q, err := query.Parse(r.URL.Query(), usecase.PlayersQuerySchema)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
 	/*
 	   1. recibir el token
 	   2. validar el token
@@ -86,19 +116,61 @@ func (api *APIServer) listPlayers(w http.ResponseWriter, r *http.Request) {
 
 	client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
+		problem.Write(w, r, err)
 		return
 	}
 
-	listPlayers := usecase.NewListPlayers(database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+	listPlayers := usecase.NewListPlayers(client.DBReader())
 
-	categories, err := listPlayers.Do(r.Context())
+	players, err := listPlayers.Do(r.Context(), q)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	query.SetPaginationHeaders(w, r, players.NextCursor)
+	err = json.NewEncoder(w).Encode(&players.Items)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+}
+
+func (api *APIServer) searchPlayers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		l, err := strconv.Atoi(value)
+		if err != nil || l <= 0 || int64(l) > query.MaxLimit {
+			problem.Write(w, r, util.ErrQueryInvalidLimit)
+			return
+		}
+		limit = l
+	}
+
+	/*
+	   1. recibir el token
+	   2. validar el token
+	   3. obtener datos del token
+	*/
+
+	tenantID := r.Header.Get("X-Tenant-ID")
+
+	client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
 
-	err = json.NewEncoder(w).Encode(&categories)
+	searchPlayers := usecase.NewSearchPlayers(client.DBReader())
+
+	players, err := searchPlayers.Do(r.Context(), r.URL.Query().Get("q"), limit)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(&players)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -106,6 +178,7 @@ func (api *APIServer) listPlayers(w http.ResponseWriter, r *http.Request) {
 }
 
 func (api *APIServer) getPlayer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
 	if categoryId := r.PathValue("id"); categoryId != "" {
 
 		/*
@@ -118,29 +191,22 @@ func (api *APIServer) getPlayer(w http.ResponseWriter, r *http.Request) {
 
 		client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
+			problem.Write(w, r, err)
 			return
 		}
 
-		getPlayer := usecase.NewGetPlayer(database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+		getPlayer := usecase.NewGetPlayer(client.DBReader())
 
 		categories, err := getPlayer.Do(r.Context(), categoryId)
-		if errors.Is(err, primitive.ErrInvalidHex) {
-			w.WriteHeader(http.StatusBadRequest)
-			grafana.SendMetric("get.player", 1, 1, map[string]interface{}{
-				"status_code": http.StatusBadRequest,
-			})
-			return
-		}
-
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			w.WriteHeader(http.StatusNotFound)
+		if err != nil {
+			problem.Write(w, r, err)
 			grafana.SendMetric("get.player", 1, 1, map[string]interface{}{
-				"status_code": http.NotFound,
+				"status_code": problem.StatusCode(err),
 			})
 			return
 		}
 
+		etag.Set(w, categories.Version)
 		err = json.NewEncoder(w).Encode(&categories)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -157,12 +223,12 @@ func (api *APIServer) getPlayer(w http.ResponseWriter, r *http.Request) {
 }
 
 func (api *APIServer) addPlayer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
 	var request usecase.CreatePlayerRequest
 	defer r.Body.Close()
 	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -176,20 +242,19 @@ func (api *APIServer) addPlayer(w http.ResponseWriter, r *http.Request) {
 
 	client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte("invalid value in header X-Tenant-ID"))
+		problem.Write(w, r, err)
 		return
 	}
 
 	createPlayer := usecase.NewCreatePlayer(
-		database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName),
-		database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName),
+		client.DBWriter(),
+		client.DBReader(),
+		client.Plan,
 	)
 
 	player, err := createPlayer.Do(r.Context(), &request)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -197,19 +262,24 @@ func (api *APIServer) addPlayer(w http.ResponseWriter, r *http.Request) {
 	err = json.NewEncoder(w).Encode(&player)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
 		return
 	}
 }
 
 func (api *APIServer) updatePlayer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
 	if id := r.PathValue("id"); id != "" {
 		var request usecase.UpdatePlayerRequest
 		defer r.Body.Close()
 		err := json.NewDecoder(r.Body).Decode(&request)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
+			return
+		}
+
+		request.Version, err = etag.IfMatch(r)
+		if err != nil {
+			problem.Write(w, r, err)
 			return
 		}
 
@@ -223,38 +293,42 @@ func (api *APIServer) updatePlayer(w http.ResponseWriter, r *http.Request) {
 
 		client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("invalid value in header X-Tenant-ID"))
+			problem.Write(w, r, err)
 			return
 		}
 
-		updatePlayer := usecase.NewUpdatePlayer(database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName), database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+		updatePlayer := usecase.NewUpdatePlayer(client.DBWriter(), client.DBReader())
 
 		category, err := updatePlayer.Do(r.Context(), id, &request)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
 			return
 		}
 
+		etag.Set(w, category.Version)
 		w.WriteHeader(http.StatusOK)
 		err = json.NewEncoder(w).Encode(&category)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(err.Error()))
 			return
 		}
 	}
 }
 
 func (api *APIServer) partiallyUpdatePlayer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
 	if id := r.PathValue("id"); id != "" {
 		var request usecase.PartiallyUpdatePlayerRequest
 		defer r.Body.Close()
 		err := json.NewDecoder(r.Body).Decode(&request)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
+			return
+		}
+
+		request.Version, err = etag.IfMatch(r)
+		if err != nil {
+			problem.Write(w, r, err)
 			return
 		}
 
@@ -268,25 +342,23 @@ func (api *APIServer) partiallyUpdatePlayer(w http.ResponseWriter, r *http.Reque
 
 		client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("invalid value in header X-Tenant-ID"))
+			problem.Write(w, r, err)
 			return
 		}
 
-		partiallyUpdatePlayer := usecase.NewPartiallyUpdatePlayer(database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName), database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+		partiallyUpdatePlayer := usecase.NewPartiallyUpdatePlayer(client.DBWriter(), client.DBReader())
 
 		player, err := partiallyUpdatePlayer.Do(r.Context(), id, &request)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
 			return
 		}
 
+		etag.Set(w, player.Version)
 		w.WriteHeader(http.StatusOK)
 		err = json.NewEncoder(w).Encode(&player)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(err.Error()))
 			return
 		}
 	}
@@ -305,22 +377,264 @@ func (api *APIServer) deletePlayer(w http.ResponseWriter, r *http.Request) {
 
 		client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("invalid value in header X-Tenant-ID"))
+			problem.Write(w, r, err)
 			return
 		}
 
-		deletePlayer := usecase.NewDeletePlayer(database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName))
-		err = deletePlayer.Do(r.Context(), id)
-		if errors.Is(err, primitive.ErrInvalidHex) {
-			w.WriteHeader(http.StatusBadRequest)
+		version, err := etag.IfMatch(r)
+		if err != nil {
+			problem.Write(w, r, err)
 			return
-		} else {
+		}
+
+		deletePlayer := usecase.NewDeletePlayer(client.DBWriter())
+		err = deletePlayer.Do(r.Context(), id, version, r.Header.Get("X-User-ID"))
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		problem.Write(w, r, util.ErrInvalidID)
+		return
+	}
+}
+
+func (api *APIServer) restorePlayer(w http.ResponseWriter, r *http.Request) {
+	if id := r.PathValue("id"); id != "" {
+
+		/*
+		   1. recibir el token
+		   2. validar el token
+		   3. obtener datos del token
+		*/
+
+		tenantID := r.Header.Get("X-Tenant-ID")
+
+		client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		restorePlayer := usecase.NewRestorePlayer(client.DBWriter(), client.DBReader(), client.Plan)
+		err = restorePlayer.Do(r.Context(), id)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		problem.Write(w, r, util.ErrInvalidID)
+		return
+	}
+}
+
+func (api *APIServer) findPlayerDuplicates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+
+		/*
+		   1. recibir el token
+		   2. validar el token
+		   3. obtener datos del token
+		*/
+
+		tenantID := r.Header.Get("X-Tenant-ID")
+
+		client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		findPlayerDuplicates := usecase.NewFindPlayerDuplicates(client.DBReader())
+		candidates, err := findPlayerDuplicates.Do(r.Context(), id)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(&candidates)
+		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 	} else {
-		w.WriteHeader(http.StatusBadRequest)
+		problem.Write(w, r, util.ErrInvalidID)
 		return
 	}
 }
+
+func (api *APIServer) mergePlayers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+		var request usecase.MergePlayersRequest
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		request.MergedBy = r.Header.Get("X-User-ID")
+
+		/*
+		   1. recibir el token
+		   2. validar el token
+		   3. obtener datos del token
+		*/
+
+		tenantID := r.Header.Get("X-Tenant-ID")
+
+		client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		mergePlayers := usecase.NewMergePlayers(client.DBWriter(), client.DBReader())
+		player, err := mergePlayers.Do(r.Context(), id, &request)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		etag.Set(w, player.Version)
+		err = json.NewEncoder(w).Encode(&player)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	} else {
+		problem.Write(w, r, util.ErrInvalidID)
+		return
+	}
+}
+
+const (
+	maxImportFileSize = 10 << 20
+	csvMediaType      = "text/csv"
+	xlsxMediaType     = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// importPlayers accepts the file either as the request body or as the "file" field of a form,
+// and tells CSV from XLSX by its content type or, for forms, by its extension too.
+func (api *APIServer) importPlayers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	dryRun, err := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	if err != nil && r.URL.Query().Has("dry_run") {
+		problem.Write(w, r, util.ErrPlayerImportDryRunIsInvalid)
+		return
+	}
+
+	rows, err := readImportFile(w, r)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	/*
+	   1. recibir el token
+	   2. validar el token
+	   3. obtener datos del token
+	*/
+
+	tenantID := r.Header.Get("X-Tenant-ID")
+
+	client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	importPlayers := usecase.NewImportPlayers(client.DBWriter(), client.DBReader(), client.Plan)
+	result, err := importPlayers.Do(r.Context(), rows, dryRun)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(&result)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+func readImportFile(w http.ResponseWriter, r *http.Request) ([]usecase.ImportPlayerRow, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileSize)
+	defer r.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var body io.Reader = r.Body
+	if mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", util.ErrImportInvalidFile, err)
+		}
+		defer file.Close()
+
+		body = file
+		mediaType, _, _ = mime.ParseMediaType(header.Header.Get("Content-Type"))
+		switch strings.ToLower(path.Ext(header.Filename)) {
+		case ".csv":
+			mediaType = csvMediaType
+		case ".xlsx":
+			mediaType = xlsxMediaType
+		}
+	}
+
+	switch mediaType {
+	case csvMediaType:
+		return usecase.ReadPlayersCSV(body)
+	case xlsxMediaType:
+		content, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", util.ErrImportInvalidFile, err)
+		}
+		return usecase.ReadPlayersXLSX(bytes.NewReader(content), int64(len(content)))
+	default:
+		return nil, util.ErrImportUnsupportedFormat
+	}
+}
+
+func (api *APIServer) exportPlayers(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	format, err := util.ParseExportFormat(values.Get("format"))
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	values.Del("format")
+
+	q, err := query.Parse(values, usecase.PlayersQuerySchema)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	/*
+	   1. recibir el token
+	   2. validar el token
+	   3. obtener datos del token
+	*/
+
+	tenantID := r.Header.Get("X-Tenant-ID")
+
+	client, err := api.PlayerMicroservice.App.GetTenantMongoDBClient(tenantID)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	exportPlayers := usecase.NewExportPlayers(client.DBReader())
+
+	// The response is streamed, so once it has started an error can only be logged
+	util.SetExportHeaders(w, "players", format)
+	if err := exportPlayers.Do(r.Context(), w, format, q); err != nil {
+		log.Logger.Error(fmt.Errorf("couldn't export players: %w", err).Error())
+	}
+}
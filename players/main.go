@@ -22,5 +22,8 @@ func main() {
 		*/
 	}
 
+	go app.StartPurge(context.Background(), "players", "users")
+	go app.WatchTenants(context.Background())
+
 	ms.NewAPIServer().Run()
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/quota"
 	"github.com/Neniel/gotennis/lib/entity"
 	"github.com/Neniel/gotennis/lib/log"
 	"github.com/Neniel/gotennis/lib/util"
@@ -13,6 +14,7 @@ import (
 
 type CreatePlayerRequest struct {
 	GovernmentID string           `json:"government_id"`
+	Country      string           `json:"country"`
 	FirstName    string           `json:"first_name"`
 	MiddleName   string           `json:"middle_name"`
 	LastName     string           `json:"last_name"`
@@ -24,35 +26,40 @@ type CreatePlayerRequest struct {
 }
 
 func (r *CreatePlayerRequest) Validate() error {
-	if r.GovernmentID == "" {
-		return util.ErrPlayerGovernmentIDIsEmpty
+	if r.Alias != nil && *r.Alias == "" {
+		r.Alias = nil
 	}
 
-	if r.Email == "" {
-		return util.ErrPlayerEmailIsEmpty
-	}
+	var v util.Validator
+	validatePlayerFields(&v, r.GovernmentID, r.Country, r.FirstName, r.LastName, r.Email, r.PhoneNumber, r.Birthdate)
+	return v.Err()
+}
 
-	if r.FirstName == "" {
-		return util.ErrPlayerFirstNameIsEmpty
+// validatePlayerFields checks the fields every player must have, adding the ones that are not
+// valid to v.
+func validatePlayerFields(v *util.Validator, governmentID string, country string, firstName string, lastName string, email string, phoneNumber string, birthdate *time.Time) {
+	if governmentID == "" {
+		v.Add("government_id", util.ErrPlayerGovernmentIDIsEmpty)
+	} else if util.IsCountryCode(country) {
+		v.Check(util.IsGovernmentID(country, governmentID), "government_id", util.ErrPlayerGovernmentIDIsInvalid)
 	}
 
-	if r.LastName == "" {
-		return util.ErrPlayerLastNameIsEmpty
-	}
+	v.Check(country == "" || util.IsCountryCode(country), "country", util.ErrPlayerCountryIsInvalid)
 
-	if r.Birthdate != nil && r.Birthdate.IsZero() {
-		return util.ErrPlayerBirthdateIsEmpty
+	if email == "" {
+		v.Add("email", util.ErrPlayerEmailIsEmpty)
+	} else {
+		v.Check(util.IsEmail(email), "email", util.ErrPlayerEmailIsInvalid)
 	}
 
-	if r.Birthdate != nil && r.Birthdate.After(time.Now().UTC()) {
-		return util.ErrPlayerBirthdateIsFutureDate
-	}
+	v.Check(firstName != "", "first_name", util.ErrPlayerFirstNameIsEmpty)
+	v.Check(lastName != "", "last_name", util.ErrPlayerLastNameIsEmpty)
+	v.Check(phoneNumber == "" || util.IsPhoneNumber(phoneNumber), "phone_number", util.ErrPlayerPhoneNumberIsInvalid)
 
-	if r.Alias != nil && *r.Alias == "" {
-		r.Alias = nil
+	if birthdate != nil {
+		v.Check(!birthdate.IsZero(), "birthdate", util.ErrPlayerBirthdateIsEmpty)
+		v.Check(!birthdate.After(time.Now().UTC()), "birthdate", util.ErrPlayerBirthdateIsFutureDate)
 	}
-
-	return nil
 }
 
 type CreatePlayer interface {
@@ -68,11 +75,13 @@ type internalCreatePlayer struct {
 type createPlayer struct {
 	*internalCreatePlayer
 	DBWriter database.DBWriter
+	Quota    *quota.Checker
 }
 
-func NewCreatePlayer(dbWriter database.DBWriter, dbReader database.DBReader) CreatePlayer {
+func NewCreatePlayer(dbWriter database.DBWriter, dbReader database.DBReader, plan entity.Plan) CreatePlayer {
 	return &createPlayer{
 		DBWriter: dbWriter,
+		Quota:    quota.NewChecker(dbReader, plan),
 		internalCreatePlayer: &internalCreatePlayer{
 			ValidateGovernmentID: NewValidateGovernmentIDUsecase(dbReader),
 			ValidateEmail:        NewValidateEmailUsecase(dbReader),
@@ -95,7 +104,7 @@ func (uc *createPlayer) Do(ctx context.Context, request *CreatePlayerRequest) (*
 
 	if !isAvailableGovernmentID {
 		log.Logger.Error(fmt.Errorf("couldn't create player. There is another player registered with the provided government ID").Error())
-		return nil, fmt.Errorf("couldn't create player. There is another player registered with the provided government ID")
+		return nil, util.ErrPlayerGovernmentIDIsTaken
 	}
 
 	isAvailableEmail, err := uc.internalCreatePlayer.ValidateEmail.IsAvailable(ctx, request.Email)
@@ -106,7 +115,7 @@ func (uc *createPlayer) Do(ctx context.Context, request *CreatePlayerRequest) (*
 
 	if !isAvailableEmail {
 		log.Logger.Error(fmt.Errorf("couldn't create player. There is another player registered with the provided email").Error())
-		return nil, fmt.Errorf("couldn't create player. There is another player registered with the provided email")
+		return nil, util.ErrPlayerEmailIsTaken
 	}
 
 	isAvailableAlias, err := uc.internalCreatePlayer.ValidateAlias.IsAvailable(ctx, request.Alias)
@@ -117,11 +126,17 @@ func (uc *createPlayer) Do(ctx context.Context, request *CreatePlayerRequest) (*
 
 	if !isAvailableAlias {
 		log.Logger.Error(fmt.Errorf("couldn't create player. There is another player registered with the provided alias").Error())
-		return nil, fmt.Errorf("couldn't create player. There is another player registered with the provided alias")
+		return nil, util.ErrPlayerAliasIsTaken
+	}
+
+	if err := uc.Quota.CheckPlayers(ctx, 1); err != nil {
+		log.Logger.Info(fmt.Errorf("couldn't create player: %w", err).Error())
+		return nil, err
 	}
 
 	newPlayer := entity.NewPlayer(
 		request.GovernmentID,
+		request.Country,
 		request.FirstName,
 		request.MiddleName,
 		request.LastName,
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/quota"
 	"github.com/Neniel/gotennis/lib/entity"
 	"github.com/Neniel/gotennis/lib/util"
 	"go.uber.org/mock/gomock"
@@ -87,6 +88,7 @@ func Test_createPlayerUsecase_CreatePlayer_Success(t *testing.T) {
 			uc := &createPlayer{
 				internalCreatePlayer: tt.fields.internalCreatePlayerUsecases,
 				DBWriter:             tt.fields.DBWriter,
+				Quota:                quota.NewChecker(dbReaderMock, entity.Plan{}),
 			}
 			got, err := uc.Do(tt.args.ctx, tt.args.request)
 			if (err != nil) != tt.wantErr {
@@ -432,6 +434,7 @@ func Test_createPlayerUsecase_CreatePlayer_Failure(t *testing.T) {
 			uc := &createPlayer{
 				internalCreatePlayer: tt.fields.internalCreatePlayerUsecases,
 				DBWriter:             tt.fields.DBWriter,
+				Quota:                quota.NewChecker(dbReaderMock, entity.Plan{}),
 			}
 			got, err := uc.Do(tt.args.ctx, tt.args.request)
 			if (err != nil) != tt.wantErr {
@@ -471,12 +474,13 @@ func TestNewCreatePlayerUsecase(t *testing.T) {
 					ValidateAlias:        NewValidateAliasUsecase(dbReader),
 				},
 				DBWriter: dbWriter,
+				Quota:    quota.NewChecker(dbReader, entity.Plan{}),
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := NewCreatePlayer(tt.args.dbWriter, tt.args.dbReader); !reflect.DeepEqual(got, tt.want) {
+			if got := NewCreatePlayer(tt.args.dbWriter, tt.args.dbReader, entity.Plan{}); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("NewCreatePlayerUsecase() = %v, want %v", got, tt.want)
 			}
 		})
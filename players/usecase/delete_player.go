@@ -7,7 +7,7 @@ import (
 )
 
 type DeletePlayer interface {
-	Do(ctx context.Context, id string) error
+	Do(ctx context.Context, id string, version *int64, deletedBy string) error
 }
 
 type deletePlayer struct {
@@ -20,6 +20,6 @@ func NewDeletePlayer(dbWriter database.DBWriter) DeletePlayer {
 	}
 }
 
-func (uc *deletePlayer) Do(ctx context.Context, id string) error {
-	return uc.DBWriter.DeletePlayer(context.Background(), id)
+func (uc *deletePlayer) Do(ctx context.Context, id string, version *int64, deletedBy string) error {
+	return uc.DBWriter.DeletePlayer(ctx, id, version, deletedBy)
 }
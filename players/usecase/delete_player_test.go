@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/util"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.uber.org/mock/gomock"
 )
@@ -48,8 +49,10 @@ func Test_deletePlayerUsecase_Delete(t *testing.T) {
 		DBWriter database.DBWriter
 	}
 	type args struct {
-		ctx context.Context
-		id  string
+		ctx       context.Context
+		id        string
+		version   *int64
+		deletedBy string
 	}
 	tests := []struct {
 		name           string
@@ -64,25 +67,43 @@ func Test_deletePlayerUsecase_Delete(t *testing.T) {
 				DBWriter: dbWriter,
 			},
 			args: args{
-				ctx: context.Background(),
-				id:  id.Hex(),
+				ctx:       context.Background(),
+				id:        id.Hex(),
+				deletedBy: "admin",
 			},
 			prepareUsecase: func() {
-				dbWriter.EXPECT().DeletePlayer(gomock.Any(), id.Hex()).Return(nil)
+				dbWriter.EXPECT().DeletePlayer(gomock.Any(), id.Hex(), nil, "admin").Return(nil)
 			},
 			wantErr: false,
 		},
+		{
+			name: "Delete_if_version_matches",
+			fields: fields{
+				DBWriter: dbWriter,
+			},
+			args: args{
+				ctx:       context.Background(),
+				id:        id.Hex(),
+				version:   util.ToPtr(int64(2)),
+				deletedBy: "admin",
+			},
+			prepareUsecase: func() {
+				dbWriter.EXPECT().DeletePlayer(gomock.Any(), id.Hex(), util.ToPtr(int64(2)), "admin").Return(util.ErrPreconditionFailed)
+			},
+			wantErr: true,
+		},
 		{
 			name: "Delete_fails",
 			fields: fields{
 				DBWriter: dbWriter,
 			},
 			args: args{
-				ctx: context.Background(),
-				id:  id.Hex(),
+				ctx:       context.Background(),
+				id:        id.Hex(),
+				deletedBy: "admin",
 			},
 			prepareUsecase: func() {
-				dbWriter.EXPECT().DeletePlayer(gomock.Any(), id.Hex()).Return(errors.New("error when deleting user"))
+				dbWriter.EXPECT().DeletePlayer(gomock.Any(), id.Hex(), nil, "admin").Return(errors.New("error when deleting user"))
 			},
 			wantErr: true,
 		},
@@ -93,7 +114,7 @@ func Test_deletePlayerUsecase_Delete(t *testing.T) {
 			uc := &deletePlayer{
 				DBWriter: tt.fields.DBWriter,
 			}
-			if err := uc.Do(tt.args.ctx, tt.args.id); (err != nil) != tt.wantErr {
+			if err := uc.Do(tt.args.ctx, tt.args.id, tt.args.version, tt.args.deletedBy); (err != nil) != tt.wantErr {
 				t.Errorf("deletePlayerUsecase.Delete() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
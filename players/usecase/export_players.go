@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+var playersExportHeader = []string{"id", "government_id", "country", "first_name", "middle_name", "last_name", "birthdate", "phone_number", "email", "alias", "category_id", "category_name", "created_at"}
+
+type ExportPlayers interface {
+	Do(ctx context.Context, w io.Writer, format util.ExportFormat, q *query.Query) error
+}
+
+type exportPlayers struct {
+	DBReader database.DBReader
+}
+
+func NewExportPlayers(dbReader database.DBReader) ExportPlayers {
+	return &exportPlayers{
+		DBReader: dbReader,
+	}
+}
+
+// Do writes to w, in the given format, the players matching the filters and sort of q.
+func (uc *exportPlayers) Do(ctx context.Context, w io.Writer, format util.ExportFormat, q *query.Query) error {
+	exporter := util.NewExporter(w, format, playersExportHeader, playerRecord)
+
+	if err := uc.DBReader.StreamPlayers(ctx, q, exporter.Write); err != nil {
+		log.Logger.Error(err.Error())
+		return err
+	}
+
+	return exporter.Flush()
+}
+
+func playerRecord(p *entity.Player) []string {
+	var birthdate, alias, categoryID, categoryName string
+	if p.Birthdate != nil {
+		birthdate = p.Birthdate.Format(time.DateOnly)
+	}
+
+	if p.Alias != nil {
+		alias = *p.Alias
+	}
+
+	if p.Category != nil {
+		categoryID, categoryName = p.Category.ID.Hex(), p.Category.Name
+	}
+
+	return []string{
+		p.ID.Hex(),
+		p.GovernmentID,
+		p.Country,
+		p.FirstName,
+		p.MiddleName,
+		p.LastName,
+		birthdate,
+		p.PhoneNumber,
+		p.Email,
+		alias,
+		categoryID,
+		categoryName,
+		p.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+const (
+	DuplicateReasonName        = "name"
+	DuplicateReasonBirthdate   = "birthdate"
+	DuplicateReasonPhoneNumber = "phone_number"
+
+	// MinDuplicateScore is the score from which a player is reported as a possible duplicate
+	MinDuplicateScore = 0.5
+)
+
+type DuplicateCandidate struct {
+	Player  entity.Player `json:"player"`
+	Score   float64       `json:"score"`
+	Reasons []string      `json:"reasons"`
+}
+
+type FindPlayerDuplicates interface {
+	Do(ctx context.Context, id string) ([]DuplicateCandidate, error)
+}
+
+type findPlayerDuplicates struct {
+	DBReader database.DBReader
+}
+
+func NewFindPlayerDuplicates(dbReader database.DBReader) FindPlayerDuplicates {
+	return &findPlayerDuplicates{
+		DBReader: dbReader,
+	}
+}
+
+// Do returns the players that are likely to be the same person as the player with the given id,
+// the most likely first. The score, between 0 and 1, weighs how similar the names are, and whether
+// the birthdate and the phone number are the same.
+func (uc *findPlayerDuplicates) Do(ctx context.Context, id string) ([]DuplicateCandidate, error) {
+	player, err := uc.DBReader.GetPlayer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := uc.DBReader.GetPlayerDuplicateCandidates(ctx, player)
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return nil, err
+	}
+
+	candidates := make([]DuplicateCandidate, 0)
+	for _, other := range players {
+		candidate := DuplicateCandidate{Player: other, Reasons: make([]string, 0)}
+
+		if similarity := nameSimilarity(player, &other); similarity >= 0.5 {
+			candidate.Score += 0.5 * similarity
+			candidate.Reasons = append(candidate.Reasons, DuplicateReasonName)
+		}
+
+		if sameDate(player, &other) {
+			candidate.Score += 0.3
+			candidate.Reasons = append(candidate.Reasons, DuplicateReasonBirthdate)
+		}
+
+		if phone := digits(player.PhoneNumber); phone != "" && phone == digits(other.PhoneNumber) {
+			candidate.Score += 0.2
+			candidate.Reasons = append(candidate.Reasons, DuplicateReasonPhoneNumber)
+		}
+
+		if candidate.Score >= MinDuplicateScore {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates, nil
+}
+
+// nameSimilarity returns how well the first and last names of player match the ones of other,
+// from 0 to 1.
+func nameSimilarity(player *entity.Player, other *entity.Player) float64 {
+	otherTerms := util.SearchTerms(other.FirstName, other.MiddleName, other.LastName)
+
+	similarity := 0.0
+	for _, name := range []string{player.FirstName, player.LastName} {
+		terms := util.SearchTerms(name)
+		if len(terms) == 0 {
+			continue
+		}
+
+		similarity += util.SearchScore(terms, otherTerms) / float64(len(terms)) / 2
+	}
+
+	return similarity
+}
+
+func sameDate(player *entity.Player, other *entity.Player) bool {
+	if player.Birthdate == nil || other.Birthdate == nil {
+		return false
+	}
+
+	return player.Birthdate.UTC().Format("2006-01-02") == other.Birthdate.UTC().Format("2006-01-02")
+}
+
+func digits(value string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, value)
+}
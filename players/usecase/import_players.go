@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/quota"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+const ImportPlayersBatchSize = 100
+
+// ImportPlayerError is why the row at Line was not imported. Code is the one the same error has
+// when a player is created, if any.
+type ImportPlayerError struct {
+	Line  int    `json:"line"`
+	Code  string `json:"code,omitempty"`
+	Error string `json:"error"`
+}
+
+func newImportPlayerError(line int, err error) ImportPlayerError {
+	importError := ImportPlayerError{Line: line, Error: err.Error()}
+
+	var appError *util.AppError
+	if errors.As(err, &appError) {
+		importError.Code = appError.Code
+	}
+
+	return importError
+}
+
+type ImportPlayersResult struct {
+	DryRun   bool                `json:"dry_run"`
+	Total    int                 `json:"total"`
+	Valid    int                 `json:"valid"`
+	Imported int                 `json:"imported"`
+	Errors   []ImportPlayerError `json:"errors"`
+}
+
+type ImportPlayers interface {
+	Do(ctx context.Context, rows []ImportPlayerRow, dryRun bool) (*ImportPlayersResult, error)
+}
+
+type importPlayers struct {
+	*internalCreatePlayer
+	DBWriter database.DBWriter
+	Quota    *quota.Checker
+}
+
+func NewImportPlayers(dbWriter database.DBWriter, dbReader database.DBReader, plan entity.Plan) ImportPlayers {
+	return &importPlayers{
+		DBWriter: dbWriter,
+		Quota:    quota.NewChecker(dbReader, plan),
+		internalCreatePlayer: &internalCreatePlayer{
+			ValidateGovernmentID: NewValidateGovernmentIDUsecase(dbReader),
+			ValidateEmail:        NewValidateEmailUsecase(dbReader),
+			ValidateAlias:        NewValidateAliasUsecase(dbReader),
+		},
+	}
+}
+
+// Do validates every row like CreatePlayer does and, unless dryRun is set, adds the valid ones in
+// batches of ImportPlayersBatchSize. Invalid rows are reported and skipped; when a batch cannot be
+// added, all of its rows are reported. Nothing is added when the valid rows do not fit in the plan of
+// the tenant.
+func (uc *importPlayers) Do(ctx context.Context, rows []ImportPlayerRow, dryRun bool) (*ImportPlayersResult, error) {
+	result := &ImportPlayersResult{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Errors: make([]ImportPlayerError, 0),
+	}
+
+	seen := make(map[string]int)
+	valid := make([]ImportPlayerRow, 0, len(rows))
+	for _, row := range rows {
+		reason, err := uc.validate(ctx, &row, seen)
+		if err != nil {
+			log.Logger.Error(fmt.Errorf("couldn't import players. Error when validating line %d: %w", row.Line, err).Error())
+			return nil, err
+		}
+
+		if reason != nil {
+			result.Errors = append(result.Errors, newImportPlayerError(row.Line, reason))
+			continue
+		}
+
+		valid = append(valid, row)
+	}
+
+	result.Valid = len(valid)
+	if err := uc.Quota.CheckPlayers(ctx, int64(len(valid))); err != nil {
+		log.Logger.Info(fmt.Errorf("couldn't import players: %w", err).Error())
+		return nil, err
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	for start := 0; start < len(valid); start += ImportPlayersBatchSize {
+		batch := valid[start:min(start+ImportPlayersBatchSize, len(valid))]
+
+		players := make([]*entity.Player, 0, len(batch))
+		for _, row := range batch {
+			players = append(players, entity.NewPlayer(
+				row.Request.GovernmentID,
+				row.Request.Country,
+				row.Request.FirstName,
+				row.Request.MiddleName,
+				row.Request.LastName,
+				row.Request.Birthdate,
+				row.Request.PhoneNumber,
+				row.Request.Email,
+				row.Request.Alias,
+			))
+		}
+
+		if _, err := uc.DBWriter.AddPlayers(ctx, players); err != nil {
+			log.Logger.Error(fmt.Errorf("couldn't import players of lines %d to %d: %w", batch[0].Line, batch[len(batch)-1].Line, err).Error())
+			for _, row := range batch {
+				result.Errors = append(result.Errors, newImportPlayerError(row.Line, err))
+			}
+			continue
+		}
+
+		result.Imported += len(batch)
+	}
+
+	return result, nil
+}
+
+// validate returns the reason why row cannot be imported, if any. seen holds the unique values of
+// the previous rows, to detect the ones repeated in the file. err is only set when the row could
+// not be validated at all.
+func (uc *importPlayers) validate(ctx context.Context, row *ImportPlayerRow, seen map[string]int) (reason error, err error) {
+	if row.Err != nil {
+		return row.Err, nil
+	}
+
+	if err := row.Request.Validate(); err != nil {
+		return err, nil
+	}
+
+	unique := []string{"government_id:" + row.Request.GovernmentID, "email:" + row.Request.Email}
+	if row.Request.Alias != nil {
+		unique = append(unique, "alias:"+*row.Request.Alias)
+	}
+
+	for _, value := range unique {
+		if line, ok := seen[value]; ok {
+			field, _, _ := strings.Cut(value, ":")
+			return fmt.Errorf("%w: '%s' is also in line %d", util.ErrImportDuplicatedInFile, field, line), nil
+		}
+	}
+
+	for _, value := range unique {
+		seen[value] = row.Line
+	}
+
+	isAvailableGovernmentID, err := uc.internalCreatePlayer.ValidateGovernmentID.IsAvailable(ctx, row.Request.GovernmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAvailableGovernmentID {
+		return util.ErrPlayerGovernmentIDIsTaken, nil
+	}
+
+	isAvailableEmail, err := uc.internalCreatePlayer.ValidateEmail.IsAvailable(ctx, row.Request.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAvailableEmail {
+		return util.ErrPlayerEmailIsTaken, nil
+	}
+
+	isAvailableAlias, err := uc.internalCreatePlayer.ValidateAlias.IsAvailable(ctx, row.Request.Alias)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isAvailableAlias {
+		return util.ErrPlayerAliasIsTaken, nil
+	}
+
+	return nil, nil
+}
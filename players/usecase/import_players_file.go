@@ -0,0 +1,277 @@
+package usecase
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+// ImportPlayerRow is a row of an import file. Line is the line of the row in the file, counting the
+// header as the first one, and Err is set when the row could not be read as a player.
+type ImportPlayerRow struct {
+	Line    int
+	Request CreatePlayerRequest
+	Err     error
+}
+
+var importPlayersColumns = []string{"government_id", "country", "first_name", "middle_name", "last_name", "birthdate", "phone_number", "email", "alias"}
+
+var importPlayersRequiredColumns = []string{"government_id", "first_name", "last_name", "email"}
+
+// ReadPlayersCSV reads the players of a CSV file whose first line names the columns. Columns are
+// matched by name, in any order, and unknown ones are ignored. Cells escaped by the exports are read
+// as they were before, see util.UnescapeCSVCell.
+func ReadPlayersCSV(r io.Reader) ([]ImportPlayerRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", util.ErrImportInvalidFile, err)
+	}
+
+	for _, record := range records {
+		for i, cell := range record {
+			record[i] = util.UnescapeCSVCell(cell)
+		}
+	}
+
+	return readPlayersRecords(records)
+}
+
+// ReadPlayersXLSX reads the players of the first sheet of an XLSX file, laid out like in ReadPlayersCSV.
+func ReadPlayersXLSX(r io.ReaderAt, size int64) ([]ImportPlayerRow, error) {
+	records, err := readXLSXRecords(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", util.ErrImportInvalidFile, err)
+	}
+
+	return readPlayersRecords(records)
+}
+
+func readPlayersRecords(records [][]string) ([]ImportPlayerRow, error) {
+	if len(records) == 0 {
+		return nil, util.ErrImportFileIsEmpty
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	for _, name := range importPlayersRequiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: '%s'", util.ErrImportMissingColumn, name)
+		}
+	}
+
+	rows := make([]ImportPlayerRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		values := make(map[string]string)
+		empty := true
+		for _, name := range importPlayersColumns {
+			if column, ok := columns[name]; ok && column < len(record) {
+				values[name] = strings.TrimSpace(record[column])
+				empty = empty && values[name] == ""
+			}
+		}
+
+		if empty {
+			continue
+		}
+
+		row := ImportPlayerRow{
+			Line: i + 2,
+			Request: CreatePlayerRequest{
+				GovernmentID: values["government_id"],
+				Country:      strings.ToUpper(values["country"]),
+				FirstName:    values["first_name"],
+				MiddleName:   values["middle_name"],
+				LastName:     values["last_name"],
+				PhoneNumber:  values["phone_number"],
+				Email:        values["email"],
+			},
+		}
+
+		if alias := values["alias"]; alias != "" {
+			row.Request.Alias = util.ToPtr(alias)
+		}
+
+		if birthdate := values["birthdate"]; birthdate != "" {
+			row.Request.Birthdate, row.Err = parseBirthdate(birthdate)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseBirthdate accepts dates as YYYY-MM-DD and, as XLSX stores them, as the number of days since
+// 1899-12-30.
+func parseBirthdate(value string) (*time.Time, error) {
+	if birthdate, err := time.Parse(time.DateOnly, value); err == nil {
+		return &birthdate, nil
+	}
+
+	if days, err := strconv.ParseFloat(value, 64); err == nil {
+		return util.ToPtr(time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(days))), nil
+	}
+
+	return nil, util.ErrImportInvalidBirthdate
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.Text)
+	}
+
+	return b.String()
+}
+
+const (
+	// xlsxMaxColumns is the number of columns of a sheet, from A to XFD
+	xlsxMaxColumns = 16384
+	// xlsxMaxPartSize bounds the XML files read from an XLSX file once decompressed
+	xlsxMaxPartSize = 50 << 20
+)
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSXRecords(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if err := decodeXLSXPart(archive, "xl/sharedStrings.xml", &sharedStrings); err != nil && !errors.Is(err, zip.ErrFormat) {
+		return nil, err
+	}
+
+	var sheet xlsxSheet
+	if err := decodeXLSXPart(archive, "xl/worksheets/sheet1.xml", &sheet); err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		record := make([]string, 0)
+		for i, cell := range row.Cells {
+			column, err := xlsxColumn(cell.Ref, i)
+			if err != nil {
+				return nil, err
+			}
+
+			// Only the columns named by the header are read
+			if len(records) > 0 && column >= len(records[0]) {
+				continue
+			}
+
+			for len(record) <= column {
+				record = append(record, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(cell.Value)
+				if err != nil || index < 0 || index >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("invalid shared string in cell %s", cell.Ref)
+				}
+				record[column] = sharedStrings.Items[index].String()
+			case "inlineStr":
+				record[column] = cell.Inline.String()
+			default:
+				record[column] = cell.Value
+			}
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// decodeXLSXPart decodes the XML file of archive with the given name, up to xlsxMaxPartSize bytes
+// once decompressed. It returns zip.ErrFormat when there is no such file.
+func decodeXLSXPart(archive *zip.Reader, name string, v any) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+
+		if file.UncompressedSize64 > xlsxMaxPartSize {
+			return fmt.Errorf("%s is larger than %d bytes", name, xlsxMaxPartSize)
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		// The size in the archive is not trusted, zip only checks it once the whole file is read
+		return xml.NewDecoder(io.LimitReader(f, xlsxMaxPartSize)).Decode(v)
+	}
+
+	return fmt.Errorf("%w: missing %s", zip.ErrFormat, name)
+}
+
+// xlsxColumn returns the zero based column of a cell reference like "AB12". Cells without a
+// reference are placed by their position in the row.
+func xlsxColumn(ref string, position int) (int, error) {
+	column := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+
+		column = column*26 + int(r-'A'+1)
+		if column > xlsxMaxColumns {
+			return 0, fmt.Errorf("invalid column in cell %s", ref)
+		}
+	}
+
+	if column == 0 {
+		column = position + 1
+	}
+
+	if column > xlsxMaxColumns {
+		return 0, fmt.Errorf("too many cells in row of cell %s", ref)
+	}
+
+	return column - 1, nil
+}
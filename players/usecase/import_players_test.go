@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReadPlayersCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    []ImportPlayerRow
+		wantErr error
+	}{
+		{
+			name: "Read_columns_by_name",
+			file: "email,last_name,first_name,government_id,alias\nrafa@test.com,Nadal,Rafael,1234,\n\n",
+			want: []ImportPlayerRow{
+				{Line: 2, Request: CreatePlayerRequest{GovernmentID: "1234", FirstName: "Rafael", LastName: "Nadal", Email: "rafa@test.com"}},
+			},
+		},
+		{
+			name: "Read_cells_escaped_by_exports",
+			file: "government_id,first_name,last_name,email,phone_number\n1234,Rafael,Nadal,rafa@test.com,'+5491155555555\n",
+			want: []ImportPlayerRow{
+				{Line: 2, Request: CreatePlayerRequest{GovernmentID: "1234", FirstName: "Rafael", LastName: "Nadal", Email: "rafa@test.com", PhoneNumber: "+5491155555555"}},
+			},
+		},
+		{
+			name: "Report_invalid_birthdates",
+			file: "government_id,first_name,last_name,email,birthdate\n1234,Rafael,Nadal,rafa@test.com,03/06/1986\n",
+			want: []ImportPlayerRow{
+				{Line: 2, Request: CreatePlayerRequest{GovernmentID: "1234", FirstName: "Rafael", LastName: "Nadal", Email: "rafa@test.com"}, Err: util.ErrImportInvalidBirthdate},
+			},
+		},
+		{
+			name:    "Fail_without_required_columns",
+			file:    "government_id,first_name,last_name\n1234,Rafael,Nadal\n",
+			wantErr: util.ErrImportMissingColumn,
+		},
+		{
+			name:    "Fail_with_empty_file",
+			file:    "",
+			wantErr: util.ErrImportFileIsEmpty,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadPlayersCSV(strings.NewReader(tt.file))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReadPlayersCSV() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadPlayersCSV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func xlsxOf(t *testing.T, sheet string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.Write([]byte(sheet)); err != nil {
+		t.Fatal(err)
+	}
+
+	archive.Close()
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestReadPlayersXLSX(t *testing.T) {
+	header := `<row><c r="A1" t="inlineStr"><is><t>government_id</t></is></c><c r="B1" t="inlineStr"><is><t>first_name</t></is></c><c r="C1" t="inlineStr"><is><t>last_name</t></is></c><c r="D1" t="inlineStr"><is><t>email</t></is></c></row>`
+	player := `<c r="A2"><v>1234</v></c><c r="B2" t="inlineStr"><is><t>Rafael</t></is></c><c r="C2" t="inlineStr"><is><t>Nadal</t></is></c><c r="D2" t="inlineStr"><is><t>rafa@test.com</t></is></c>`
+
+	tests := []struct {
+		name    string
+		sheet   string
+		want    []ImportPlayerRow
+		wantErr error
+	}{
+		{
+			name:  "Ignores_cells_past_the_header",
+			sheet: `<worksheet><sheetData>` + header + `<row>` + player + `<c r="XFD2"><v>1</v></c></row></sheetData></worksheet>`,
+			want: []ImportPlayerRow{
+				{Line: 2, Request: CreatePlayerRequest{GovernmentID: "1234", FirstName: "Rafael", LastName: "Nadal", Email: "rafa@test.com"}},
+			},
+		},
+		{
+			name:    "Fails_with_columns_past_the_last_one",
+			sheet:   `<worksheet><sheetData>` + header + `<row>` + player + `<c r="ZZZZZZZZZZZZZZZZZZZZ2"><v>1</v></c></row></sheetData></worksheet>`,
+			wantErr: util.ErrImportInvalidFile,
+		},
+		{
+			name:    "Fails_when_the_sheet_is_too_large_once_decompressed",
+			sheet:   `<worksheet><sheetData>` + header + strings.Repeat(" ", xlsxMaxPartSize) + `</sheetData></worksheet>`,
+			wantErr: util.ErrImportInvalidFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := xlsxOf(t, tt.sheet)
+			got, err := ReadPlayersXLSX(file, file.Size())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("ReadPlayersXLSX() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadPlayersXLSX() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_importPlayers_Do(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dbReader := database.NewMockDBReader(ctrl)
+	dbWriter := database.NewMockDBWriter(ctrl)
+
+	rows := []ImportPlayerRow{
+		{Line: 2, Request: CreatePlayerRequest{GovernmentID: "1", FirstName: "Rafael", LastName: "Nadal", Email: "rafa@test.com"}},
+		{Line: 3, Request: CreatePlayerRequest{GovernmentID: "2", FirstName: "Roger", Email: "roger@test.com"}},
+		{Line: 4, Request: CreatePlayerRequest{GovernmentID: "3", FirstName: "Rafa", LastName: "Nadal", Email: "rafa@test.com"}},
+		{Line: 5, Request: CreatePlayerRequest{GovernmentID: "4", FirstName: "Novak", LastName: "Djokovic", Email: "novak@test.com"}},
+	}
+
+	type args struct {
+		dryRun bool
+	}
+	tests := []struct {
+		name           string
+		args           args
+		prepareUsecase func()
+		want           *ImportPlayersResult
+		wantErr        bool
+	}{
+		{
+			name: "Dry_run_does_not_add_players",
+			args: args{dryRun: true},
+			prepareUsecase: func() {
+				dbReader.EXPECT().IsAvailable(gomock.Any(), "government_id", "1").Return(true, nil)
+				dbReader.EXPECT().IsAvailable(gomock.Any(), "email", "rafa@test.com").Return(true, nil)
+				dbReader.EXPECT().IsAvailable(gomock.Any(), "government_id", "4").Return(false, nil)
+			},
+			want: &ImportPlayersResult{
+				DryRun: true,
+				Total:  4,
+				Valid:  1,
+				Errors: []ImportPlayerError{
+					{Line: 3, Code: util.ErrValidationFailed.Code, Error: util.ErrPlayerLastNameIsEmpty.Error()},
+					{Line: 4, Code: util.ErrImportDuplicatedInFile.Code, Error: "another row of the import file has the same value: 'email' is also in line 2"},
+					{Line: 5, Code: util.ErrPlayerGovernmentIDIsTaken.Code, Error: util.ErrPlayerGovernmentIDIsTaken.Error()},
+				},
+			},
+		},
+		{
+			name: "Add_valid_players",
+			args: args{dryRun: false},
+			prepareUsecase: func() {
+				dbReader.EXPECT().IsAvailable(gomock.Any(), "government_id", "1").Return(true, nil)
+				dbReader.EXPECT().IsAvailable(gomock.Any(), "email", "rafa@test.com").Return(true, nil)
+				dbReader.EXPECT().IsAvailable(gomock.Any(), "government_id", "4").Return(true, nil)
+				dbReader.EXPECT().IsAvailable(gomock.Any(), "email", "novak@test.com").Return(true, nil)
+				dbWriter.EXPECT().AddPlayers(gomock.Any(), gomock.Len(2)).DoAndReturn(func(_ context.Context, players []*entity.Player) ([]*entity.Player, error) {
+					return players, nil
+				})
+			},
+			want: &ImportPlayersResult{
+				Total:    4,
+				Valid:    2,
+				Imported: 2,
+				Errors: []ImportPlayerError{
+					{Line: 3, Code: util.ErrValidationFailed.Code, Error: util.ErrPlayerLastNameIsEmpty.Error()},
+					{Line: 4, Code: util.ErrImportDuplicatedInFile.Code, Error: "another row of the import file has the same value: 'email' is also in line 2"},
+				},
+			},
+		},
+		{
+			name: "Fail_when_availability_cannot_be_checked",
+			args: args{dryRun: true},
+			prepareUsecase: func() {
+				dbReader.EXPECT().IsAvailable(gomock.Any(), "government_id", "1").Return(false, errors.New("database is down"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			uc := NewImportPlayers(dbWriter, dbReader, entity.Plan{})
+			got, err := uc.Do(context.Background(), rows, tt.args.dryRun)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("importPlayers.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("importPlayers.Do() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,12 +4,29 @@ import (
 	"context"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
 	"github.com/Neniel/gotennis/lib/entity"
 	"github.com/Neniel/gotennis/lib/log"
 )
 
+var PlayersQuerySchema = query.Schema{
+	Filters: map[string]query.Field{
+		"government_id": {Path: "government_id", Type: query.String},
+		"email":         {Path: "email", Type: query.String},
+		"alias":         {Path: "alias", Type: query.String},
+		"first_name":    {Path: "first_name", Type: query.String},
+		"last_name":     {Path: "last_name", Type: query.String},
+		"category_id":   {Path: "category._id", Type: query.ObjectID},
+	},
+	Sorts: map[string]query.Field{
+		"first_name": {Path: "first_name"},
+		"last_name":  {Path: "last_name"},
+		"created_at": {Path: "created_at"},
+	},
+}
+
 type ListPlayers interface {
-	Do(ctx context.Context) ([]entity.Player, error)
+	Do(ctx context.Context, q *query.Query) (*query.Page[entity.Player], error)
 }
 
 type listPlayers struct {
@@ -22,8 +39,8 @@ func NewListPlayers(dbReader database.DBReader) ListPlayers {
 	}
 }
 
-func (uc *listPlayers) Do(ctx context.Context) ([]entity.Player, error) {
-	players, err := uc.DBReader.GetPlayers(ctx)
+func (uc *listPlayers) Do(ctx context.Context, q *query.Query) (*query.Page[entity.Player], error) {
+	players, err := uc.DBReader.GetPlayers(ctx, q)
 
 	if err != nil {
 		log.Logger.Error(err.Error())
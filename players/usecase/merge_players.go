@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+type MergePlayersRequest struct {
+	DuplicateID string `json:"duplicate_id"`
+	MergedBy    string `json:"-"`
+}
+
+func (r *MergePlayersRequest) Validate(id string) error {
+	var v util.Validator
+	if r.DuplicateID == "" {
+		v.Add("duplicate_id", util.ErrPlayerMergeDuplicateIDIsEmpty)
+	} else {
+		v.Check(r.DuplicateID != id, "duplicate_id", util.ErrPlayerMergeWithItself)
+	}
+
+	return v.Err()
+}
+
+type MergePlayers interface {
+	Do(ctx context.Context, id string, request *MergePlayersRequest) (*entity.Player, error)
+}
+
+type mergePlayers struct {
+	DBWriter database.DBWriter
+	DBReader database.DBReader
+}
+
+func NewMergePlayers(dbWriter database.DBWriter, dbReader database.DBReader) MergePlayers {
+	return &mergePlayers{
+		DBWriter: dbWriter,
+		DBReader: dbReader,
+	}
+}
+
+// Do merges the player referenced by the request into the player with the given id, which is the
+// one that remains. The remaining player keeps its own data and only takes from the duplicate the
+// fields it does not have.
+func (uc *mergePlayers) Do(ctx context.Context, id string, request *MergePlayersRequest) (*entity.Player, error) {
+	if err := request.Validate(id); err != nil {
+		return nil, err
+	}
+
+	survivor, err := uc.DBReader.GetPlayer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	duplicate, err := uc.DBReader.GetPlayer(ctx, request.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	merge := &entity.PlayerMerge{
+		Survivor:  *survivor,
+		Duplicate: *duplicate,
+	}
+
+	if request.MergedBy != "" {
+		merge.MergedBy = util.ToPtr(request.MergedBy)
+	}
+
+	merged := *survivor
+	merged.MergeFrom(duplicate)
+
+	return uc.DBWriter.MergePlayers(ctx, &merged, merge)
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_mergePlayers_Do(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dbReader := database.NewMockDBReader(ctrl)
+	dbWriter := database.NewMockDBWriter(ctrl)
+
+	survivor := entity.Player{ID: primitive.NewObjectID(), FirstName: "Rafael", LastName: "Nadal", Email: "rafa@test.com"}
+	duplicate := entity.Player{ID: primitive.NewObjectID(), FirstName: "Rafa", LastName: "Nadal", Email: "rafael@test.com", PhoneNumber: "+34 600 000 000"}
+
+	merged := survivor
+	merged.PhoneNumber = duplicate.PhoneNumber
+
+	type args struct {
+		ctx     context.Context
+		id      string
+		request *MergePlayersRequest
+	}
+	tests := []struct {
+		name           string
+		args           args
+		prepareUsecase func()
+		want           *entity.Player
+		wantErr        error
+	}{
+		{
+			name: "Merge_successfully",
+			args: args{ctx: context.Background(), id: survivor.ID.Hex(), request: &MergePlayersRequest{DuplicateID: duplicate.ID.Hex(), MergedBy: "admin"}},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), survivor.ID.Hex()).Return(&survivor, nil)
+				dbReader.EXPECT().GetPlayer(gomock.Any(), duplicate.ID.Hex()).Return(&duplicate, nil)
+				dbWriter.EXPECT().MergePlayers(gomock.Any(), &merged, &entity.PlayerMerge{
+					Survivor:  survivor,
+					Duplicate: duplicate,
+					MergedBy:  util.ToPtr("admin"),
+				}).Return(&merged, nil)
+			},
+			want: &merged,
+		},
+		{
+			name:           "Fails_without_duplicate",
+			args:           args{ctx: context.Background(), id: survivor.ID.Hex(), request: &MergePlayersRequest{}},
+			prepareUsecase: func() {},
+			wantErr:        util.ErrPlayerMergeDuplicateIDIsEmpty,
+		},
+		{
+			name:           "Fails_when_merging_a_player_with_itself",
+			args:           args{ctx: context.Background(), id: survivor.ID.Hex(), request: &MergePlayersRequest{DuplicateID: survivor.ID.Hex()}},
+			prepareUsecase: func() {},
+			wantErr:        util.ErrPlayerMergeWithItself,
+		},
+		{
+			name: "Fails_when_duplicate_does_not_exist",
+			args: args{ctx: context.Background(), id: survivor.ID.Hex(), request: &MergePlayersRequest{DuplicateID: duplicate.ID.Hex()}},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), survivor.ID.Hex()).Return(&survivor, nil)
+				dbReader.EXPECT().GetPlayer(gomock.Any(), duplicate.ID.Hex()).Return(nil, mongo.ErrNoDocuments)
+			},
+			wantErr: mongo.ErrNoDocuments,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			uc := NewMergePlayers(dbWriter, dbReader)
+			got, err := uc.Do(tt.args.ctx, tt.args.id, tt.args.request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("mergePlayers.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergePlayers.Do() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
@@ -13,61 +12,50 @@ import (
 	"github.com/Neniel/gotennis/lib/util"
 )
 
+// PartiallyUpdatePlayerRequest is a JSON merge patch (RFC 7396) of a player: only the fields in it
+// are changed, and the ones that are null are cleared.
 type PartiallyUpdatePlayerRequest struct {
-	ID           string           `json:"id"`
-	GovernmentID string           `json:"government_id,omitempty"`
-	FirstName    string           `json:"first_name,omitempty"`
-	MiddleName   string           `json:"middle_name,omitempty"`
-	LastName     string           `json:"last_name,omitempty"`
-	Category     *entity.Category `json:"category,omitempty"`
-	Birthdate    *time.Time       `json:"birthdate,omitempty"`
-	PhoneNumber  string           `json:"phone_number,omitempty"`
-	Email        string           `json:"email,omitempty"`
-	Alias        *string          `json:"alias,omitempty"`
+	ID           util.PatchField[string]           `json:"id"`
+	GovernmentID util.PatchField[string]           `json:"government_id"`
+	Country      util.PatchField[string]           `json:"country"`
+	FirstName    util.PatchField[string]           `json:"first_name"`
+	MiddleName   util.PatchField[string]           `json:"middle_name"`
+	LastName     util.PatchField[string]           `json:"last_name"`
+	Category     util.PatchField[*entity.Category] `json:"category"`
+	Birthdate    util.PatchField[*time.Time]       `json:"birthdate"`
+	PhoneNumber  util.PatchField[string]           `json:"phone_number"`
+	Email        util.PatchField[string]           `json:"email"`
+	Alias        util.PatchField[*string]          `json:"alias"`
+	// Version is the one the update is based on, taken from header If-Match. When set, the update
+	// fails with util.ErrPreconditionFailed if the player has been written since
+	Version *int64 `json:"-"`
 }
 
 func (r *PartiallyUpdatePlayerRequest) Validate(id string) error {
+	var v util.Validator
 	if id == "" {
-		return errors.New("id is required in request")
+		v.Add("id", util.ErrPlayerIDIsEmpty)
+	} else if r.ID.Present {
+		v.Check(r.ID.Value == id, "id", util.ErrPlayerIDMismatch)
 	}
 
-	if r.ID == "" {
-		return errors.New("id is required in request")
-	}
-
-	if r.ID != id {
-		return errors.New("id in request and url must be equal")
-	}
-
-	if r.GovernmentID == "" {
-		return util.ErrPlayerGovernmentIDIsEmpty
-	}
-
-	if r.Email == "" {
-		return util.ErrPlayerEmailIsEmpty
-	}
-
-	if r.FirstName == "" {
-		return util.ErrPlayerFirstNameIsEmpty
-	}
-
-	if r.LastName == "" {
-		return util.ErrPlayerLastNameIsEmpty
-	}
-
-	if r.Alias != nil && *r.Alias == "" {
-		return util.ErrPlayerAliasIsEmpty
-	}
-
-	if r.Birthdate != nil && r.Birthdate.IsZero() {
-		return util.ErrPlayerBirthdateIsEmpty
-	}
-
-	if r.Birthdate != nil && r.Birthdate.After(time.Now().UTC()) {
-		return util.ErrPlayerBirthdateIsFutureDate
-	}
+	return v.Err()
+}
 
-	return nil
+// apply applies the request to player and returns the patch that writes it.
+func (r *PartiallyUpdatePlayerRequest) apply(player *entity.Player) *util.Patch {
+	patch := util.NewPatch()
+	util.ApplyPatchField(patch, "government_id", r.GovernmentID, &player.GovernmentID)
+	util.ApplyPatchField(patch, "country", r.Country, &player.Country)
+	util.ApplyPatchField(patch, "first_name", r.FirstName, &player.FirstName)
+	util.ApplyPatchField(patch, "middle_name", r.MiddleName, &player.MiddleName)
+	util.ApplyPatchField(patch, "last_name", r.LastName, &player.LastName)
+	util.ApplyPatchField(patch, "category", r.Category, &player.Category)
+	util.ApplyPatchField(patch, "birthdate", r.Birthdate, &player.Birthdate)
+	util.ApplyPatchField(patch, "phone_number", r.PhoneNumber, &player.PhoneNumber)
+	util.ApplyPatchField(patch, "email", r.Email, &player.Email)
+	util.ApplyPatchField(patch, "alias", r.Alias, &player.Alias)
+	return patch
 }
 
 type PartialltUpdatePlayer interface {
@@ -78,6 +66,7 @@ type internalPartiallyUpdatePlayer struct {
 	ValidateGovernmentID ValidateGovernmentID
 	ValidateEmail        ValidateEmail
 	ValidateAlias        ValidateAlias
+	ValidateCategory     ValidateCategory
 }
 
 type partiallyUpdatePlayer struct {
@@ -94,67 +83,93 @@ func NewPartiallyUpdatePlayer(dbWriter database.DBWriter, dbReader database.DBRe
 			ValidateGovernmentID: NewValidateGovernmentIDUsecase(dbReader),
 			ValidateEmail:        NewValidateEmailUsecase(dbReader),
 			ValidateAlias:        NewValidateAliasUsecase(dbReader),
+			ValidateCategory:     NewValidateCategoryUsecase(dbReader),
 		},
 	}
 }
 
 func (uc *partiallyUpdatePlayer) Do(ctx context.Context, id string, request *PartiallyUpdatePlayerRequest) (*entity.Player, error) {
 	if err := request.Validate(id); err != nil {
-		log.Logger.Error(fmt.Errorf("couldn't create player. Error when validating request: %w", err).Error())
+		log.Logger.Error(fmt.Errorf("couldn't update player. Error when validating request: %w", err).Error())
 		return nil, err
 	}
 
-	isAvailableGovernmentID, err := uc.internalPartiallyUpdatePlayer.ValidateGovernmentID.IsAvailable(ctx, request.GovernmentID)
+	player, err := uc.DBReader.GetPlayer(ctx, id)
 	if err != nil {
-		log.Logger.Error(fmt.Errorf("couldn't update player. Error when validating government ID: %w", err).Error())
 		return nil, err
 	}
 
-	if !isAvailableGovernmentID {
-		log.Logger.Error(fmt.Errorf("couldn't update player. There is another player registered with the provided government ID").Error())
-		return nil, fmt.Errorf("couldn't update player. There is another player registered with the provided government ID")
+	if err := util.CheckVersion(request.Version, player.Version); err != nil {
+		return nil, err
+	}
+
+	// The player embeds the stored category, whatever else the client sent of it
+	if request.Category.Present && !request.Category.Null {
+		request.Category.Value, err = uc.internalPartiallyUpdatePlayer.ValidateCategory.Find(ctx, request.Category.Value)
+		if err != nil {
+			log.Logger.Error(fmt.Errorf("couldn't update player. Error when validating category: %w", err).Error())
+			return nil, err
+		}
 	}
 
-	isAvailableEmail, err := uc.internalPartiallyUpdatePlayer.ValidateEmail.IsAvailable(ctx, request.Email)
-	if err != nil {
-		log.Logger.Error(fmt.Errorf("couldn't update player. Error when validating email: %w", err).Error())
+	governmentID, email, alias := player.GovernmentID, player.Email, player.Alias
+	patch := request.apply(player)
+
+	// The patched player must be as valid as one that is created or fully updated
+	var v util.Validator
+	validatePlayerFields(&v, player.GovernmentID, player.Country, player.FirstName, player.LastName, player.Email, player.PhoneNumber, player.Birthdate)
+	v.Check(player.Alias == nil || *player.Alias != "", "alias", util.ErrPlayerAliasIsEmpty)
+	if err := v.Err(); err != nil {
+		log.Logger.Error(fmt.Errorf("couldn't update player. Error when validating request: %w", err).Error())
 		return nil, err
 	}
 
-	if !isAvailableEmail {
-		log.Logger.Error(fmt.Errorf("couldn't update player. There is another player registered with the provided email").Error())
-		return nil, fmt.Errorf("couldn't update player. There is another player registered with the provided email")
+	if player.GovernmentID != governmentID {
+		isAvailableGovernmentID, err := uc.internalPartiallyUpdatePlayer.ValidateGovernmentID.IsAvailable(ctx, player.GovernmentID)
+		if err != nil {
+			log.Logger.Error(fmt.Errorf("couldn't update player. Error when validating government ID: %w", err).Error())
+			return nil, err
+		}
+
+		if !isAvailableGovernmentID {
+			log.Logger.Error(fmt.Errorf("couldn't update player. There is another player registered with the provided government ID").Error())
+			return nil, util.ErrPlayerGovernmentIDIsTaken
+		}
 	}
 
-	isAvailableAlias, err := uc.internalPartiallyUpdatePlayer.ValidateAlias.IsAvailable(ctx, request.Alias)
-	if err != nil {
-		log.Logger.Error(fmt.Errorf("couldn't update player. Error when validating alias: %w", err).Error())
-		return nil, err
+	if player.Email != email {
+		isAvailableEmail, err := uc.internalPartiallyUpdatePlayer.ValidateEmail.IsAvailable(ctx, player.Email)
+		if err != nil {
+			log.Logger.Error(fmt.Errorf("couldn't update player. Error when validating email: %w", err).Error())
+			return nil, err
+		}
+
+		if !isAvailableEmail {
+			log.Logger.Error(fmt.Errorf("couldn't update player. There is another player registered with the provided email").Error())
+			return nil, util.ErrPlayerEmailIsTaken
+		}
 	}
 
-	if !isAvailableAlias {
-		log.Logger.Error(fmt.Errorf("couldn't update player. There is another player registered with the provided alias").Error())
-		return nil, fmt.Errorf("couldn't update player. There is another player registered with the provided alias")
+	if player.Alias != nil && (alias == nil || *player.Alias != *alias) {
+		isAvailableAlias, err := uc.internalPartiallyUpdatePlayer.ValidateAlias.IsAvailable(ctx, player.Alias)
+		if err != nil {
+			log.Logger.Error(fmt.Errorf("couldn't update player. Error when validating alias: %w", err).Error())
+			return nil, err
+		}
+
+		if !isAvailableAlias {
+			log.Logger.Error(fmt.Errorf("couldn't update player. There is another player registered with the provided alias").Error())
+			return nil, util.ErrPlayerAliasIsTaken
+		}
 	}
 
-	player, err := uc.DBReader.GetPlayer(ctx, id)
-	if err != nil {
-		return nil, err
+	if patch.IsEmpty() {
+		return player, nil
 	}
 
-	player.GovernmentID = request.GovernmentID
-	player.Email = request.Email
-	player.Alias = request.Alias
-	player.FirstName = request.FirstName
-	player.MiddleName = request.MiddleName
-	player.LastName = request.LastName
-	player.Birthdate = request.Birthdate
-	player.Category = request.Category
-	player.PhoneNumber = request.PhoneNumber
-
-	updatedPlayer, err := uc.DBWriter.UpdatePlayer(ctx, player)
+	updatedPlayer, err := uc.DBWriter.PatchPlayer(ctx, player, patch)
 	if err != nil {
-		log.Logger.Error(fmt.Errorf("couldn't update player. Error when attempting add user to the database request: %w", err).Error())
+		log.Logger.Error(fmt.Errorf("couldn't update player. Error when attempting to patch the player in the database: %w", err).Error())
 		return nil, err
 	}
 	return updatedPlayer, nil
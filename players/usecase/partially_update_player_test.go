@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_partiallyUpdatePlayer_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+	id := primitive.NewObjectID()
+	categoryID := primitive.NewObjectID()
+	category := &entity.Category{ID: categoryID, Name: "Primera"}
+
+	storedPlayer := func() *entity.Player {
+		return &entity.Player{
+			ID:           id,
+			GovernmentID: "30123456",
+			Country:      "AR",
+			FirstName:    "Bob",
+			MiddleName:   "Sponge",
+			LastName:     "Square Pants",
+			PhoneNumber:  "+54 000 000 000",
+			Email:        "bobsponge@test.com",
+			Alias:        util.ToPtr("bob"),
+			Version:      2,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		patch          string
+		version        *int64
+		prepareUsecase func()
+		want           *entity.Player
+		wantErr        error
+	}{
+		{
+			name:    "Sets_and_clears_fields",
+			patch:   `{"phone_number":"+54 11 5555 5555","middle_name":null,"alias":null}`,
+			version: util.ToPtr(int64(2)),
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), id.Hex()).Return(storedPlayer(), nil)
+
+				patched := storedPlayer()
+				patched.PhoneNumber = "+54 11 5555 5555"
+				patched.MiddleName = ""
+				patched.Alias = nil
+				dbWriter.EXPECT().PatchPlayer(gomock.Any(), patched, &util.Patch{
+					Set:   map[string]interface{}{"phone_number": "+54 11 5555 5555"},
+					Unset: []string{"middle_name", "alias"},
+				}).Return(patched, nil)
+			},
+			want: &entity.Player{
+				ID:           id,
+				GovernmentID: "30123456",
+				Country:      "AR",
+				FirstName:    "Bob",
+				LastName:     "Square Pants",
+				PhoneNumber:  "+54 11 5555 5555",
+				Email:        "bobsponge@test.com",
+				Version:      2,
+			},
+		},
+		{
+			name:  "Empty_patch_changes_nothing",
+			patch: `{}`,
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), id.Hex()).Return(storedPlayer(), nil)
+			},
+			want: storedPlayer(),
+		},
+		{
+			name:  "Fails_when_ID_does_not_match",
+			patch: `{"id":"663d70d88264adea5d7d29bb"}`,
+			prepareUsecase: func() {
+			},
+			wantErr: util.ErrPlayerIDMismatch,
+		},
+		{
+			name:    "Fails_when_player_has_been_modified",
+			patch:   `{"first_name":"Patrick"}`,
+			version: util.ToPtr(int64(1)),
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), id.Hex()).Return(storedPlayer(), nil)
+			},
+			wantErr: util.ErrPreconditionFailed,
+		},
+		{
+			name:  "Fails_when_clearing_a_required_field",
+			patch: `{"first_name":null}`,
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), id.Hex()).Return(storedPlayer(), nil)
+			},
+			wantErr: util.ErrPlayerFirstNameIsEmpty,
+		},
+		{
+			name:  "Embeds_the_stored_category",
+			patch: `{"category":{"id":"` + categoryID.Hex() + `","name":"Made up"}}`,
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), id.Hex()).Return(storedPlayer(), nil)
+				dbReader.EXPECT().GetCategory(gomock.Any(), categoryID.Hex()).Return(category, nil)
+
+				patched := storedPlayer()
+				patched.Category = category
+				dbWriter.EXPECT().PatchPlayer(gomock.Any(), patched, &util.Patch{
+					Set: map[string]interface{}{"category": category},
+				}).Return(patched, nil)
+			},
+			want: func() *entity.Player {
+				patched := storedPlayer()
+				patched.Category = category
+				return patched
+			}(),
+		},
+		{
+			name:  "Fails_when_category_does_not_exist",
+			patch: `{"category":{"id":"` + categoryID.Hex() + `"}}`,
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), id.Hex()).Return(storedPlayer(), nil)
+				dbReader.EXPECT().GetCategory(gomock.Any(), categoryID.Hex()).Return(nil, mongo.ErrNoDocuments)
+			},
+			wantErr: util.ErrPlayerCategoryNotFound,
+		},
+		{
+			name:  "Fails_when_category_has_no_ID",
+			patch: `{"category":{"name":"Primera"}}`,
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), id.Hex()).Return(storedPlayer(), nil)
+			},
+			wantErr: util.ErrPlayerCategoryIDIsEmpty,
+		},
+		{
+			name:  "Fails_when_new_email_is_taken",
+			patch: `{"email":"patrick@test.com"}`,
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetPlayer(gomock.Any(), id.Hex()).Return(storedPlayer(), nil)
+				dbReader.EXPECT().IsAvailable(gomock.Any(), "email", "patrick@test.com").Return(false, nil)
+			},
+			wantErr: util.ErrPlayerEmailIsTaken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request PartiallyUpdatePlayerRequest
+			if err := json.Unmarshal([]byte(tt.patch), &request); err != nil {
+				t.Fatal(err)
+			}
+			request.Version = tt.version
+
+			tt.prepareUsecase()
+			uc := NewPartiallyUpdatePlayer(dbWriter, dbReader)
+			got, err := uc.Do(context.Background(), id.Hex(), &request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("partiallyUpdatePlayer.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("partiallyUpdatePlayer.Do() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/quota"
+	"github.com/Neniel/gotennis/lib/entity"
+)
+
+type RestorePlayer interface {
+	Do(ctx context.Context, id string) error
+}
+
+type restorePlayer struct {
+	DBWriter database.DBWriter
+	Quota    *quota.Checker
+}
+
+func NewRestorePlayer(dbWriter database.DBWriter, dbReader database.DBReader, plan entity.Plan) RestorePlayer {
+	return &restorePlayer{
+		DBWriter: dbWriter,
+		Quota:    quota.NewChecker(dbReader, plan),
+	}
+}
+
+// Do restores the deleted player, which counts again towards the plan of the tenant like a new one.
+func (uc *restorePlayer) Do(ctx context.Context, id string) error {
+	if err := uc.Quota.CheckPlayers(ctx, 1); err != nil {
+		return err
+	}
+
+	return uc.DBWriter.RestorePlayer(ctx, id)
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+const DefaultSearchLimit = 20
+
+type SearchPlayers interface {
+	Do(ctx context.Context, q string, limit int) ([]entity.Player, error)
+}
+
+type searchPlayers struct {
+	DBReader database.DBReader
+}
+
+func NewSearchPlayers(dbReader database.DBReader) SearchPlayers {
+	return &searchPlayers{
+		DBReader: dbReader,
+	}
+}
+
+// Do returns up to limit players whose names, alias or email match every word of q, best matches
+// first. Words match regardless of case and accents, as the beginning of a word and with typos.
+func (uc *searchPlayers) Do(ctx context.Context, q string, limit int) ([]entity.Player, error) {
+	terms := util.SearchTerms(q)
+	if len(terms) == 0 {
+		return nil, util.ErrPlayerSearchQueryIsEmpty
+	}
+
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+
+	type match struct {
+		player entity.Player
+		score  float64
+	}
+
+	// Only the best limit matches are kept, ties in the order the candidates are read
+	matches := make([]match, 0, limit+1)
+	err := uc.DBReader.SearchPlayers(ctx, terms, func(player *entity.Player) error {
+		score := util.SearchScore(terms, player.SearchTerms)
+		if score == 0 {
+			return nil
+		}
+
+		i := sort.Search(len(matches), func(i int) bool { return matches[i].score < score })
+		if i == limit {
+			return nil
+		}
+
+		matches = slices.Insert(matches, i, match{player: *player, score: score})
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(err.Error())
+		return nil, err
+	}
+
+	players := make([]entity.Player, 0, len(matches))
+	for _, m := range matches {
+		players = append(players, m.player)
+	}
+
+	return players, nil
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_searchPlayers_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+
+	nunez := entity.Player{FirstName: "Martín", LastName: "Núñez", SearchTerms: util.SearchTerms("Martín", "Núñez")}
+	martinez := entity.Player{FirstName: "Marta", LastName: "Martínez", SearchTerms: util.SearchTerms("Marta", "Martínez")}
+	gonzalez := entity.Player{FirstName: "Rodrigo", LastName: "González", SearchTerms: util.SearchTerms("Rodrigo", "González")}
+	candidates := []entity.Player{martinez, gonzalez, nunez}
+	readCandidates := func(_ context.Context, _ []string, fn func(*entity.Player) error) error {
+		for i := range candidates {
+			if err := fn(&candidates[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	type args struct {
+		ctx   context.Context
+		q     string
+		limit int
+	}
+	tests := []struct {
+		name           string
+		args           args
+		prepareUsecase func()
+		want           []entity.Player
+		wantErr        error
+	}{
+		{
+			name: "Ignores_case_and_accents",
+			args: args{ctx: context.Background(), q: "MARTIN NUNEZ"},
+			prepareUsecase: func() {
+				dbReader.EXPECT().SearchPlayers(gomock.Any(), []string{"martin", "nunez"}, gomock.Any()).DoAndReturn(readCandidates)
+			},
+			want: []entity.Player{nunez},
+		},
+		{
+			name: "Ranks_exact_matches_before_prefixes",
+			args: args{ctx: context.Background(), q: "marta"},
+			prepareUsecase: func() {
+				dbReader.EXPECT().SearchPlayers(gomock.Any(), []string{"marta"}, gomock.Any()).DoAndReturn(readCandidates)
+			},
+			want: []entity.Player{martinez, nunez},
+		},
+		{
+			name: "Tolerates_typos",
+			args: args{ctx: context.Background(), q: "gonzales"},
+			prepareUsecase: func() {
+				dbReader.EXPECT().SearchPlayers(gomock.Any(), []string{"gonzales"}, gomock.Any()).DoAndReturn(readCandidates)
+			},
+			want: []entity.Player{gonzalez},
+		},
+		{
+			name: "Limits_the_results",
+			args: args{ctx: context.Background(), q: "mar", limit: 1},
+			prepareUsecase: func() {
+				dbReader.EXPECT().SearchPlayers(gomock.Any(), []string{"mar"}, gomock.Any()).DoAndReturn(readCandidates)
+			},
+			want: []entity.Player{martinez},
+		},
+		{
+			name:           "Fails_with_an_empty_query",
+			args:           args{ctx: context.Background(), q: " - "},
+			prepareUsecase: func() {},
+			wantErr:        util.ErrPlayerSearchQueryIsEmpty,
+		},
+		{
+			name: "Fails_when_reading_the_candidates",
+			args: args{ctx: context.Background(), q: "martin"},
+			prepareUsecase: func() {
+				dbReader.EXPECT().SearchPlayers(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("error when searching players"))
+			},
+			wantErr: errors.New("error when searching players"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			uc := NewSearchPlayers(dbReader)
+			got, err := uc.Do(tt.args.ctx, tt.args.q, tt.args.limit)
+			if (err != nil) != (tt.wantErr != nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Errorf("searchPlayers.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searchPlayers.Do() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
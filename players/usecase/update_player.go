@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
@@ -16,6 +15,7 @@ import (
 type UpdatePlayerRequest struct {
 	ID           string           `json:"id"`
 	GovernmentID string           `json:"government_id"`
+	Country      string           `json:"country"`
 	FirstName    string           `json:"first_name"`
 	MiddleName   string           `json:"middle_name"`
 	LastName     string           `json:"last_name"`
@@ -24,50 +24,22 @@ type UpdatePlayerRequest struct {
 	PhoneNumber  string           `json:"phone_number"`
 	Email        string           `json:"email"`
 	Alias        *string          `json:"alias,omitempty"`
+	// Version is the one the update is based on, taken from header If-Match. When set, the update
+	// fails with util.ErrPreconditionFailed if the player has been written since
+	Version *int64 `json:"-"`
 }
 
 func (r *UpdatePlayerRequest) Validate(id string) error {
-	if id == "" {
-		return errors.New("id is required in request")
+	var v util.Validator
+	if id == "" || r.ID == "" {
+		v.Add("id", util.ErrPlayerIDIsEmpty)
+	} else {
+		v.Check(r.ID == id, "id", util.ErrPlayerIDMismatch)
 	}
 
-	if r.ID == "" {
-		return errors.New("id is required in request")
-	}
-
-	if r.ID != id {
-		return errors.New("id in request and url must be equal")
-	}
-
-	if r.GovernmentID == "" {
-		return util.ErrPlayerGovernmentIDIsEmpty
-	}
-
-	if r.Email == "" {
-		return util.ErrPlayerEmailIsEmpty
-	}
-
-	if r.FirstName == "" {
-		return util.ErrPlayerFirstNameIsEmpty
-	}
-
-	if r.LastName == "" {
-		return util.ErrPlayerLastNameIsEmpty
-	}
-
-	if r.Alias != nil && *r.Alias == "" {
-		return util.ErrPlayerAliasIsEmpty
-	}
-
-	if r.Birthdate != nil && r.Birthdate.IsZero() {
-		return util.ErrPlayerBirthdateIsEmpty
-	}
-
-	if r.Birthdate != nil && r.Birthdate.After(time.Now().UTC()) {
-		return util.ErrPlayerBirthdateIsFutureDate
-	}
-
-	return nil
+	validatePlayerFields(&v, r.GovernmentID, r.Country, r.FirstName, r.LastName, r.Email, r.PhoneNumber, r.Birthdate)
+	v.Check(r.Alias == nil || *r.Alias != "", "alias", util.ErrPlayerAliasIsEmpty)
+	return v.Err()
 }
 
 type UpdatePlayer interface {
@@ -112,7 +84,7 @@ func (uc *updatePlayerUsecase) Do(ctx context.Context, id string, request *Updat
 
 	if !isAvailableGovernmentID {
 		log.Logger.Error(fmt.Errorf("couldn't update player. There is another player registered with the provided government ID").Error())
-		return nil, fmt.Errorf("couldn't update player. There is another player registered with the provided government ID")
+		return nil, util.ErrPlayerGovernmentIDIsTaken
 	}
 
 	isAvailableEmail, err := uc.internalUpdatePlayer.ValidateEmail.IsAvailable(ctx, request.Email)
@@ -123,7 +95,7 @@ func (uc *updatePlayerUsecase) Do(ctx context.Context, id string, request *Updat
 
 	if !isAvailableEmail {
 		log.Logger.Error(fmt.Errorf("couldn't update player. There is another player registered with the provided email").Error())
-		return nil, fmt.Errorf("couldn't update player. There is another player registered with the provided email")
+		return nil, util.ErrPlayerEmailIsTaken
 	}
 
 	isAvailableAlias, err := uc.internalUpdatePlayer.ValidateAlias.IsAvailable(ctx, request.Alias)
@@ -134,7 +106,7 @@ func (uc *updatePlayerUsecase) Do(ctx context.Context, id string, request *Updat
 
 	if !isAvailableAlias {
 		log.Logger.Error(fmt.Errorf("couldn't update player. There is another player registered with the provided alias").Error())
-		return nil, fmt.Errorf("couldn't update player. There is another player registered with the provided alias")
+		return nil, util.ErrPlayerAliasIsTaken
 	}
 
 	player, err := uc.DBReader.GetPlayer(ctx, id)
@@ -142,7 +114,12 @@ func (uc *updatePlayerUsecase) Do(ctx context.Context, id string, request *Updat
 		return nil, err
 	}
 
+	if err := util.CheckVersion(request.Version, player.Version); err != nil {
+		return nil, err
+	}
+
 	player.GovernmentID = request.GovernmentID
+	player.Country = request.Country
 	player.Email = request.Email
 	player.Alias = request.Alias
 	player.FirstName = request.FirstName
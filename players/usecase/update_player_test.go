@@ -47,7 +47,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "Square Pants",
 				Category:     nil,
 				Birthdate:    nil,
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "bobsponge@test.com",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -66,7 +66,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "Square Pants",
 				Category:     nil,
 				Birthdate:    nil,
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "bobsponge@test.com",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -85,7 +85,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "Square Pants",
 				Category:     nil,
 				Birthdate:    nil,
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "bobsponge@test.com",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -104,7 +104,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "Square Pants",
 				Category:     nil,
 				Birthdate:    nil,
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "bobsponge@test.com",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -123,7 +123,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "Square Pants",
 				Category:     nil,
 				Birthdate:    nil,
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "bobsponge@test.com",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -142,7 +142,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "Square Pants",
 				Category:     nil,
 				Birthdate:    nil,
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "bobsponge@test.com",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -161,7 +161,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "",
 				Category:     nil,
 				Birthdate:    nil,
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "bobsponge@test.com",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -180,7 +180,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "Square Pants",
 				Category:     nil,
 				Birthdate:    nil,
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -199,7 +199,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "Square Pants",
 				Category:     nil,
 				Birthdate:    &time.Time{},
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "bobsponge@gmail.com",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -218,7 +218,7 @@ func TestUpdatePlayerRequest_Validate(t *testing.T) {
 				LastName:     "Square Pants",
 				Category:     nil,
 				Birthdate:    util.ToPtr(time.Now().AddDate(20, 0, 0).UTC()),
-				PhoneNumber:  "+00 000 000 000",
+				PhoneNumber:  "+54 000 000 000",
 				Email:        "bobsponge@gmail.com",
 				Alias:        util.ToPtr("bob"),
 			},
@@ -355,7 +355,7 @@ func Test_updatePlayerUsecase_UpdatePlayer_Success(t *testing.T) {
 					LastName:     "Square Pants",
 					Category:     nil,
 					Birthdate:    nil,
-					PhoneNumber:  "+00 000 000 000",
+					PhoneNumber:  "+54 000 000 000",
 					Email:        "bobsponge@test.com",
 					Alias:        util.ToPtr("bob"),
 				}, nil)
@@ -752,7 +752,7 @@ func Test_updatePlayerUsecase_UpdatePlayer_Failure(t *testing.T) {
 					LastName:     "Square Pants",
 					Category:     nil,
 					Birthdate:    nil,
-					PhoneNumber:  "+00 000 000 000",
+					PhoneNumber:  "+54 000 000 000",
 					Email:        "bobsponge@test.com",
 					Alias:        util.ToPtr("bob"),
 				}, nil)
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ValidateCategory interface {
+	// Find returns the stored copy of the given category so players never embed
+	// categories that do not exist. A nil category is allowed and returns nil.
+	Find(ctx context.Context, category *entity.Category) (*entity.Category, error)
+}
+
+type validateCategoryUsecase struct {
+	DBReader database.DBReader
+}
+
+func NewValidateCategoryUsecase(dbReader database.DBReader) ValidateCategory {
+	return &validateCategoryUsecase{
+		DBReader: dbReader,
+	}
+}
+
+func (uc *validateCategoryUsecase) Find(ctx context.Context, category *entity.Category) (*entity.Category, error) {
+	if category == nil {
+		return nil, nil
+	}
+
+	if category.ID.IsZero() {
+		return nil, util.ErrPlayerCategoryIDIsEmpty
+	}
+
+	storedCategory, err := uc.DBReader.GetCategory(ctx, category.ID.Hex())
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, util.ErrPlayerCategoryNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return storedCategory, nil
+}
@@ -1,29 +1,45 @@
 package main
 
 import (
+	"cmp"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/Neniel/gotennis/customers/usecase"
 
 	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/migration"
+	"github.com/Neniel/gotennis/lib/database/query"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/problem"
 	"github.com/Neniel/gotennis/lib/telemetry/grafana"
-
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
+	"github.com/Neniel/gotennis/lib/util"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type Usecases struct {
-	CreateTenant usecase.CreateTenant
-	ListTenants  usecase.ListTenants
-	GetTenant    usecase.GetTenant
-	//UpdateCustomer usecase.UpdateTenant
-	DeleteTenant usecase.DeleteTenant
+	CreateTenant          usecase.CreateTenant
+	ListTenants           usecase.ListTenants
+	GetTenant             usecase.GetTenant
+	UpdateTenant          usecase.UpdateTenant
+	PartiallyUpdateTenant usecase.PartiallyUpdateTenant
+	ChangeTenantStatus    usecase.ChangeTenantStatus
+	DeleteTenant          usecase.DeleteTenant
+	RestoreTenant         usecase.RestoreTenant
+	BackupTenant          usecase.BackupTenant
+	RestoreTenantBackup   usecase.RestoreTenantBackup
+	MigrateTenants        usecase.MigrateTenants
+	GetTenantUsage        usecase.GetTenantUsage
+	VerifyTenantDomain    usecase.VerifyTenantDomain
 }
 
 type CustomerMicroservice struct {
@@ -53,14 +69,32 @@ func (api *APIServer) Run() {
 	mux.HandleFunc("GET /ping", api.pingHandler)
 	mux.HandleFunc("GET /tenants", api.listTenants)
 	mux.HandleFunc("GET /tenants/{id}", api.getTenant)
-	mux.HandleFunc("POST /tenants", api.addTenant)
-	//mux.HandleFunc("PUT /api/tenants/{id}", api.updateCustomer)
+	mux.Handle("POST /tenants", app.NewIdempotency(api.systemIdempotencyStore, app.IdempotencyKeyTTL).Middleware(http.HandlerFunc(api.addTenant)))
+	mux.HandleFunc("PUT /tenants/{id}", api.updateTenant)
+	mux.HandleFunc("PATCH /tenants/{id}", api.partiallyUpdateTenant)
+	mux.HandleFunc("POST /tenants/{id}/suspend", api.changeTenantStatus(entity.TenantStatusSuspended))
+	mux.HandleFunc("POST /tenants/{id}/reactivate", api.changeTenantStatus(entity.TenantStatusActive))
+	mux.HandleFunc("POST /tenants/{id}/offboard", api.changeTenantStatus(entity.TenantStatusOffboarded))
+	mux.HandleFunc("POST /tenants/{id}/domains/{domain}/verify", api.verifyTenantDomain)
 	mux.HandleFunc("DELETE /tenants/{id}", api.deleteTenant)
+	mux.HandleFunc("POST /tenants/{id}/restore", api.restoreTenant)
+	mux.HandleFunc("GET /tenants/{id}/usage", api.getTenantUsage)
+	mux.HandleFunc("GET /tenants/{id}/backup", api.backupTenant)
+	mux.HandleFunc("POST /tenants/{id}/backup/restore", api.restoreTenantBackup)
+	mux.HandleFunc("GET /migrations", api.getMigrations)
+	mux.HandleFunc("POST /migrations", api.runMigrations)
 	mux.Handle("/metrics", promhttp.Handler())
 
 	log.Fatal(http.ListenAndServe(os.Getenv("APP_PORT"), mux))
 }
 
+// systemIdempotencyStore keeps the idempotency keys of this service, whose requests are not sent
+// for a tenant, in the system database.
+func (api *APIServer) systemIdempotencyStore(string) (database.DBReader, database.DBWriter, error) {
+	system := api.CustomerMicroservice.App.GetSystemMongoDBClient()
+	return database.NewDatabaseReader(system.MongoDBClient, system.DatabaseName), database.NewDatabaseWriter(system.MongoDBClient, system.DatabaseName), nil
+}
+
 func (api *APIServer) pingHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		w.Write([]byte("Tenants is ok"))
@@ -72,13 +106,20 @@ func (api *APIServer) pingHandler(w http.ResponseWriter, r *http.Request) {
 func (api *APIServer) listTenants(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Access-Control-Allow-Origin", "*")
 	w.Header().Add("Content-Type", "application/json")
-	customers, err := api.CustomerMicroservice.Usecases.ListTenants.Do(r.Context())
+	q, err := query.Parse(r.URL.Query(), usecase.TenantsQuerySchema)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		problem.Write(w, r, err)
 		return
 	}
 
-	err = json.NewEncoder(w).Encode(&customers)
+	tenants, err := api.CustomerMicroservice.Usecases.ListTenants.Do(r.Context(), q)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	query.SetPaginationHeaders(w, r, tenants.NextCursor)
+	err = json.NewEncoder(w).Encode(&tenants.Items)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -90,18 +131,10 @@ func (api *APIServer) getTenant(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 	if id := r.PathValue("id"); id != "" {
 		tenants, err := api.CustomerMicroservice.Usecases.GetTenant.Do(r.Context(), id)
-		if errors.Is(err, primitive.ErrInvalidHex) {
-			w.WriteHeader(http.StatusBadRequest)
-			grafana.SendMetric("get.category", 1, 1, map[string]interface{}{
-				"status_code": http.StatusBadRequest,
-			})
-			return
-		}
-
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			w.WriteHeader(http.StatusNotFound)
+		if err != nil {
+			problem.Write(w, r, err)
 			grafana.SendMetric("get.category", 1, 1, map[string]interface{}{
-				"status_code": http.NotFound,
+				"status_code": problem.StatusCode(err),
 			})
 			return
 		}
@@ -127,15 +160,15 @@ func (api *APIServer) addTenant(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		problem.Write(w, r, err)
 		return
 	}
 
+	request.CreatedBy = r.Header.Get("X-User-ID")
+
 	customer, err := api.CustomerMicroservice.Usecases.CreateTenant.Do(r.Context(), &request)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -143,7 +176,91 @@ func (api *APIServer) addTenant(w http.ResponseWriter, r *http.Request) {
 	err = json.NewEncoder(w).Encode(&customer)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
+		return
+	}
+}
+
+func (api *APIServer) updateTenant(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+		var request usecase.UpdateTenantRequest
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		request.UpdatedBy = r.Header.Get("X-User-ID")
+
+		tenant, err := api.CustomerMicroservice.Usecases.UpdateTenant.Do(r.Context(), id, &request)
+		writeUpdatedTenant(w, r, tenant, err)
+	}
+}
+
+func (api *APIServer) partiallyUpdateTenant(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+		var request usecase.PartiallyUpdateTenantRequest
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		request.UpdatedBy = r.Header.Get("X-User-ID")
+
+		tenant, err := api.CustomerMicroservice.Usecases.PartiallyUpdateTenant.Do(r.Context(), id, &request)
+		writeUpdatedTenant(w, r, tenant, err)
+	}
+}
+
+// changeTenantStatus returns the handler that moves a tenant to status. The body is optional and
+// may only tell the reason:
+//
+//	{"reason": "unpaid invoices"}
+func (api *APIServer) changeTenantStatus(status entity.TenantStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Header().Add("Content-Type", "application/json")
+		if id := r.PathValue("id"); id != "" {
+			var request usecase.ChangeTenantStatusRequest
+			defer r.Body.Close()
+			err := json.NewDecoder(r.Body).Decode(&request)
+			if err != nil && !errors.Is(err, io.EOF) {
+				problem.Write(w, r, err)
+				return
+			}
+
+			request.UpdatedBy = r.Header.Get("X-User-ID")
+
+			tenant, err := api.CustomerMicroservice.Usecases.ChangeTenantStatus.Do(r.Context(), id, status, &request)
+			writeUpdatedTenant(w, r, tenant, err)
+		}
+	}
+}
+
+func (api *APIServer) verifyTenantDomain(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+		tenant, err := api.CustomerMicroservice.Usecases.VerifyTenantDomain.Do(r.Context(), id, r.PathValue("domain"), r.Header.Get("X-User-ID"))
+		writeUpdatedTenant(w, r, tenant, err)
+	}
+}
+
+func writeUpdatedTenant(w http.ResponseWriter, r *http.Request, tenant *entity.Tenant, err error) {
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(&tenant)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 }
@@ -152,12 +269,139 @@ func (api *APIServer) deleteTenant(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Access-Control-Allow-Origin", "*")
 	w.Header().Add("Content-Type", "application/json")
 	if id := r.PathValue("id"); id != "" {
-		err := api.CustomerMicroservice.Usecases.DeleteTenant.Do(r.Context(), id)
+		err := api.CustomerMicroservice.Usecases.DeleteTenant.Do(r.Context(), id, r.Header.Get("X-User-ID"))
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
 			return
 		}
 		w.WriteHeader(http.StatusNoContent)
 	}
 }
+
+func (api *APIServer) restoreTenant(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+		err := api.CustomerMicroservice.Usecases.RestoreTenant.Do(r.Context(), id)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (api *APIServer) getTenantUsage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+		usage, err := api.CustomerMicroservice.Usecases.GetTenantUsage.Do(r.Context(), id)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(&usage)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func (api *APIServer) backupTenant(w http.ResponseWriter, r *http.Request) {
+	if id := r.PathValue("id"); id != "" {
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Header().Add("Content-Type", "application/gzip")
+		w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s.tar.gz", id, time.Now().UTC().Format("20060102T150405Z"))))
+
+		// Collections are staged before anything is written, so most errors can still be reported
+		_, err := api.CustomerMicroservice.Usecases.BackupTenant.Do(r.Context(), id, w)
+		if err != nil {
+			w.Header().Del("Content-Disposition")
+			problem.Write(w, r, err)
+			return
+		}
+	}
+}
+
+// maxBackupSize bounds the archives that can be restored, as they are staged on disk before being
+// checked
+const maxBackupSize = 1 << 30
+
+func (api *APIServer) restoreTenantBackup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBackupSize)
+		defer r.Body.Close()
+		manifest, err := api.CustomerMicroservice.Usecases.RestoreTenantBackup.Do(r.Context(), id, r.URL.Query().Get("database"), r.Body)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		err = json.NewEncoder(w).Encode(&manifest)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// getMigrations reports, for every tenant, the migrations that have not been applied yet.
+func (api *APIServer) getMigrations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+
+	results := api.CustomerMicroservice.Usecases.MigrateTenants.Do(r.Context(), migration.Up, 0, true)
+
+	err := json.NewEncoder(w).Encode(&results)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// runMigrations migrates every tenant as told by the query parameters:
+//
+//	?direction=down&target=2&dry_run=true
+//
+// Going up defaults to the latest version, while going down always needs a target.
+func (api *APIServer) runMigrations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+
+	values := r.URL.Query()
+	direction, err := migration.ParseDirection(cmp.Or(values.Get("direction"), string(migration.Up)))
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	target, err := strconv.Atoi(cmp.Or(values.Get("target"), "0"))
+	if err != nil || (direction == migration.Down && !values.Has("target")) {
+		problem.Write(w, r, util.ErrMigrationInvalidTarget)
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(cmp.Or(values.Get("dry_run"), "false"))
+	if err != nil {
+		problem.Write(w, r, util.ErrMigrationDryRunIsInvalid)
+		return
+	}
+
+	results := api.CustomerMicroservice.Usecases.MigrateTenants.Do(r.Context(), direction, target, dryRun)
+	for _, result := range results {
+		if result.Error != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			break
+		}
+	}
+
+	err = json.NewEncoder(w).Encode(&results)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
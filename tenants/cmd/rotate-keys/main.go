@@ -0,0 +1,46 @@
+// Command rotate-keys encrypts the connection strings of every tenant with the current master key.
+//
+// It runs with the same environment as the tenants service, listing the new key first and the
+// previous ones after it in MASTER_KEYS or MASTER_KEYFILE. Once it is done, the previous keys can
+// be removed from the services.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Neniel/gotennis/customers/usecase"
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/security"
+)
+
+func main() {
+	// Only the system database is used, the tenants are not connected
+	system, err := app.NewSystemMongoDB(context.Background())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	keys, err := security.KeyProviderFromEnvironment()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	rotated, skipped, err := usecase.NewRotateTenantKeys(system, keys).Do(context.Background())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d tenants encrypted with the current key\n", rotated)
+
+	// Tenants updated meanwhile may have been written with a previous key, so they are not done yet
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "%d tenants were updated meanwhile and skipped, run it again: %s\n", len(skipped), strings.Join(skipped, ", "))
+		os.Exit(1)
+	}
+}
@@ -10,12 +10,13 @@ require (
 	github.com/Neniel/gotennis/lib/telemetry v0.0.0-20240602192022-f8de9f9ace57
 	github.com/prometheus/client_golang v1.19.1
 	go.mongodb.org/mongo-driver v1.15.0
+	go.uber.org/mock v0.4.0
 )
 
 require (
-	github.com/Neniel/gotennis/lib v0.0.0-20240602192022-f8de9f9ace57 // indirect
+	github.com/Neniel/gotennis/lib v0.0.0-20240602192022-f8de9f9ace57
 	github.com/Neniel/gotennis/lib/config v0.0.0-20240602192022-f8de9f9ace57 // indirect
-	github.com/Neniel/gotennis/lib/util v0.0.0-20240602192022-f8de9f9ace57 // indirect
+	github.com/Neniel/gotennis/lib/util v0.0.0-20240602192022-f8de9f9ace57
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/cespare/xxhash/v2 v2.3.0 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
@@ -28,7 +29,6 @@ require (
 	github.com/xdg-go/scram v1.1.2 // indirect
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	github.com/youmark/pkcs8 v0.0.0-20240424034433-3c2c7870ae76 // indirect
-	go.uber.org/mock v0.4.0 // indirect
 	golang.org/x/crypto v0.23.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/sys v0.20.0 // indirect
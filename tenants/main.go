@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Neniel/gotennis/customers/usecase"
 	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/log"
 )
 
 func main() {
@@ -13,13 +16,33 @@ func main() {
 	ms := &CustomerMicroservice{
 		App: app,
 		Usecases: &Usecases{
-			CreateTenant: usecase.NewCreateTenant(app),
-			ListTenants:  usecase.NewListTenants(app),
-			GetTenant:    usecase.NewGetTenant(app),
-			//UpdateCustomer: usecase.NewUpdateCustomer(app),
-			DeleteTenant: usecase.NewDeleteTenant(app),
+			CreateTenant:          usecase.NewCreateTenant(app),
+			ListTenants:           usecase.NewListTenants(app),
+			GetTenant:             usecase.NewGetTenant(app),
+			UpdateTenant:          usecase.NewUpdateTenant(app),
+			PartiallyUpdateTenant: usecase.NewPartiallyUpdateTenant(app),
+			ChangeTenantStatus:    usecase.NewChangeTenantStatus(app),
+			DeleteTenant:          usecase.NewDeleteTenant(app),
+			RestoreTenant:         usecase.NewRestoreTenant(app),
+			BackupTenant:          usecase.NewBackupTenant(app),
+			RestoreTenantBackup:   usecase.NewRestoreTenantBackup(app),
+			MigrateTenants:        usecase.NewMigrateTenants(app),
+			GetTenantUsage:        usecase.NewGetTenantUsage(app),
+			VerifyTenantDomain:    usecase.NewVerifyTenantDomain(app),
 		},
 	}
 
+	system := app.GetSystemMongoDBClient()
+	if err := database.NewDatabaseWriter(system.MongoDBClient, system.DatabaseName).CreateIdempotencyIndexes(context.Background()); err != nil {
+		log.Logger.Error(fmt.Errorf("error while creating the idempotency indexes of the system database: %w", err).Error())
+	}
+
+	if err := database.NewDatabaseWriter(system.MongoDBClient, system.DatabaseName).IndexTenants(context.Background()); err != nil {
+		log.Logger.Error(fmt.Errorf("error while indexing the tenants of the system database: %w", err).Error())
+	}
+
+	go app.StartSystemPurge(context.Background(), "tenants")
+	go app.WatchTenants(context.Background())
+
 	ms.NewAPIServer().Run()
 }
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database/backup"
+	"github.com/Neniel/gotennis/lib/database/mongodb"
+	"github.com/Neniel/gotennis/lib/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BackupTenant interface {
+	Do(ctx context.Context, id string, w io.Writer) (*backup.Manifest, error)
+}
+
+type backupTenant struct {
+	App app.IApp
+}
+
+func NewBackupTenant(app app.IApp) BackupTenant {
+	return &backupTenant{
+		App: app,
+	}
+}
+
+// Do writes to w an archive with the whole database of the tenant, or with its part of it when
+// the database is shared.
+func (uc *backupTenant) Do(ctx context.Context, id string, w io.Writer) (*backup.Manifest, error) {
+	// The status is not checked, so that suspended tenants can be backed up too
+	client, err := uc.App.GetTenantMongoDBClientOfAnyStatus(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Shared databases are dumped with the documents of the tenant and the ones common to all of
+	// the tenants, such as the applied migrations
+	filter := bson.D{}
+	if client.SharedDatabase {
+		_id, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, err
+		}
+
+		filter = bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: mongodb.TenantIDField, Value: _id}},
+			bson.D{{Key: mongodb.TenantIDField, Value: bson.D{{Key: "$exists", Value: false}}}},
+		}}}
+	}
+
+	manifest, err := backup.Dump(ctx, client.MongoDBClient.Database(client.DatabaseName), filter, id, w)
+	if err != nil {
+		log.Logger.Error(fmt.Errorf("could not backup tenant '%s': %w", id, err).Error())
+		return nil, err
+	}
+
+	log.Logger.Info(fmt.Sprintf("backup of tenant '%s' created with %d collections", id, len(manifest.Collections)))
+	return manifest, nil
+}
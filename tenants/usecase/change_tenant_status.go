@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+type ChangeTenantStatusRequest struct {
+	Reason    string `json:"reason"`
+	UpdatedBy string `json:"-"`
+}
+
+type ChangeTenantStatus interface {
+	Do(ctx context.Context, id string, status entity.TenantStatus, request *ChangeTenantStatusRequest) (*entity.Tenant, error)
+}
+
+type changeTenantStatus struct {
+	DBWriter database.DBWriter
+	DBReader database.DBReader
+}
+
+func NewChangeTenantStatus(app app.IApp) ChangeTenantStatus {
+	systemMongoDBClient := app.GetSystemMongoDBClient()
+	return &changeTenantStatus{
+		DBWriter: database.NewDatabaseWriter(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+		DBReader: database.NewDatabaseReader(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+	}
+}
+
+// Do suspends, reactivates or offboards the tenant, recording why and by whom.
+func (uc *changeTenantStatus) Do(ctx context.Context, id string, status entity.TenantStatus, request *ChangeTenantStatusRequest) (*entity.Tenant, error) {
+	tenant, err := uc.DBReader.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !tenant.CanTransitionTo(status) {
+		return nil, util.ErrTenantInvalidStatusTransition
+	}
+
+	tenant.Status = status
+	tenant.StatusReason = nil
+	if request.Reason != "" {
+		tenant.StatusReason = &request.Reason
+	}
+	tenant.StatusChangedAt = util.ToPtr(time.Now().UTC())
+	tenant.UpdatedBy = &request.UpdatedBy
+
+	tenant, err = uc.DBWriter.UpdateTenant(ctx, tenant)
+	if err != nil {
+		log.Logger.Error(fmt.Errorf("could not change status of tenant '%s' to '%s': %w", id, status, err).Error())
+		return nil, err
+	}
+
+	log.Logger.Info(fmt.Sprintf("tenant '%s' is now %s", id, status))
+	return tenant, nil
+}
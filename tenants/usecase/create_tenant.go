@@ -2,61 +2,263 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/Neniel/gotennis/lib/app"
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/migration"
 	"github.com/Neniel/gotennis/lib/entity"
 	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/security"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// DefaultCategories are the categories a tenant starts with when none are requested
+var DefaultCategories = []string{"Primera", "Segunda", "Tercera", "Cuarta", "Quinta", "Sexta", "Séptima"}
+
+const minAdminPasswordLength = 8
+
+// SharedTenantsDatabaseName is the database of the shared tier tenants that do not ask for another one
+const SharedTenantsDatabaseName = "shared_tenants"
+
 type CreateTenant interface {
 	Do(ctx context.Context, request *CreateTenantRequest) (*entity.Tenant, error)
 }
 
 type createTenant struct {
+	App      app.IApp
+	DBReader database.DBReader
 	DBWriter database.DBWriter
 }
 
 func NewCreateTenant(app app.IApp) CreateTenant {
 	systemMongoDBClient := app.GetSystemMongoDBClient()
 	return &createTenant{
+		App:      app,
+		DBReader: database.NewDatabaseReader(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
 		DBWriter: database.NewDatabaseWriter(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
 	}
 }
 
 type CreateTenantRequest struct {
-	Name                    string `json:"name"`
-	PhoneNumber             string `json:"phone_number"`
-	Email                   string `json:"email"`
-	Tier                    string `json:"tier"`
-	MongoDBConnectionString string `json:"mongo_db_connection_string"`
-	DatabaseName            string `json:"database_name"`
+	Name                    string             `json:"name"`
+	Slug                    string             `json:"slug"`
+	PhoneNumber             string             `json:"phone_number"`
+	Email                   string             `json:"email"`
+	Tier                    entity.TenantTier  `json:"tier"`
+	MongoDBConnectionString string             `json:"mongo_db_connection_string"`
+	DatabaseName            string             `json:"database_name"`
+	Categories              []string           `json:"categories"`
+	Admin                   CreateAdminRequest `json:"admin"`
+	CreatedBy               string             `json:"-"`
+}
+
+// CreateAdminRequest describes the first user of a tenant, who manages it.
+type CreateAdminRequest struct {
+	GovernmentID string `json:"government_id"`
+	Email        string `json:"email"`
+	Password     string `json:"password"`
 }
 
 func (r *CreateTenantRequest) Validate() error {
-	return nil
+	if r.Tier == "" {
+		r.Tier = entity.TenantTierStandard
+	}
+
+	if r.Categories == nil {
+		r.Categories = DefaultCategories
+	}
+
+	if r.Slug == "" {
+		r.Slug = util.Slug(r.Name)
+	}
+
+	var v util.Validator
+	validateTenantFields(&v, r.Name, r.Email, r.PhoneNumber, r.Tier)
+	v.Check(r.Name == "" || util.IsDomainLabel(r.Slug), "slug", util.ErrTenantSlugIsInvalid)
+
+	// Shared tenants only ever live in the shared database, and no other tenant does
+	if r.Tier == entity.TenantTierShared {
+		v.Check(r.DatabaseName == "", "database_name", util.ErrTenantDatabaseNameIsNotAllowed)
+	} else {
+		v.Check(r.DatabaseName != SharedTenantsDatabaseName, "database_name", util.ErrTenantDatabaseNameIsNotAllowed)
+	}
+
+	v.Check(r.Admin.GovernmentID != "", "admin.government_id", util.ErrTenantAdminGovernmentIDIsEmpty)
+	v.Check(util.IsEmail(r.Admin.Email), "admin.email", util.ErrTenantAdminEmailIsInvalid)
+	v.Check(len(r.Admin.Password) >= minAdminPasswordLength, "admin.password", util.ErrTenantAdminPasswordIsTooShort)
+	return v.Err()
+}
+
+// validateTenantFields checks the fields every tenant must have, adding the ones that are not
+// valid to v.
+func validateTenantFields(v *util.Validator, name string, email string, phoneNumber string, tier entity.TenantTier) {
+	v.Check(name != "", "name", util.ErrTenantNameIsEmpty)
+
+	if email == "" {
+		v.Add("email", util.ErrTenantEmailIsEmpty)
+	} else {
+		v.Check(util.IsEmail(email), "email", util.ErrTenantEmailIsInvalid)
+	}
+
+	v.Check(phoneNumber == "" || util.IsPhoneNumber(phoneNumber), "phone_number", util.ErrTenantPhoneNumberIsInvalid)
+	v.Check(tier.IsValid(), "tier", util.ErrTenantInvalidTier)
 }
 
+// Do provisions the database of a new tenant, migrated to the latest schema and with its categories
+// and admin user, and registers the tenant once it is ready. The database must be new or empty,
+// and it is dropped again when provisioning fails so that the request can be retried. Shared
+// databases are never dropped; only the documents of the tenant are, see app.TenantMongoDB.DBWriter.
 func (uc *createTenant) Do(ctx context.Context, request *CreateTenantRequest) (*entity.Tenant, error) {
 	if err := request.Validate(); err != nil {
 		log.Logger.Info(fmt.Errorf("could not create tenant: %w", err).Error())
 		return nil, err
 	}
 
-	customer := &entity.Tenant{
+	tenant := &entity.Tenant{
+		ID:                      primitive.NewObjectID(),
 		Name:                    request.Name,
+		Slug:                    request.Slug,
 		PhoneNumber:             request.PhoneNumber,
 		Email:                   request.Email,
 		Tier:                    request.Tier,
 		MongoDBConnectionString: request.MongoDBConnectionString,
 		DatabaseName:            request.DatabaseName,
+		CreatedBy:               request.CreatedBy,
+	}
+
+	isTaken, err := uc.DBReader.IsTenantSlugTaken(ctx, tenant.Slug)
+	if err != nil {
+		log.Logger.Info(fmt.Errorf("could not create tenant: %w", err).Error())
+		return nil, err
+	}
+
+	if isTaken {
+		return nil, util.ErrTenantSlugIsTaken
+	}
+
+	if tenant.MongoDBConnectionString == "" {
+		tenant.MongoDBConnectionString = uc.App.GetDefaultTenantMongoDBURI()
+	}
+
+	if tenant.DatabaseName == "" && tenant.HasSharedDatabase() {
+		tenant.DatabaseName = SharedTenantsDatabaseName
+	}
+
+	if tenant.DatabaseName == "" {
+		tenant.DatabaseName = "tenant_" + tenant.ID.Hex()
+	}
+
+	// The scoped IsEmpty below cannot tell whether the database belongs to another tenant, and the
+	// reservation keeps concurrent requests from provisioning the same database until the tenant is
+	// registered, when the unique index on its dedicated database takes over
+	if !tenant.HasSharedDatabase() {
+		isReserved, err := uc.DBWriter.ReserveTenantDatabase(ctx, tenant.DatabaseName)
+		if err != nil {
+			log.Logger.Info(fmt.Errorf("could not create tenant: %w", err).Error())
+			return nil, err
+		}
+
+		if !isReserved {
+			return nil, util.ErrTenantDatabaseNameIsTaken
+		}
+
+		defer func() {
+			if err := uc.DBWriter.ReleaseTenantDatabase(context.WithoutCancel(ctx), tenant.DatabaseName); err != nil {
+				log.Logger.Error(fmt.Errorf("could not release database '%s' of tenant '%s': %w", tenant.DatabaseName, tenant.Name, err).Error())
+			}
+		}()
+
+		isTaken, err := uc.DBReader.IsTenantDatabaseNameTaken(ctx, tenant.DatabaseName)
+		if err != nil {
+			log.Logger.Info(fmt.Errorf("could not create tenant: %w", err).Error())
+			return nil, err
+		}
+
+		if isTaken {
+			return nil, util.ErrTenantDatabaseNameIsTaken
+		}
 	}
 
-	newCustomer, err := uc.DBWriter.AddTenant(ctx, customer)
+	client, err := uc.App.ConnectTenantMongoDB(ctx, tenant)
 	if err != nil {
 		log.Logger.Info(fmt.Errorf("could not create tenant: %w", err).Error())
+		return nil, util.ErrTenantDatabaseIsNotAvailable
+	}
+	defer client.MongoDBClient.Disconnect(context.Background())
+
+	tenantDBReader := client.DBReader()
+	tenantDBWriter := client.DBWriter()
+
+	isEmpty, err := tenantDBReader.IsEmpty(ctx)
+	if err != nil {
+		log.Logger.Info(fmt.Errorf("could not create tenant: %w", err).Error())
+		return nil, err
+	}
+
+	if !isEmpty {
+		return nil, util.ErrTenantDatabaseIsNotEmpty
+	}
+
+	if err := uc.provision(ctx, client, tenant, request); err != nil {
+		log.Logger.Error(fmt.Errorf("could not provision tenant '%s': %w", tenant.Name, err).Error())
+
+		// The request is not cancelled while rolling back, otherwise the database would stay
+		if dropErr := tenantDBWriter.DropDatabase(context.WithoutCancel(ctx)); dropErr != nil {
+			log.Logger.Error(fmt.Errorf("could not drop database '%s' of tenant '%s' after a failed provisioning: %w", tenant.DatabaseName, tenant.Name, dropErr).Error())
+			return nil, errors.Join(err, dropErr)
+		}
+
 		return nil, err
 	}
-	return newCustomer, nil
+
+	log.Logger.Info(fmt.Sprintf("tenant '%s' provisioned in database '%s'", tenant.Name, tenant.DatabaseName))
+	return tenant, nil
+}
+
+func (uc *createTenant) provision(ctx context.Context, client *app.TenantMongoDB, tenant *entity.Tenant, request *CreateTenantRequest) error {
+	_, err := migration.NewRunner(migration.All).Run(ctx, client.MongoDBClient.Database(client.DatabaseName), migration.Up, 0, false)
+	if err != nil {
+		return fmt.Errorf("error when migrating database: %w", err)
+	}
+
+	tenantDBWriter := client.DBWriter()
+
+	for _, name := range request.Categories {
+		if _, err := tenantDBWriter.AddCategory(ctx, entity.NewCategory(name)); err != nil {
+			return fmt.Errorf("error when adding category '%s': %w", name, err)
+		}
+	}
+
+	password, err := security.EncryptPassword(request.Admin.Password)
+	if err != nil {
+		return err
+	}
+
+	_, err = tenantDBWriter.AddUser(ctx, &entity.User{
+		CustomerID:   tenant.ID,
+		GovernmentID: request.Admin.GovernmentID,
+		Email:        request.Admin.Email,
+		Password:     password,
+		Role:         entity.UserRoleAdmin,
+		CreatedBy:    request.CreatedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("error when adding admin user: %w", err)
+	}
+
+	// The connection string is only encrypted once the database has been provisioned with it
+	tenant.MongoDBConnectionString, err = security.Encrypt(uc.App.GetKeyProvider(), tenant.MongoDBConnectionString, tenant.ID.Hex())
+	if err != nil {
+		return fmt.Errorf("error when encrypting connection string: %w", err)
+	}
+
+	// The tenant is registered last, so that it is never served before its database is ready
+	if _, err := uc.DBWriter.AddTenant(ctx, tenant); err != nil {
+		return fmt.Errorf("error when registering tenant: %w", err)
+	}
+
+	return nil
 }
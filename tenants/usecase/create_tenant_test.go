@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_createTenant_Do(t *testing.T) {
+	iApp := app.NewMockIApp(gomock.NewController(t))
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+
+	request := func(tier entity.TenantTier, databaseName string) *CreateTenantRequest {
+		return &CreateTenantRequest{
+			Name:         "Club",
+			Email:        "club@test.com",
+			Tier:         tier,
+			DatabaseName: databaseName,
+			Categories:   []string{},
+			Admin:        CreateAdminRequest{GovernmentID: "30123456", Email: "admin@test.com", Password: "password"},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		request        *CreateTenantRequest
+		prepareUsecase func()
+		wantErr        error
+	}{
+		{
+			name:    "Fails_when_slug_is_taken",
+			request: request(entity.TenantTierStandard, "club"),
+			prepareUsecase: func() {
+				dbReader.EXPECT().IsTenantSlugTaken(gomock.Any(), "club").Return(true, nil)
+			},
+			wantErr: util.ErrTenantSlugIsTaken,
+		},
+		{
+			name:    "Fails_when_database_is_being_provisioned_by_another_request",
+			request: request(entity.TenantTierStandard, "club"),
+			prepareUsecase: func() {
+				dbReader.EXPECT().IsTenantSlugTaken(gomock.Any(), "club").Return(false, nil)
+				iApp.EXPECT().GetDefaultTenantMongoDBURI().Return("mongodb://localhost:27017")
+				dbWriter.EXPECT().ReserveTenantDatabase(gomock.Any(), "club").Return(false, nil)
+			},
+			wantErr: util.ErrTenantDatabaseNameIsTaken,
+		},
+		{
+			name:    "Fails_and_releases_database_when_it_belongs_to_another_tenant",
+			request: request(entity.TenantTierStandard, "club"),
+			prepareUsecase: func() {
+				dbReader.EXPECT().IsTenantSlugTaken(gomock.Any(), "club").Return(false, nil)
+				iApp.EXPECT().GetDefaultTenantMongoDBURI().Return("mongodb://localhost:27017")
+				dbWriter.EXPECT().ReserveTenantDatabase(gomock.Any(), "club").Return(true, nil)
+				dbReader.EXPECT().IsTenantDatabaseNameTaken(gomock.Any(), "club").Return(true, nil)
+				dbWriter.EXPECT().ReleaseTenantDatabase(gomock.Any(), "club").Return(nil)
+			},
+			wantErr: util.ErrTenantDatabaseNameIsTaken,
+		},
+		{
+			name:    "Fails_and_releases_database_when_it_cannot_be_connected",
+			request: request(entity.TenantTierStandard, "club"),
+			prepareUsecase: func() {
+				dbReader.EXPECT().IsTenantSlugTaken(gomock.Any(), "club").Return(false, nil)
+				iApp.EXPECT().GetDefaultTenantMongoDBURI().Return("mongodb://localhost:27017")
+				dbWriter.EXPECT().ReserveTenantDatabase(gomock.Any(), "club").Return(true, nil)
+				dbReader.EXPECT().IsTenantDatabaseNameTaken(gomock.Any(), "club").Return(false, nil)
+				iApp.EXPECT().ConnectTenantMongoDB(gomock.Any(), gomock.Any()).Return(nil, errors.New("cluster is down"))
+				dbWriter.EXPECT().ReleaseTenantDatabase(gomock.Any(), "club").Return(nil)
+			},
+			wantErr: util.ErrTenantDatabaseIsNotAvailable,
+		},
+		{
+			name:    "Does_not_reserve_the_shared_database",
+			request: request(entity.TenantTierShared, ""),
+			prepareUsecase: func() {
+				dbReader.EXPECT().IsTenantSlugTaken(gomock.Any(), "club").Return(false, nil)
+				iApp.EXPECT().GetDefaultTenantMongoDBURI().Return("mongodb://localhost:27017")
+				iApp.EXPECT().ConnectTenantMongoDB(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tenant *entity.Tenant) (*app.TenantMongoDB, error) {
+					if tenant.DatabaseName != SharedTenantsDatabaseName {
+						t.Errorf("tenant is provisioned in '%s', want '%s'", tenant.DatabaseName, SharedTenantsDatabaseName)
+					}
+					return nil, errors.New("cluster is down")
+				})
+			},
+			wantErr: util.ErrTenantDatabaseIsNotAvailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			uc := &createTenant{App: iApp, DBReader: dbReader, DBWriter: dbWriter}
+			if _, err := uc.Do(context.Background(), tt.request); !errors.Is(err, tt.wantErr) {
+				t.Errorf("createTenant.Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -10,7 +10,7 @@ import (
 )
 
 type DeleteTenant interface {
-	Do(ctx context.Context, customerID string) error
+	Do(ctx context.Context, customerID string, deletedBy string) error
 }
 
 type deleteTenant struct {
@@ -24,9 +24,9 @@ func NewDeleteTenant(app app.IApp) DeleteTenant {
 	}
 }
 
-func (uc *deleteTenant) Do(ctx context.Context, customerID string) error {
+func (uc *deleteTenant) Do(ctx context.Context, customerID string, deletedBy string) error {
 
-	err := uc.DBWriter.DeleteTenant(ctx, customerID)
+	err := uc.DBWriter.DeleteTenant(ctx, customerID, deletedBy)
 	if err != nil {
 		log.Logger.Info(fmt.Errorf("could not delete tenant: %w", err).Error())
 		return err
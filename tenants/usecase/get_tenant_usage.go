@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/quota"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+)
+
+// TenantUsage tells what a tenant uses of the plan of its tier.
+type TenantUsage struct {
+	TenantID string            `json:"tenant_id"`
+	Tier     entity.TenantTier `json:"tier"`
+	Plan     entity.Plan       `json:"plan"`
+	Usage    quota.Usage       `json:"usage"`
+}
+
+type GetTenantUsage interface {
+	Do(ctx context.Context, id string) (*TenantUsage, error)
+}
+
+type getTenantUsage struct {
+	App      app.IApp
+	DBReader database.DBReader
+}
+
+func NewGetTenantUsage(app app.IApp) GetTenantUsage {
+	systemMongoDBClient := app.GetSystemMongoDBClient()
+	return &getTenantUsage{
+		App:      app,
+		DBReader: database.NewDatabaseReader(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+	}
+}
+
+func (uc *getTenantUsage) Do(ctx context.Context, id string) (*TenantUsage, error) {
+	tenant, err := uc.DBReader.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The status is not checked, so that the usage of suspended tenants can be reviewed too
+	client, err := uc.App.GetTenantMongoDBClientOfAnyStatus(id)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := quota.NewChecker(client.DBReader(), tenant.GetPlan()).Usage(ctx, time.Now().UTC())
+	if err != nil {
+		log.Logger.Error(fmt.Errorf("could not get usage of tenant '%s': %w", id, err).Error())
+		return nil, err
+	}
+
+	return &TenantUsage{
+		TenantID: id,
+		Tier:     tenant.GetTier(),
+		Plan:     tenant.GetPlan(),
+		Usage:    *usage,
+	}, nil
+}
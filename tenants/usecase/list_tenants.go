@@ -6,12 +6,25 @@ import (
 
 	"github.com/Neniel/gotennis/lib/app"
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
 	"github.com/Neniel/gotennis/lib/entity"
 	"github.com/Neniel/gotennis/lib/log"
 )
 
+var TenantsQuerySchema = query.Schema{
+	Filters: map[string]query.Field{
+		"name":  {Path: "name", Type: query.String},
+		"email": {Path: "email", Type: query.String},
+		"tier":  {Path: "tier", Type: query.String},
+	},
+	Sorts: map[string]query.Field{
+		"name":       {Path: "name"},
+		"created_at": {Path: "created_at"},
+	},
+}
+
 type ListTenants interface {
-	Do(ctx context.Context) ([]entity.Tenant, error)
+	Do(ctx context.Context, q *query.Query) (*query.Page[entity.Tenant], error)
 }
 
 type listTenants struct {
@@ -25,11 +38,11 @@ func NewListTenants(app app.IApp) ListTenants {
 	}
 }
 
-func (uc *listTenants) Do(ctx context.Context) ([]entity.Tenant, error) {
-	newCustomer, err := uc.DBReader.GetTenants(ctx)
+func (uc *listTenants) Do(ctx context.Context, q *query.Query) (*query.Page[entity.Tenant], error) {
+	tenants, err := uc.DBReader.GetTenants(ctx, q)
 	if err != nil {
-		log.Logger.Info(fmt.Errorf("could not create customer: %w", err).Error())
+		log.Logger.Info(fmt.Errorf("could not list tenants: %w", err).Error())
 		return nil, err
 	}
-	return newCustomer, nil
+	return tenants, nil
 }
@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database/migration"
+)
+
+type MigrateTenants interface {
+	Do(ctx context.Context, direction migration.Direction, target int, dryRun bool) map[string]*migration.Result
+}
+
+type migrateTenants struct {
+	App app.IApp
+}
+
+func NewMigrateTenants(app app.IApp) MigrateTenants {
+	return &migrateTenants{
+		App: app,
+	}
+}
+
+// Do migrates the databases of every tenant and returns the outcome of each one by tenant ID.
+func (uc *migrateTenants) Do(ctx context.Context, direction migration.Direction, target int, dryRun bool) map[string]*migration.Result {
+	return uc.App.Migrate(ctx, direction, target, dryRun)
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/security"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+type PartiallyUpdateTenantRequest struct {
+	ID                      string             `json:"id"`
+	Name                    *string            `json:"name,omitempty"`
+	PhoneNumber             *string            `json:"phone_number,omitempty"`
+	Email                   *string            `json:"email,omitempty"`
+	Tier                    *entity.TenantTier `json:"tier,omitempty"`
+	MongoDBConnectionString *string            `json:"mongo_db_connection_string,omitempty"`
+	DatabaseName            *string            `json:"database_name,omitempty"`
+	CustomDomains           *[]string          `json:"custom_domains,omitempty"`
+	UpdatedBy               string             `json:"-"`
+}
+
+func (r *PartiallyUpdateTenantRequest) Validate(id string) error {
+	var v util.Validator
+	if r.ID == "" {
+		v.Add("id", util.ErrTenantIDIsEmpty)
+	} else {
+		v.Check(r.ID == id, "id", util.ErrTenantIDMismatch)
+	}
+
+	v.Check(r.Name == nil || *r.Name != "", "name", util.ErrTenantNameIsEmpty)
+
+	if r.Email != nil && *r.Email == "" {
+		v.Add("email", util.ErrTenantEmailIsEmpty)
+	} else if r.Email != nil {
+		v.Check(util.IsEmail(*r.Email), "email", util.ErrTenantEmailIsInvalid)
+	}
+
+	v.Check(r.PhoneNumber == nil || *r.PhoneNumber == "" || util.IsPhoneNumber(*r.PhoneNumber), "phone_number", util.ErrTenantPhoneNumberIsInvalid)
+	v.Check(r.Tier == nil || r.Tier.IsValid(), "tier", util.ErrTenantInvalidTier)
+
+	if r.CustomDomains != nil {
+		validateCustomDomains(&v, *r.CustomDomains)
+	}
+
+	return v.Err()
+}
+
+type PartiallyUpdateTenant interface {
+	Do(ctx context.Context, id string, request *PartiallyUpdateTenantRequest) (*entity.Tenant, error)
+}
+
+type partiallyUpdateTenant struct {
+	DBWriter database.DBWriter
+	DBReader database.DBReader
+	Keys     security.KeyProvider
+}
+
+func NewPartiallyUpdateTenant(app app.IApp) PartiallyUpdateTenant {
+	systemMongoDBClient := app.GetSystemMongoDBClient()
+	return &partiallyUpdateTenant{
+		DBWriter: database.NewDatabaseWriter(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+		DBReader: database.NewDatabaseReader(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+		Keys:     app.GetKeyProvider(),
+	}
+}
+
+// Do updates only the fields of the tenant that are present in request.
+func (uc *partiallyUpdateTenant) Do(ctx context.Context, id string, request *PartiallyUpdateTenantRequest) (*entity.Tenant, error) {
+	if err := request.Validate(id); err != nil {
+		log.Logger.Info(fmt.Errorf("could not update tenant: %w", err).Error())
+		return nil, err
+	}
+
+	var databaseName string
+	if request.DatabaseName != nil {
+		databaseName = *request.DatabaseName
+	}
+
+	var tier entity.TenantTier
+	if request.Tier != nil {
+		tier = *request.Tier
+	}
+
+	tenant, err := getEditableTenant(ctx, uc.DBReader, id, databaseName, tier)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Name != nil {
+		tenant.Name = *request.Name
+	}
+
+	if request.PhoneNumber != nil {
+		tenant.PhoneNumber = *request.PhoneNumber
+	}
+
+	if request.Email != nil {
+		tenant.Email = *request.Email
+	}
+
+	if request.Tier != nil {
+		tenant.Tier = *request.Tier
+	}
+
+	if request.MongoDBConnectionString != nil && *request.MongoDBConnectionString != "" {
+		tenant.MongoDBConnectionString, err = security.Encrypt(uc.Keys, *request.MongoDBConnectionString, tenant.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if request.CustomDomains != nil {
+		if err := setCustomDomains(tenant, *request.CustomDomains); err != nil {
+			return nil, err
+		}
+	}
+
+	tenant.UpdatedBy = &request.UpdatedBy
+
+	return uc.DBWriter.UpdateTenant(ctx, tenant)
+}
@@ -0,0 +1,34 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/log"
+)
+
+type RestoreTenant interface {
+	Do(ctx context.Context, id string) error
+}
+
+type restoreTenant struct {
+	DBWriter database.DBWriter
+}
+
+func NewRestoreTenant(app app.IApp) RestoreTenant {
+	systemMongoDBClient := app.GetSystemMongoDBClient()
+	return &restoreTenant{
+		DBWriter: database.NewDatabaseWriter(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+	}
+}
+
+func (uc *restoreTenant) Do(ctx context.Context, id string) error {
+	err := uc.DBWriter.RestoreTenant(ctx, id)
+	if err != nil {
+		log.Logger.Info(fmt.Errorf("could not restore tenant: %w", err).Error())
+		return err
+	}
+	return nil
+}
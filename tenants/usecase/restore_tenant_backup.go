@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database/backup"
+	"github.com/Neniel/gotennis/lib/log"
+)
+
+type RestoreTenantBackup interface {
+	Do(ctx context.Context, id string, databaseName string, r io.Reader) (*backup.Manifest, error)
+}
+
+type restoreTenantBackup struct {
+	App app.IApp
+}
+
+func NewRestoreTenantBackup(app app.IApp) RestoreTenantBackup {
+	return &restoreTenantBackup{
+		App: app,
+	}
+}
+
+// Do restores the archive read from r into the database with the given name, in the cluster of
+// the tenant. The database of the tenant is used when no name is given, but either way it must
+// be new or empty, so backups of tenants in a shared database go to another one.
+func (uc *restoreTenantBackup) Do(ctx context.Context, id string, databaseName string, r io.Reader) (*backup.Manifest, error) {
+	// The status is not checked, so that backups can also be restored into suspended tenants
+	client, err := uc.App.GetTenantMongoDBClientOfAnyStatus(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if databaseName == "" {
+		databaseName = client.DatabaseName
+	}
+
+	manifest, err := backup.Restore(ctx, client.MongoDBClient.Database(databaseName), r)
+	if err != nil {
+		log.Logger.Info(fmt.Errorf("could not restore backup of tenant '%s' into '%s': %w", id, databaseName, err).Error())
+		return nil, err
+	}
+
+	log.Logger.Info(fmt.Sprintf("backup of tenant '%s' created at %s restored into '%s'", manifest.TenantID, manifest.CreatedAt, databaseName))
+	return manifest, nil
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/security"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+type RotateTenantKeys interface {
+	Do(ctx context.Context) (int, []string, error)
+}
+
+type rotateTenantKeys struct {
+	DBWriter database.DBWriter
+	Keys     security.KeyProvider
+}
+
+// NewRotateTenantKeys only needs the system database, since the connection strings are kept there.
+func NewRotateTenantKeys(system *app.SystemMongoDB, keys security.KeyProvider) RotateTenantKeys {
+	return &rotateTenantKeys{
+		DBWriter: database.NewDatabaseWriter(system.MongoDBClient, system.DatabaseName),
+		Keys:     keys,
+	}
+}
+
+// Do encrypts again the connection string of every tenant with the current master key, bound to the
+// tenant, including the ones stored unencrypted or not bound to any tenant yet. It returns how many
+// tenants were encrypted and the IDs of the ones skipped because they were updated meanwhile, which
+// may need to be rotated again. The previous keys must still be provided until it is done.
+func (uc *rotateTenantKeys) Do(ctx context.Context) (int, []string, error) {
+	if uc.Keys == nil {
+		return 0, nil, fmt.Errorf("%w: set MASTER_KEYS or MASTER_KEYFILE", util.ErrEncryptionKeyNotFound)
+	}
+
+	rotated, skipped, err := uc.DBWriter.RewriteTenantConnectionStrings(ctx, func(id string, connectionString string) (string, error) {
+		plaintext, err := security.Decrypt(uc.Keys, connectionString, id)
+		if err != nil {
+			return "", err
+		}
+
+		return security.Encrypt(uc.Keys, plaintext, id)
+	})
+	if err != nil {
+		log.Logger.Error(fmt.Errorf("could not rotate keys of tenants, %d were encrypted with the current key: %w", rotated, err).Error())
+		return rotated, skipped, err
+	}
+
+	if len(skipped) > 0 {
+		log.Logger.Info(fmt.Sprintf("connection strings of tenants %s were updated while rotating keys and were skipped", strings.Join(skipped, ", ")))
+	}
+
+	log.Logger.Info(fmt.Sprintf("connection strings of %d tenants encrypted with the current key", rotated))
+	return rotated, skipped, nil
+}
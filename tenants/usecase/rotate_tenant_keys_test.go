@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/security"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_rotateTenantKeys_Do(t *testing.T) {
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+
+	const (
+		previousKey = "previous:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+		currentKey  = "current:AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="
+	)
+
+	previous, err := security.NewKeyring(previousKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotating, err := security.NewKeyring(currentKey + "," + previousKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current, err := security.NewKeyring(currentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := primitive.NewObjectID().Hex()
+	encrypted, err := security.Encrypt(previous, "mongodb://club:27017", id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		keys           security.KeyProvider
+		prepareUsecase func()
+		want           int
+		wantSkipped    []string
+		wantErr        error
+	}{
+		{
+			name: "Encrypts_connection_strings_with_the_current_key",
+			keys: rotating,
+			prepareUsecase: func() {
+				dbWriter.EXPECT().RewriteTenantConnectionStrings(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rewrite func(string, string) (string, error)) (int, []string, error) {
+					for _, connectionString := range []string{encrypted, "mongodb://club:27017"} {
+						rewritten, err := rewrite(id, connectionString)
+						if err != nil {
+							return 0, nil, err
+						}
+
+						if plaintext, err := security.Decrypt(current, rewritten, id); err != nil || plaintext != "mongodb://club:27017" {
+							t.Errorf("connection string is not encrypted with the current key: %v, %v", plaintext, err)
+						}
+					}
+					return 2, []string{"updated"}, nil
+				})
+			},
+			want:        2,
+			wantSkipped: []string{"updated"},
+		},
+		{
+			name: "Fails_when_a_connection_string_cannot_be_decrypted",
+			keys: current,
+			prepareUsecase: func() {
+				dbWriter.EXPECT().RewriteTenantConnectionStrings(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, rewrite func(string, string) (string, error)) (int, []string, error) {
+					_, err := rewrite(id, encrypted)
+					return 0, nil, err
+				})
+			},
+			wantErr: util.ErrEncryptionKeyNotFound,
+		},
+		{
+			name:           "Fails_without_keys",
+			prepareUsecase: func() {},
+			wantErr:        util.ErrEncryptionKeyNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			uc := &rotateTenantKeys{DBWriter: dbWriter, Keys: tt.keys}
+			got, skipped, err := uc.Do(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("rotateTenantKeys.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want || !reflect.DeepEqual(skipped, tt.wantSkipped) {
+				t.Errorf("rotateTenantKeys.Do() = %v, %v, want %v, %v", got, skipped, tt.want, tt.wantSkipped)
+			}
+		})
+	}
+}
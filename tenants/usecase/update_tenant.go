@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/security"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+type UpdateTenantRequest struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	PhoneNumber string            `json:"phone_number"`
+	Email       string            `json:"email"`
+	Tier        entity.TenantTier `json:"tier"`
+	// MongoDBConnectionString is never returned, so the current one is kept when it is empty
+	MongoDBConnectionString string `json:"mongo_db_connection_string"`
+	DatabaseName            string `json:"database_name"`
+	// CustomDomains are kept when missing, while an empty list removes them
+	CustomDomains []string `json:"custom_domains"`
+	UpdatedBy     string   `json:"-"`
+}
+
+func (r *UpdateTenantRequest) Validate(id string) error {
+	if r.Tier == "" {
+		r.Tier = entity.TenantTierStandard
+	}
+
+	var v util.Validator
+	if r.ID == "" {
+		v.Add("id", util.ErrTenantIDIsEmpty)
+	} else {
+		v.Check(r.ID == id, "id", util.ErrTenantIDMismatch)
+	}
+
+	validateTenantFields(&v, r.Name, r.Email, r.PhoneNumber, r.Tier)
+	validateCustomDomains(&v, r.CustomDomains)
+	return v.Err()
+}
+
+type UpdateTenant interface {
+	Do(ctx context.Context, id string, request *UpdateTenantRequest) (*entity.Tenant, error)
+}
+
+type updateTenant struct {
+	DBWriter database.DBWriter
+	DBReader database.DBReader
+	Keys     security.KeyProvider
+}
+
+func NewUpdateTenant(app app.IApp) UpdateTenant {
+	systemMongoDBClient := app.GetSystemMongoDBClient()
+	return &updateTenant{
+		DBWriter: database.NewDatabaseWriter(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+		DBReader: database.NewDatabaseReader(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+		Keys:     app.GetKeyProvider(),
+	}
+}
+
+func (uc *updateTenant) Do(ctx context.Context, id string, request *UpdateTenantRequest) (*entity.Tenant, error) {
+	if err := request.Validate(id); err != nil {
+		log.Logger.Info(fmt.Errorf("could not update tenant: %w", err).Error())
+		return nil, err
+	}
+
+	tenant, err := getEditableTenant(ctx, uc.DBReader, id, request.DatabaseName, request.Tier)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant.Name = request.Name
+	tenant.PhoneNumber = request.PhoneNumber
+	tenant.Email = request.Email
+	tenant.Tier = request.Tier
+	if request.MongoDBConnectionString != "" {
+		tenant.MongoDBConnectionString, err = security.Encrypt(uc.Keys, request.MongoDBConnectionString, tenant.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if request.CustomDomains != nil {
+		if err := setCustomDomains(tenant, request.CustomDomains); err != nil {
+			return nil, err
+		}
+	}
+
+	tenant.UpdatedBy = &request.UpdatedBy
+
+	return uc.DBWriter.UpdateTenant(ctx, tenant)
+}
+
+// setCustomDomains replaces the custom domains of tenant with domains. The ones it already had
+// keep their verification, while new ones get a token to be verified with.
+func setCustomDomains(tenant *entity.Tenant, domains []string) error {
+	current := make(map[string]entity.CustomDomain, len(tenant.CustomDomains))
+	for _, customDomain := range tenant.CustomDomains {
+		current[customDomain.Domain] = customDomain
+	}
+
+	customDomains := make([]entity.CustomDomain, 0, len(domains))
+	seen := make(map[string]bool, len(domains))
+	for _, domain := range domains {
+		domain = normalizeDomain(domain)
+		if !util.IsDomainName(domain) {
+			return util.ErrTenantCustomDomainIsInvalid
+		}
+
+		if seen[domain] {
+			continue
+		}
+		seen[domain] = true
+
+		customDomain, ok := current[domain]
+		if !ok {
+			token := make([]byte, 16)
+			if _, err := rand.Read(token); err != nil {
+				return err
+			}
+
+			customDomain = entity.CustomDomain{Domain: domain, VerificationToken: hex.EncodeToString(token)}
+		}
+
+		customDomains = append(customDomains, customDomain)
+	}
+
+	tenant.CustomDomains = customDomains
+	return nil
+}
+
+// validateCustomDomains adds to v the domains that are not valid, by their index.
+func validateCustomDomains(v *util.Validator, domains []string) {
+	for i, domain := range domains {
+		v.Check(util.IsDomainName(normalizeDomain(domain)), fmt.Sprintf("custom_domains[%d]", i), util.ErrTenantCustomDomainIsInvalid)
+	}
+}
+
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+}
+
+// getEditableTenant returns the tenant to be updated, as long as it has not been offboarded and
+// neither databaseName nor tier, when given, would move its documents to another database.
+func getEditableTenant(ctx context.Context, dbReader database.DBReader, id string, databaseName string, tier entity.TenantTier) (*entity.Tenant, error) {
+	tenant, err := dbReader.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if tenant.GetStatus() == entity.TenantStatusOffboarded {
+		return nil, util.ErrTenantIsOffboarded
+	}
+
+	if databaseName != "" && databaseName != tenant.DatabaseName {
+		return nil, util.ErrTenantDatabaseNameIsImmutable
+	}
+
+	if tier != "" && (tier == entity.TenantTierShared) != tenant.HasSharedDatabase() {
+		return nil, util.ErrTenantSharedTierIsImmutable
+	}
+
+	return tenant, nil
+}
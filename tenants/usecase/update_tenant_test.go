@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/security"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_updateTenant_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+	keys, err := security.NewKeyring("current:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := primitive.NewObjectID()
+	verifiedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	storedTenant := func() *entity.Tenant {
+		return &entity.Tenant{
+			ID:                      id,
+			Name:                    "Club",
+			Email:                   "club@test.com",
+			Tier:                    entity.TenantTierStandard,
+			MongoDBConnectionString: "enc:v2:stored",
+			DatabaseName:            "club",
+			CustomDomains:           []entity.CustomDomain{{Domain: "club.com", VerificationToken: "token", VerifiedAt: &verifiedAt}},
+		}
+	}
+
+	request := func() *UpdateTenantRequest {
+		return &UpdateTenantRequest{ID: id.Hex(), Name: "Club A", Email: "club@test.com", UpdatedBy: "admin"}
+	}
+
+	tests := []struct {
+		name           string
+		request        func() *UpdateTenantRequest
+		prepareUsecase func()
+		wantErr        error
+	}{
+		{
+			name:    "Keeps_the_connection_string_and_the_custom_domains_when_missing",
+			request: request,
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+				dbWriter.EXPECT().UpdateTenant(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tenant *entity.Tenant) (*entity.Tenant, error) {
+					if tenant.Name != "Club A" || tenant.MongoDBConnectionString != "enc:v2:stored" || len(tenant.CustomDomains) != 1 {
+						t.Errorf("tenant is not updated as requested: %+v", tenant)
+					}
+					return tenant, nil
+				})
+			},
+		},
+		{
+			name: "Encrypts_new_connection_strings_for_the_tenant",
+			request: func() *UpdateTenantRequest {
+				r := request()
+				r.MongoDBConnectionString = "mongodb://club:27017"
+				return r
+			},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+				dbWriter.EXPECT().UpdateTenant(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tenant *entity.Tenant) (*entity.Tenant, error) {
+					if connectionString, err := security.Decrypt(keys, tenant.MongoDBConnectionString, id.Hex()); err != nil || connectionString != "mongodb://club:27017" {
+						t.Errorf("connection string is not encrypted for the tenant: %v, %v", connectionString, err)
+					}
+					return tenant, nil
+				})
+			},
+		},
+		{
+			name: "Keeps_the_verification_of_the_custom_domains_kept",
+			request: func() *UpdateTenantRequest {
+				r := request()
+				r.CustomDomains = []string{"Club.com.", "www.club.com"}
+				return r
+			},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+				dbWriter.EXPECT().UpdateTenant(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tenant *entity.Tenant) (*entity.Tenant, error) {
+					if len(tenant.CustomDomains) != 2 || !tenant.CustomDomains[0].IsVerified() || tenant.CustomDomains[1].IsVerified() || tenant.CustomDomains[1].VerificationToken == "" {
+						t.Errorf("custom domains are not updated as requested: %+v", tenant.CustomDomains)
+					}
+					return tenant, nil
+				})
+			},
+		},
+		{
+			name: "Fails_when_moving_the_tenant_to_another_database",
+			request: func() *UpdateTenantRequest {
+				r := request()
+				r.DatabaseName = "other"
+				return r
+			},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+			},
+			wantErr: util.ErrTenantDatabaseNameIsImmutable,
+		},
+		{
+			name:    "Fails_when_tenant_is_offboarded",
+			request: request,
+			prepareUsecase: func() {
+				tenant := storedTenant()
+				tenant.Status = entity.TenantStatusOffboarded
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(tenant, nil)
+			},
+			wantErr: util.ErrTenantIsOffboarded,
+		},
+		{
+			name:    "Fails_when_tenant_has_been_modified_meanwhile",
+			request: request,
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+				dbWriter.EXPECT().UpdateTenant(gomock.Any(), gomock.Any()).Return(nil, util.ErrTenantIsModified)
+			},
+			wantErr: util.ErrTenantIsModified,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			uc := &updateTenant{DBWriter: dbWriter, DBReader: dbReader, Keys: keys}
+			if _, err := uc.Do(context.Background(), id.Hex(), tt.request()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("updateTenant.Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/app"
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type VerifyTenantDomain interface {
+	Do(ctx context.Context, id string, domain string, updatedBy string) (*entity.Tenant, error)
+}
+
+type verifyTenantDomain struct {
+	DBWriter  database.DBWriter
+	DBReader  database.DBReader
+	LookupTXT func(ctx context.Context, name string) ([]string, error)
+}
+
+func NewVerifyTenantDomain(app app.IApp) VerifyTenantDomain {
+	systemMongoDBClient := app.GetSystemMongoDBClient()
+	return &verifyTenantDomain{
+		DBWriter:  database.NewDatabaseWriter(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+		DBReader:  database.NewDatabaseReader(systemMongoDBClient.MongoDBClient, systemMongoDBClient.DatabaseName),
+		LookupTXT: net.DefaultResolver.LookupTXT,
+	}
+}
+
+// Do verifies the custom domain of the tenant by looking for its token in the TXT record of the
+// domain, see entity.CustomDomain. A domain can only be verified by one tenant.
+func (uc *verifyTenantDomain) Do(ctx context.Context, id string, domain string, updatedBy string) (*entity.Tenant, error) {
+	tenant, err := getEditableTenant(ctx, uc.DBReader, id, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	i := slices.IndexFunc(tenant.CustomDomains, func(d entity.CustomDomain) bool { return d.Domain == domain })
+	if i < 0 {
+		return nil, util.ErrTenantCustomDomainNotFound
+	}
+
+	customDomain := &tenant.CustomDomains[i]
+	if customDomain.IsVerified() {
+		return tenant, nil
+	}
+
+	owner, err := uc.DBReader.GetTenantByCustomDomain(ctx, domain)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	if owner != nil && owner.ID != tenant.ID {
+		return nil, util.ErrTenantCustomDomainIsTaken
+	}
+
+	records, err := uc.LookupTXT(ctx, customDomain.VerificationRecord())
+	if err != nil {
+		log.Logger.Info(fmt.Errorf("could not look up TXT record of domain '%s': %w", domain, err).Error())
+		return nil, util.ErrTenantCustomDomainIsNotVerified
+	}
+
+	if !slices.Contains(records, customDomain.VerificationToken) {
+		return nil, util.ErrTenantCustomDomainIsNotVerified
+	}
+
+	customDomain.VerifiedAt = util.ToPtr(time.Now().UTC())
+	tenant.UpdatedBy = &updatedBy
+
+	tenant, err = uc.DBWriter.UpdateTenant(ctx, tenant)
+	if err != nil {
+		log.Logger.Error(fmt.Errorf("could not verify domain '%s' of tenant '%s': %w", domain, id, err).Error())
+		return nil, err
+	}
+
+	log.Logger.Info(fmt.Sprintf("domain '%s' of tenant '%s' verified", domain, id))
+	return tenant, nil
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_verifyTenantDomain_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+
+	id := primitive.NewObjectID()
+	storedTenant := func() *entity.Tenant {
+		return &entity.Tenant{
+			ID:            id,
+			Name:          "Club",
+			CustomDomains: []entity.CustomDomain{{Domain: "club.com", VerificationToken: "token"}},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		domain         string
+		records        []string
+		prepareUsecase func()
+		wantErr        error
+	}{
+		{
+			name:    "Verifies_domains_publishing_the_token",
+			domain:  "Club.com.",
+			records: []string{"other", "token"},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+				dbReader.EXPECT().GetTenantByCustomDomain(gomock.Any(), "club.com").Return(nil, mongo.ErrNoDocuments)
+				dbWriter.EXPECT().UpdateTenant(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tenant *entity.Tenant) (*entity.Tenant, error) {
+					if !tenant.CustomDomains[0].IsVerified() || *tenant.UpdatedBy != "admin" {
+						t.Errorf("domain is not verified: %+v", tenant)
+					}
+					return tenant, nil
+				})
+			},
+		},
+		{
+			name:   "Keeps_domains_verified_already",
+			domain: "club.com",
+			prepareUsecase: func() {
+				tenant := storedTenant()
+				tenant.CustomDomains[0].VerifiedAt = util.ToPtr(time.Now().UTC())
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(tenant, nil)
+			},
+		},
+		{
+			name:    "Fails_when_token_is_not_published",
+			domain:  "club.com",
+			records: []string{"other"},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+				dbReader.EXPECT().GetTenantByCustomDomain(gomock.Any(), "club.com").Return(nil, mongo.ErrNoDocuments)
+			},
+			wantErr: util.ErrTenantCustomDomainIsNotVerified,
+		},
+		{
+			name:    "Fails_when_domain_is_verified_by_another_tenant",
+			domain:  "club.com",
+			records: []string{"token"},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+				dbReader.EXPECT().GetTenantByCustomDomain(gomock.Any(), "club.com").Return(&entity.Tenant{ID: primitive.NewObjectID()}, nil)
+			},
+			wantErr: util.ErrTenantCustomDomainIsTaken,
+		},
+		{
+			name:    "Fails_when_another_tenant_verifies_the_domain_meanwhile",
+			domain:  "club.com",
+			records: []string{"token"},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+				dbReader.EXPECT().GetTenantByCustomDomain(gomock.Any(), "club.com").Return(nil, mongo.ErrNoDocuments)
+				dbWriter.EXPECT().UpdateTenant(gomock.Any(), gomock.Any()).Return(nil, util.ErrTenantCustomDomainIsTaken)
+			},
+			wantErr: util.ErrTenantCustomDomainIsTaken,
+		},
+		{
+			name:   "Fails_when_tenant_does_not_have_the_domain",
+			domain: "other.com",
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTenant(gomock.Any(), id.Hex()).Return(storedTenant(), nil)
+			},
+			wantErr: util.ErrTenantCustomDomainNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			uc := &verifyTenantDomain{
+				DBWriter: dbWriter,
+				DBReader: dbReader,
+				LookupTXT: func(ctx context.Context, name string) ([]string, error) {
+					if name != "_gotennis-verification.club.com" {
+						t.Errorf("TXT record looked up at %s", name)
+					}
+					return tt.records, nil
+				},
+			}
+			if _, err := uc.Do(context.Background(), id.Hex(), tt.domain, "admin"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifyTenantDomain.Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,19 +2,21 @@ package main
 
 import (
 	"encoding/json"
-	"errors"
+	"fmt"
 
 	"net/http"
 	"os"
 
 	"github.com/Neniel/gotennis/tournaments/usecase"
-	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/Neniel/gotennis/lib/app"
-	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
+	"github.com/Neniel/gotennis/lib/etag"
 	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/middleware"
+	"github.com/Neniel/gotennis/lib/problem"
 	"github.com/Neniel/gotennis/lib/telemetry/grafana"
+	"github.com/Neniel/gotennis/lib/util"
 )
 
 type Usecases struct {
@@ -22,7 +24,11 @@ type Usecases struct {
 	ListTournaments  usecase.ListTournaments
 	GetTournament    usecase.GetTournament
 	UpdateTournament usecase.UpdateTournament
+	PatchTournament  usecase.PatchTournament
 	DeleteTournament usecase.DeleteTournament
+	ChangeStatus     usecase.ChangeTournamentStatus
+	Restore          usecase.RestoreTournament
+	Export           usecase.ExportTournaments
 }
 
 type TournamentMicroservice struct {
@@ -51,12 +57,17 @@ func (api *APIServer) Run() {
 
 	mux.HandleFunc("GET /ping", api.pingHandler)
 	mux.HandleFunc("GET /tournaments", api.listTournaments)
+	mux.HandleFunc("GET /tournaments/export", api.exportTournaments)
 	mux.HandleFunc("GET /tournaments/{id}", api.getTournament)
-	mux.HandleFunc("POST /tournaments", api.addTournament)
+	mux.Handle("POST /tournaments", api.TournamentMicroservice.App.GetIdempotency().Middleware(http.HandlerFunc(api.addTournament)))
 	mux.HandleFunc("PUT /tournaments/{id}", api.updateTournament)
+	mux.HandleFunc("PATCH /tournaments/{id}", api.patchTournament)
+	mux.HandleFunc("PUT /tournaments/{id}/status", api.changeTournamentStatus)
 	mux.HandleFunc("DELETE /tournaments/{id}", api.deleteTournament)
+	mux.HandleFunc("POST /tournaments/{id}/restore", api.restoreTournament)
 
-	log.Logger.Error(http.ListenAndServe(os.Getenv("APP_PORT"), mux).Error())
+	handler := middleware.TenantMiddleware(middleware.RateLimitMiddleware(mux, api.TournamentMicroservice.App.APICallsPerMinute), api.TournamentMicroservice.App.CheckTenant)
+	log.Logger.Error(http.ListenAndServe(os.Getenv("APP_PORT"), api.TournamentMicroservice.App.GetTenantResolver().Middleware(handler)).Error())
 }
 
 func (api *APIServer) pingHandler(w http.ResponseWriter, r *http.Request) {
@@ -72,6 +83,15 @@ func (api *APIServer) listTournaments(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Access-Control-Allow-Origin", "*")
 	w.Header().Add("Content-Type", "application/json")
 
+	q, err := query.Parse(r.URL.Query(), usecase.TournamentsQuerySchema)
+	if err != nil {
+		grafana.SendMetric("tournament.list", 1, 1, map[string]interface{}{
+			"status_code": http.StatusBadRequest,
+		})
+		problem.Write(w, r, err)
+		return
+	}
+
 	/*
 	   1. recibir el token
 	   2. validar el token
@@ -82,23 +102,23 @@ func (api *APIServer) listTournaments(w http.ResponseWriter, r *http.Request) {
 
 	client, err := api.TournamentMicroservice.App.GetTenantMongoDBClient(tenantID)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte("invalid value in header X-Tenant-ID"))
+		problem.Write(w, r, err)
 		return
 	}
 
-	listTournaments := usecase.NewListTournaments(database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+	listTournaments := usecase.NewListTournaments(client.DBReader())
 
-	tournaments, err := listTournaments.Do(r.Context())
+	tournaments, err := listTournaments.Do(r.Context(), q)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
 		grafana.SendMetric("tournament.list", 1, 1, map[string]interface{}{
-			"status_code": http.StatusInternalServerError,
+			"status_code": problem.StatusCode(err),
 		})
+		problem.Write(w, r, err)
 		return
 	}
 
-	err = json.NewEncoder(w).Encode(&tournaments)
+	query.SetPaginationHeaders(w, r, tournaments.NextCursor)
+	err = json.NewEncoder(w).Encode(&tournaments.Items)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		grafana.SendMetric("tournament.list", 1, 1, map[string]interface{}{
@@ -127,30 +147,22 @@ func (api *APIServer) getTournament(w http.ResponseWriter, r *http.Request) {
 
 		client, err := api.TournamentMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("invalid value in header X-Tenant-ID"))
+			problem.Write(w, r, err)
 			return
 		}
 
-		getTournament := usecase.NewGetTournament(database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+		getTournament := usecase.NewGetTournament(client.DBReader())
 
 		categories, err := getTournament.Do(r.Context(), id)
-		if errors.Is(err, primitive.ErrInvalidHex) {
-			w.WriteHeader(http.StatusBadRequest)
-			grafana.SendMetric("tournament.get", 1, 1, map[string]interface{}{
-				"status_code": http.StatusBadRequest,
-			})
-			return
-		}
-
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			w.WriteHeader(http.StatusNotFound)
+		if err != nil {
 			grafana.SendMetric("tournament.get", 1, 1, map[string]interface{}{
-				"status_code": http.NotFound,
+				"status_code": problem.StatusCode(err),
 			})
+			problem.Write(w, r, err)
 			return
 		}
 
+		etag.Set(w, categories.Version)
 		err = json.NewEncoder(w).Encode(&categories)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -176,8 +188,7 @@ func (api *APIServer) addTournament(w http.ResponseWriter, r *http.Request) {
 		grafana.SendMetric("tournaments.add", 1, 1, map[string]interface{}{
 			"status_code": http.StatusBadRequest,
 		})
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -191,20 +202,18 @@ func (api *APIServer) addTournament(w http.ResponseWriter, r *http.Request) {
 
 	client, err := api.TournamentMicroservice.App.GetTenantMongoDBClient(tenantID)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		w.Write([]byte("invalid value in header X-Tenant-ID"))
+		problem.Write(w, r, err)
 		return
 	}
 
-	createTournament := usecase.NewCreateTournament(database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName))
+	createTournament := usecase.NewCreateTournament(client.DBWriter(), client.DBReader(), client.Plan)
 
 	tournament, err := createTournament.CreateTournament(r.Context(), &request)
 	if err != nil {
 		grafana.SendMetric("tournaments.add", 1, 1, map[string]interface{}{
-			"status_code": http.StatusBadRequest,
+			"status_code": problem.StatusCode(err),
 		})
-		w.WriteHeader(http.StatusBadRequest)
-		w.Write([]byte(err.Error()))
+		problem.Write(w, r, err)
 		return
 	}
 
@@ -215,7 +224,6 @@ func (api *APIServer) addTournament(w http.ResponseWriter, r *http.Request) {
 			"status_code": http.StatusInternalServerError,
 		})
 		w.WriteHeader(http.StatusInternalServerError)
-		w.Write([]byte(err.Error()))
 		return
 	}
 
@@ -234,8 +242,16 @@ func (api *APIServer) updateTournament(w http.ResponseWriter, r *http.Request) {
 			grafana.SendMetric("tournaments.update", 1, 1, map[string]interface{}{
 				"status_code": http.StatusBadRequest,
 			})
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
+			return
+		}
+
+		request.Version, err = etag.IfMatch(r)
+		if err != nil {
+			grafana.SendMetric("tournaments.update", 1, 1, map[string]interface{}{
+				"status_code": problem.StatusCode(err),
+			})
+			problem.Write(w, r, err)
 			return
 		}
 
@@ -249,23 +265,22 @@ func (api *APIServer) updateTournament(w http.ResponseWriter, r *http.Request) {
 
 		client, err := api.TournamentMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("invalid value in header X-Tenant-ID"))
+			problem.Write(w, r, err)
 			return
 		}
 
-		updateTournament := usecase.NewUpdateTournament(database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName), database.NewDatabaseReader(client.MongoDBClient, client.DatabaseName))
+		updateTournament := usecase.NewUpdateTournament(client.DBWriter(), client.DBReader(), client.Plan)
 
 		category, err := updateTournament.Do(r.Context(), id, &request)
 		if err != nil {
 			grafana.SendMetric("tournaments.update", 1, 1, map[string]interface{}{
-				"status_code": http.StatusBadRequest,
+				"status_code": problem.StatusCode(err),
 			})
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte(err.Error()))
+			problem.Write(w, r, err)
 			return
 		}
 
+		etag.Set(w, category.Version)
 		w.WriteHeader(http.StatusOK)
 		err = json.NewEncoder(w).Encode(&category)
 		if err != nil {
@@ -273,16 +288,34 @@ func (api *APIServer) updateTournament(w http.ResponseWriter, r *http.Request) {
 				"status_code": http.StatusInternalServerError,
 			})
 			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(err.Error()))
 			return
 		}
 	}
 }
 
-func (api *APIServer) deleteTournament(w http.ResponseWriter, r *http.Request) {
+func (api *APIServer) patchTournament(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Access-Control-Allow-Origin", "*")
 	w.Header().Add("Content-Type", "application/json")
 	if id := r.PathValue("id"); id != "" {
+		var request usecase.PatchTournamentRequest
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			grafana.SendMetric("tournaments.patch", 1, 1, map[string]interface{}{
+				"status_code": http.StatusBadRequest,
+			})
+			problem.Write(w, r, err)
+			return
+		}
+
+		request.Version, err = etag.IfMatch(r)
+		if err != nil {
+			grafana.SendMetric("tournaments.patch", 1, 1, map[string]interface{}{
+				"status_code": problem.StatusCode(err),
+			})
+			problem.Write(w, r, err)
+			return
+		}
 
 		/*
 		   1. recibir el token
@@ -294,23 +327,212 @@ func (api *APIServer) deleteTournament(w http.ResponseWriter, r *http.Request) {
 
 		client, err := api.TournamentMicroservice.App.GetTenantMongoDBClient(tenantID)
 		if err != nil {
-			w.WriteHeader(http.StatusUnauthorized)
-			w.Write([]byte("invalid value in header X-Tenant-ID"))
+			problem.Write(w, r, err)
 			return
 		}
 
-		deleteTournament := usecase.NewDeleteTournament(database.NewDatabaseWriter(client.MongoDBClient, client.DatabaseName))
+		patchTournament := usecase.NewPatchTournament(client.DBWriter(), client.DBReader(), client.Plan)
 
-		err = deleteTournament.Do(r.Context(), id)
-		if errors.Is(err, primitive.ErrInvalidHex) {
-			w.WriteHeader(http.StatusBadRequest)
+		tournament, err := patchTournament.Do(r.Context(), id, &request)
+		if err != nil {
+			grafana.SendMetric("tournaments.patch", 1, 1, map[string]interface{}{
+				"status_code": problem.StatusCode(err),
+			})
+			problem.Write(w, r, err)
 			return
-		} else {
+		}
+
+		etag.Set(w, tournament.Version)
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(&tournament)
+		if err != nil {
+			grafana.SendMetric("tournaments.patch", 1, 1, map[string]interface{}{
+				"status_code": http.StatusInternalServerError,
+			})
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		grafana.SendMetric("tournaments.patch", 1, 1, map[string]interface{}{
+			"status_code": http.StatusOK,
+		})
+	}
+}
+
+func (api *APIServer) changeTournamentStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+		var request usecase.ChangeTournamentStatusRequest
+		defer r.Body.Close()
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			grafana.SendMetric("tournaments.status", 1, 1, map[string]interface{}{
+				"status_code": http.StatusBadRequest,
+			})
+			problem.Write(w, r, err)
+			return
+		}
+
+		request.Version, err = etag.IfMatch(r)
+		if err != nil {
+			grafana.SendMetric("tournaments.status", 1, 1, map[string]interface{}{
+				"status_code": problem.StatusCode(err),
+			})
+			problem.Write(w, r, err)
+			return
+		}
+
+		/*
+		   1. recibir el token
+		   2. validar el token
+		   3. obtener datos del token
+		*/
+
+		tenantID := r.Header.Get("X-Tenant-ID")
+
+		client, err := api.TournamentMicroservice.App.GetTenantMongoDBClient(tenantID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		changeStatus := usecase.NewChangeTournamentStatus(client.DBWriter(), client.DBReader())
+
+		tournament, err := changeStatus.Do(r.Context(), id, &request)
+		if err != nil {
+			grafana.SendMetric("tournaments.status", 1, 1, map[string]interface{}{
+				"status_code": problem.StatusCode(err),
+			})
+			problem.Write(w, r, err)
+			return
+		}
+
+		etag.Set(w, tournament.Version)
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(&tournament)
+		if err != nil {
+			grafana.SendMetric("tournaments.status", 1, 1, map[string]interface{}{
+				"status_code": http.StatusInternalServerError,
+			})
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
+
+		grafana.SendMetric("tournaments.status", 1, 1, map[string]interface{}{
+			"status_code": http.StatusOK,
+		})
+	}
+}
+
+func (api *APIServer) deleteTournament(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+
+		/*
+		   1. recibir el token
+		   2. validar el token
+		   3. obtener datos del token
+		*/
+
+		tenantID := r.Header.Get("X-Tenant-ID")
+
+		client, err := api.TournamentMicroservice.App.GetTenantMongoDBClient(tenantID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		version, err := etag.IfMatch(r)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		deleteTournament := usecase.NewDeleteTournament(client.DBWriter(), client.DBReader())
+
+		err = deleteTournament.Do(r.Context(), id, version, r.Header.Get("X-User-ID"))
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
 	} else {
-		w.WriteHeader(http.StatusBadRequest)
+		problem.Write(w, r, util.ErrInvalidID)
 		return
 	}
 }
+
+func (api *APIServer) restoreTournament(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Header().Add("Content-Type", "application/json")
+	if id := r.PathValue("id"); id != "" {
+
+		/*
+		   1. recibir el token
+		   2. validar el token
+		   3. obtener datos del token
+		*/
+
+		tenantID := r.Header.Get("X-Tenant-ID")
+
+		client, err := api.TournamentMicroservice.App.GetTenantMongoDBClient(tenantID)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		restoreTournament := usecase.NewRestoreTournament(client.DBWriter(), client.DBReader(), client.Plan)
+
+		err = restoreTournament.Do(r.Context(), id)
+		if err != nil {
+			problem.Write(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		problem.Write(w, r, util.ErrInvalidID)
+		return
+	}
+}
+
+func (api *APIServer) exportTournaments(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	format, err := util.ParseExportFormat(values.Get("format"))
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+	values.Del("format")
+
+	q, err := query.Parse(values, usecase.TournamentsQuerySchema)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	/*
+	   1. recibir el token
+	   2. validar el token
+	   3. obtener datos del token
+	*/
+
+	tenantID := r.Header.Get("X-Tenant-ID")
+
+	client, err := api.TournamentMicroservice.App.GetTenantMongoDBClient(tenantID)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	exportTournaments := usecase.NewExportTournaments(client.DBReader())
+
+	// The response is streamed, so once it has started an error can only be logged
+	util.SetExportHeaders(w, "tournaments", format)
+	if err := exportTournaments.Do(r.Context(), w, format, q); err != nil {
+		log.Logger.Error(fmt.Errorf("couldn't export tournaments: %w", err).Error())
+	}
+}
@@ -5,7 +5,7 @@ go 1.22.3
 require github.com/Neniel/gotennis/lib/database v0.0.0-20240602192022-f8de9f9ace57
 
 require (
-	github.com/Neniel/gotennis/lib v0.0.0-20240602192022-f8de9f9ace57 // indirect
+	github.com/Neniel/gotennis/lib v0.0.0-20240602192022-f8de9f9ace57
 	github.com/Neniel/gotennis/lib/config v0.0.0-20240602192022-f8de9f9ace57 // indirect
 )
 
@@ -14,7 +14,7 @@ require (
 	github.com/Neniel/gotennis/lib/entity v0.0.0-20240602192022-f8de9f9ace57
 	github.com/Neniel/gotennis/lib/log v0.0.0-20240602192022-f8de9f9ace57
 	github.com/Neniel/gotennis/lib/telemetry v0.0.0-20240602192022-f8de9f9ace57
-	github.com/Neniel/gotennis/lib/util v0.0.0-20240602192022-f8de9f9ace57 // indirect
+	github.com/Neniel/gotennis/lib/util v0.0.0-20240602192022-f8de9f9ace57
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/klauspost/compress v1.17.8 // indirect
 	github.com/montanaflynn/stats v0.7.1 // indirect
@@ -23,7 +23,7 @@ require (
 	github.com/xdg-go/stringprep v1.0.4 // indirect
 	github.com/youmark/pkcs8 v0.0.0-20240424034433-3c2c7870ae76 // indirect
 	go.mongodb.org/mongo-driver v1.15.0
-	go.uber.org/mock v0.4.0 // indirect
+	go.uber.org/mock v0.4.0
 	golang.org/x/crypto v0.23.0 // indirect
 	golang.org/x/sync v0.7.0 // indirect
 	golang.org/x/text v0.15.0 // indirect
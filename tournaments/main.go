@@ -22,5 +22,8 @@ func main() {
 		*/
 	}
 
+	go app.StartPurge(context.Background(), "tournaments")
+	go app.WatchTenants(context.Background())
+
 	ms.NewAPIServer().Run()
 }
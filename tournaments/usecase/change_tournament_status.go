@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+type ChangeTournamentStatusRequest struct {
+	Status entity.TournamentStatus `json:"status"`
+	// Version is the one the update is based on, taken from header If-Match. When set, the update
+	// fails with util.ErrPreconditionFailed if the tournament has been written since
+	Version *int64 `json:"-"`
+}
+
+func (r *ChangeTournamentStatusRequest) Validate() error {
+	var v util.Validator
+	v.Check(r.Status.IsValid(), "status", util.ErrTournamentInvalidStatus)
+	return v.Err()
+}
+
+type ChangeTournamentStatus interface {
+	Do(ctx context.Context, id string, request *ChangeTournamentStatusRequest) (*entity.Tournament, error)
+}
+
+type changeTournamentStatus struct {
+	DBWriter database.DBWriter
+	DBReader database.DBReader
+}
+
+func NewChangeTournamentStatus(dbWriter database.DBWriter, dbReader database.DBReader) ChangeTournamentStatus {
+	return &changeTournamentStatus{
+		DBWriter: dbWriter,
+		DBReader: dbReader,
+	}
+}
+
+func (u *changeTournamentStatus) Do(ctx context.Context, id string, request *ChangeTournamentStatusRequest) (*entity.Tournament, error) {
+	if err := request.Validate(); err != nil {
+		return nil, err
+	}
+
+	tournament, err := u.DBReader.GetTournament(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := util.CheckVersion(request.Version, tournament.Version); err != nil {
+		return nil, err
+	}
+
+	if !tournament.CanTransitionTo(request.Status) {
+		return nil, util.ErrTournamentInvalidStatusTransition
+	}
+
+	tournament.Status = request.Status
+
+	return u.DBWriter.UpdateTournament(ctx, tournament)
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.uber.org/mock/gomock"
+)
+
+func TestChangeTournamentStatusRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  entity.TournamentStatus
+		wantErr bool
+	}{
+		{
+			name:    "Status_is_empty",
+			status:  "",
+			wantErr: true,
+		},
+		{
+			name:    "Status_is_unknown",
+			status:  "postponed",
+			wantErr: true,
+		},
+		{
+			name:    "Status_is_valid",
+			status:  entity.TournamentStatusEntriesOpen,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ChangeTournamentStatusRequest{
+				Status: tt.status,
+			}
+			if err := r.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("ChangeTournamentStatusRequest.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_changeTournamentStatus_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+
+	type args struct {
+		ctx     context.Context
+		id      string
+		request *ChangeTournamentStatusRequest
+	}
+	tests := []struct {
+		name         string
+		args         args
+		prepareMocks func()
+		want         *entity.Tournament
+		wantErr      error
+	}{
+		{
+			name: "Fails_when_fetching_tournament",
+			args: args{
+				ctx:     context.Background(),
+				id:      "663d70d88264adea5d7d29bb",
+				request: &ChangeTournamentStatusRequest{Status: entity.TournamentStatusEntriesOpen},
+			},
+			prepareMocks: func() {
+				dbReader.EXPECT().GetTournament(gomock.Any(), "663d70d88264adea5d7d29bb").Return(nil, errors.New("error when fetching tournament"))
+			},
+			want:    nil,
+			wantErr: errors.New("error when fetching tournament"),
+		},
+		{
+			name: "Draft_tournament_cannot_start",
+			args: args{
+				ctx:     context.Background(),
+				id:      "663d70d88264adea5d7d29bb",
+				request: &ChangeTournamentStatusRequest{Status: entity.TournamentStatusInProgress},
+			},
+			prepareMocks: func() {
+				dbReader.EXPECT().GetTournament(gomock.Any(), "663d70d88264adea5d7d29bb").Return(&entity.Tournament{
+					Name: "Tournament 1",
+				}, nil)
+			},
+			want:    nil,
+			wantErr: util.ErrTournamentInvalidStatusTransition,
+		},
+		{
+			name: "Completed_tournament_cannot_be_cancelled",
+			args: args{
+				ctx:     context.Background(),
+				id:      "663d70d88264adea5d7d29bb",
+				request: &ChangeTournamentStatusRequest{Status: entity.TournamentStatusCancelled},
+			},
+			prepareMocks: func() {
+				dbReader.EXPECT().GetTournament(gomock.Any(), "663d70d88264adea5d7d29bb").Return(&entity.Tournament{
+					Name:   "Tournament 1",
+					Status: entity.TournamentStatusCompleted,
+				}, nil)
+			},
+			want:    nil,
+			wantErr: util.ErrTournamentInvalidStatusTransition,
+		},
+		{
+			name: "Opens_entries_of_draft_tournament",
+			args: args{
+				ctx:     context.Background(),
+				id:      "663d70d88264adea5d7d29bb",
+				request: &ChangeTournamentStatusRequest{Status: entity.TournamentStatusEntriesOpen},
+			},
+			prepareMocks: func() {
+				dbReader.EXPECT().GetTournament(gomock.Any(), "663d70d88264adea5d7d29bb").Return(&entity.Tournament{
+					Name:   "Tournament 1",
+					Status: entity.TournamentStatusDraft,
+				}, nil)
+				dbWriter.EXPECT().UpdateTournament(gomock.Any(), &entity.Tournament{
+					Name:   "Tournament 1",
+					Status: entity.TournamentStatusEntriesOpen,
+				}).Return(&entity.Tournament{
+					Name:   "Tournament 1",
+					Status: entity.TournamentStatusEntriesOpen,
+				}, nil)
+			},
+			want: &entity.Tournament{
+				Name:   "Tournament 1",
+				Status: entity.TournamentStatusEntriesOpen,
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMocks()
+			u := NewChangeTournamentStatus(dbWriter, dbReader)
+			got, err := u.Do(tt.args.ctx, tt.args.id, tt.args.request)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("changeTournamentStatus.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changeTournamentStatus.Do() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,10 +2,15 @@ package usecase
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/quota"
 	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
 )
 
 type CreateTournamentRequest struct {
@@ -16,27 +21,68 @@ type CreateTournamentRequest struct {
 	Category  *entity.Category `json:"category"`
 }
 
+func (r *CreateTournamentRequest) Validate() error {
+	var v util.Validator
+	validateTournamentFields(&v, r.Name, r.StartDate, r.EndDate, r.Category)
+	return v.Err()
+}
+
+// validateTournamentFields checks the fields every tournament must have, adding the ones that are
+// not valid to v.
+func validateTournamentFields(v *util.Validator, name string, startDate time.Time, endDate time.Time, category *entity.Category) {
+	v.Check(strings.TrimSpace(name) != "", "name", util.ErrTournamentNameIsEmpty)
+	v.Check(!startDate.IsZero(), "start_date", util.ErrTournamentStartDateIsEmpty)
+	v.Check(!endDate.IsZero(), "end_date", util.ErrTournamentEndDateIsEmpty)
+
+	if !startDate.IsZero() && !endDate.IsZero() {
+		v.Check(!endDate.Before(startDate), "end_date", util.ErrTournamentEndDateIsBeforeStartDate)
+	}
+
+	v.Check(category == nil || !category.ID.IsZero(), "category.id", util.ErrTournamentCategoryIDIsEmpty)
+}
+
 type CreateTournament interface {
 	CreateTournament(ctx context.Context, request *CreateTournamentRequest) (*entity.Tournament, error)
 }
 
 type createTournament struct {
-	DBWriter database.DBWriter
+	DBWriter         database.DBWriter
+	ValidateCategory ValidateCategory
+	Quota            *quota.Checker
 }
 
-func NewCreateTournament(dbWriter database.DBWriter) CreateTournament {
+func NewCreateTournament(dbWriter database.DBWriter, dbReader database.DBReader, plan entity.Plan) CreateTournament {
 	return &createTournament{
-		DBWriter: dbWriter,
+		DBWriter:         dbWriter,
+		ValidateCategory: NewValidateCategoryUsecase(dbReader),
+		Quota:            quota.NewChecker(dbReader, plan),
 	}
 }
 
 func (u *createTournament) CreateTournament(ctx context.Context, request *CreateTournamentRequest) (*entity.Tournament, error) {
+	if err := request.Validate(); err != nil {
+		log.Logger.Info(fmt.Errorf("couldn't create tournament. Error when validating request: %w", err).Error())
+		return nil, err
+	}
+
+	category, err := u.ValidateCategory.Find(ctx, request.Category)
+	if err != nil {
+		log.Logger.Info(fmt.Errorf("couldn't create tournament. Error when validating category: %w", err).Error())
+		return nil, err
+	}
+
+	if err := u.Quota.CheckTournaments(ctx, request.StartDate.Year(), 1); err != nil {
+		log.Logger.Info(fmt.Errorf("couldn't create tournament: %w", err).Error())
+		return nil, err
+	}
+
 	tournament := &entity.Tournament{
 		Name:      request.Name,
 		Location:  request.Location,
 		StartDate: request.StartDate,
 		EndDate:   request.EndDate,
-		Category:  request.Category,
+		Category:  category,
+		Status:    entity.TournamentStatusDraft,
 	}
 
 	return u.DBWriter.AddTournament(ctx, tournament)
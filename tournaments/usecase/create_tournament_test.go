@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateTournamentRequest_Validate(t *testing.T) {
+	startDate := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, time.June, 9, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		request *CreateTournamentRequest
+		wantErr error
+	}{
+		{
+			name: "Name_is_empty",
+			request: &CreateTournamentRequest{
+				Name:      "  ",
+				StartDate: startDate,
+				EndDate:   endDate,
+			},
+			wantErr: util.ErrTournamentNameIsEmpty,
+		},
+		{
+			name: "Start_date_is_empty",
+			request: &CreateTournamentRequest{
+				Name:    "Tournament 1",
+				EndDate: endDate,
+			},
+			wantErr: util.ErrTournamentStartDateIsEmpty,
+		},
+		{
+			name: "End_date_is_empty",
+			request: &CreateTournamentRequest{
+				Name:      "Tournament 1",
+				StartDate: startDate,
+			},
+			wantErr: util.ErrTournamentEndDateIsEmpty,
+		},
+		{
+			name: "End_date_is_before_start_date",
+			request: &CreateTournamentRequest{
+				Name:      "Tournament 1",
+				StartDate: endDate,
+				EndDate:   startDate,
+			},
+			wantErr: util.ErrTournamentEndDateIsBeforeStartDate,
+		},
+		{
+			name: "Category_without_ID",
+			request: &CreateTournamentRequest{
+				Name:      "Tournament 1",
+				StartDate: startDate,
+				EndDate:   endDate,
+				Category:  &entity.Category{Name: "Category 1"},
+			},
+			wantErr: util.ErrTournamentCategoryIDIsEmpty,
+		},
+		{
+			name: "Request_is_correct",
+			request: &CreateTournamentRequest{
+				Name:      "Tournament 1",
+				StartDate: startDate,
+				EndDate:   startDate,
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.request.Validate(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CreateTournamentRequest.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_createTournament_CreateTournament(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+
+	categoryID, _ := primitive.ObjectIDFromHex("663d70d88264adea5d7d29bb")
+	startDate := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, time.June, 9, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		request      *CreateTournamentRequest
+		prepareMocks func()
+		want         *entity.Tournament
+		wantErr      error
+	}{
+		{
+			name: "Category_does_not_exist",
+			request: &CreateTournamentRequest{
+				Name:      "Tournament 1",
+				StartDate: startDate,
+				EndDate:   endDate,
+				Category:  &entity.Category{ID: categoryID},
+			},
+			prepareMocks: func() {
+				dbReader.EXPECT().GetCategory(gomock.Any(), "663d70d88264adea5d7d29bb").Return(nil, mongo.ErrNoDocuments)
+			},
+			want:    nil,
+			wantErr: util.ErrTournamentCategoryNotFound,
+		},
+		{
+			name: "Embeds_the_stored_category",
+			request: &CreateTournamentRequest{
+				Name:      "Tournament 1",
+				StartDate: startDate,
+				EndDate:   endDate,
+				Category:  &entity.Category{ID: categoryID, Name: "Outdated name"},
+			},
+			prepareMocks: func() {
+				dbReader.EXPECT().GetCategory(gomock.Any(), "663d70d88264adea5d7d29bb").Return(&entity.Category{ID: categoryID, Name: "Category 1"}, nil)
+				dbWriter.EXPECT().AddTournament(gomock.Any(), &entity.Tournament{
+					Name:      "Tournament 1",
+					StartDate: startDate,
+					EndDate:   endDate,
+					Category:  &entity.Category{ID: categoryID, Name: "Category 1"},
+					Status:    entity.TournamentStatusDraft,
+				}).DoAndReturn(func(_ context.Context, tournament *entity.Tournament) (*entity.Tournament, error) {
+					return tournament, nil
+				})
+			},
+			want: &entity.Tournament{
+				Name:      "Tournament 1",
+				StartDate: startDate,
+				EndDate:   endDate,
+				Category:  &entity.Category{ID: categoryID, Name: "Category 1"},
+				Status:    entity.TournamentStatusDraft,
+			},
+			wantErr: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareMocks()
+			u := NewCreateTournament(dbWriter, dbReader, entity.Plan{})
+			got, err := u.CreateTournament(context.Background(), tt.request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("createTournament.CreateTournament() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("createTournament.CreateTournament() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -4,22 +4,39 @@ import (
 	"context"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/util"
 )
 
 type DeleteTournament interface {
-	Do(ctx context.Context, id string) error
+	Do(ctx context.Context, id string, version *int64, deletedBy string) error
 }
 
 type deleteTournament struct {
 	DBWriter database.DBWriter
+	DBReader database.DBReader
 }
 
-func NewDeleteTournament(dbWriter database.DBWriter) DeleteTournament {
+func NewDeleteTournament(dbWriter database.DBWriter, dbReader database.DBReader) DeleteTournament {
 	return &deleteTournament{
 		DBWriter: dbWriter,
+		DBReader: dbReader,
 	}
 }
 
-func (u *deleteTournament) Do(ctx context.Context, id string) error {
-	return u.DBWriter.DeleteTournament(ctx, id)
+func (u *deleteTournament) Do(ctx context.Context, id string, version *int64, deletedBy string) error {
+	tournament, err := u.DBReader.GetTournament(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := util.CheckVersion(version, tournament.Version); err != nil {
+		return err
+	}
+
+	if !tournament.CanBeDeleted() {
+		return util.ErrTournamentCannotBeDeleted
+	}
+
+	// The tournament is only deleted as it was checked, even when no version was given
+	return u.DBWriter.DeleteTournament(ctx, id, &tournament.Version, deletedBy)
 }
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_deleteTournament_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name           string
+		version        *int64
+		prepareUsecase func()
+		wantErr        error
+	}{
+		{
+			name: "Deletes_the_tournament_only_with_the_version_it_was_checked_at",
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTournament(gomock.Any(), id.Hex()).Return(&entity.Tournament{ID: id, Status: entity.TournamentStatusDraft, Version: 3}, nil)
+				dbWriter.EXPECT().DeleteTournament(gomock.Any(), id.Hex(), util.ToPtr(int64(3)), "admin").Return(nil)
+			},
+		},
+		{
+			name: "Fails_when_tournament_is_in_progress",
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTournament(gomock.Any(), id.Hex()).Return(&entity.Tournament{ID: id, Status: entity.TournamentStatusInProgress, Version: 3}, nil)
+			},
+			wantErr: util.ErrTournamentCannotBeDeleted,
+		},
+		{
+			name:    "Fails_when_tournament_has_been_modified",
+			version: util.ToPtr(int64(2)),
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTournament(gomock.Any(), id.Hex()).Return(&entity.Tournament{ID: id, Status: entity.TournamentStatusDraft, Version: 3}, nil)
+			},
+			wantErr: util.ErrPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			u := NewDeleteTournament(dbWriter, dbReader)
+			if err := u.Do(context.Background(), id.Hex(), tt.version, "admin"); !errors.Is(err, tt.wantErr) {
+				t.Errorf("deleteTournament.Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+var tournamentsExportHeader = []string{"id", "name", "location", "start_date", "end_date", "category_id", "category_name", "status"}
+
+type ExportTournaments interface {
+	Do(ctx context.Context, w io.Writer, format util.ExportFormat, q *query.Query) error
+}
+
+type exportTournaments struct {
+	DBReader database.DBReader
+}
+
+func NewExportTournaments(dbReader database.DBReader) ExportTournaments {
+	return &exportTournaments{
+		DBReader: dbReader,
+	}
+}
+
+// Do writes to w, in the given format, the tournaments matching the filters and sort of q.
+func (uc *exportTournaments) Do(ctx context.Context, w io.Writer, format util.ExportFormat, q *query.Query) error {
+	exporter := util.NewExporter(w, format, tournamentsExportHeader, tournamentRecord)
+
+	if err := uc.DBReader.StreamTournaments(ctx, q, exporter.Write); err != nil {
+		return err
+	}
+
+	return exporter.Flush()
+}
+
+func tournamentRecord(t *entity.Tournament) []string {
+	var categoryID, categoryName string
+	if t.Category != nil {
+		categoryID, categoryName = t.Category.ID.Hex(), t.Category.Name
+	}
+
+	return []string{
+		t.ID.Hex(),
+		t.Name,
+		t.Location,
+		t.StartDate.Format(time.DateOnly),
+		t.EndDate.Format(time.DateOnly),
+		categoryID,
+		categoryName,
+		string(t.GetStatus()),
+	}
+}
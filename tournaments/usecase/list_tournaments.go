@@ -4,11 +4,26 @@ import (
 	"context"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/query"
 	"github.com/Neniel/gotennis/lib/entity"
 )
 
+var TournamentsQuerySchema = query.Schema{
+	Filters: map[string]query.Field{
+		"name":        {Path: "name", Type: query.String},
+		"location":    {Path: "location", Type: query.String},
+		"status":      {Path: "status", Type: query.String},
+		"category_id": {Path: "category._id", Type: query.ObjectID},
+	},
+	Sorts: map[string]query.Field{
+		"name":       {Path: "name"},
+		"start_date": {Path: "start_date"},
+		"end_date":   {Path: "end_date"},
+	},
+}
+
 type ListTournaments interface {
-	Do(ctx context.Context) ([]entity.Tournament, error)
+	Do(ctx context.Context, q *query.Query) (*query.Page[entity.Tournament], error)
 }
 
 type listTournaments struct {
@@ -21,6 +36,6 @@ func NewListTournaments(dbReader database.DBReader) ListTournaments {
 	}
 }
 
-func (u *listTournaments) Do(ctx context.Context) ([]entity.Tournament, error) {
-	return u.DBReader.GetTournaments(ctx)
+func (u *listTournaments) Do(ctx context.Context, q *query.Query) (*query.Page[entity.Tournament], error) {
+	return u.DBReader.GetTournaments(ctx, q)
 }
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/quota"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
+)
+
+// PatchTournamentRequest is a JSON merge patch (RFC 7396) of a tournament: only the fields in it
+// are changed, and the ones that are null are cleared.
+type PatchTournamentRequest struct {
+	ID        util.PatchField[string]           `json:"id"`
+	Name      util.PatchField[string]           `json:"name"`
+	Location  util.PatchField[string]           `json:"location"`
+	StartDate util.PatchField[time.Time]        `json:"start_date"`
+	EndDate   util.PatchField[time.Time]        `json:"end_date"`
+	Category  util.PatchField[*entity.Category] `json:"category"`
+	// Version is the one the update is based on, taken from header If-Match. When set, the update
+	// fails with util.ErrPreconditionFailed if the tournament has been written since
+	Version *int64 `json:"-"`
+}
+
+func (r *PatchTournamentRequest) Validate(id string) error {
+	var v util.Validator
+	if id == "" {
+		v.Add("id", util.ErrTournamentIDIsEmpty)
+	} else if r.ID.Present {
+		v.Check(r.ID.Value == id, "id", util.ErrTournamentIDMismatch)
+	}
+
+	return v.Err()
+}
+
+type PatchTournament interface {
+	Do(ctx context.Context, id string, request *PatchTournamentRequest) (*entity.Tournament, error)
+}
+
+type patchTournament struct {
+	DBWriter         database.DBWriter
+	DBReader         database.DBReader
+	ValidateCategory ValidateCategory
+	Quota            *quota.Checker
+}
+
+func NewPatchTournament(dbWriter database.DBWriter, dbReader database.DBReader, plan entity.Plan) PatchTournament {
+	return &patchTournament{
+		DBWriter:         dbWriter,
+		DBReader:         dbReader,
+		ValidateCategory: NewValidateCategoryUsecase(dbReader),
+		Quota:            quota.NewChecker(dbReader, plan),
+	}
+}
+
+func (u *patchTournament) Do(ctx context.Context, id string, request *PatchTournamentRequest) (*entity.Tournament, error) {
+	if err := request.Validate(id); err != nil {
+		log.Logger.Info(fmt.Errorf("couldn't update tournament. Error when validating request: %w", err).Error())
+		return nil, err
+	}
+
+	tournament, err := u.DBReader.GetTournament(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := util.CheckVersion(request.Version, tournament.Version); err != nil {
+		return nil, err
+	}
+
+	if !tournament.IsEditable() {
+		return nil, util.ErrTournamentIsNotEditable
+	}
+
+	year := tournament.StartDate.Year()
+
+	patch := util.NewPatch()
+	util.ApplyPatchField(patch, "name", request.Name, &tournament.Name)
+	util.ApplyPatchField(patch, "location", request.Location, &tournament.Location)
+	util.ApplyPatchField(patch, "start_date", request.StartDate, &tournament.StartDate)
+	util.ApplyPatchField(patch, "end_date", request.EndDate, &tournament.EndDate)
+
+	var v util.Validator
+	validateTournamentFields(&v, tournament.Name, tournament.StartDate, tournament.EndDate, request.Category.Value)
+	if err := v.Err(); err != nil {
+		log.Logger.Info(fmt.Errorf("couldn't update tournament. Error when validating request: %w", err).Error())
+		return nil, err
+	}
+
+	// Moving the tournament to another year counts it in the plan of that year
+	if tournament.StartDate.Year() != year {
+		if err := u.Quota.CheckTournaments(ctx, tournament.StartDate.Year(), 1); err != nil {
+			return nil, err
+		}
+	}
+
+	// Tournaments embed the stored copy of their category rather than the one in the request
+	if request.Category.Present {
+		category, err := u.ValidateCategory.Find(ctx, request.Category.Value)
+		if err != nil {
+			log.Logger.Info(fmt.Errorf("couldn't update tournament. Error when validating category: %w", err).Error())
+			return nil, err
+		}
+
+		util.ApplyPatchField(patch, "category", util.PatchField[*entity.Category]{Present: true, Null: category == nil, Value: category}, &tournament.Category)
+	}
+
+	if patch.IsEmpty() {
+		return tournament, nil
+	}
+
+	return u.DBWriter.PatchTournament(ctx, tournament, patch)
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/quota"
+	"github.com/Neniel/gotennis/lib/entity"
+)
+
+type RestoreTournament interface {
+	Do(ctx context.Context, id string) error
+}
+
+type restoreTournament struct {
+	DBWriter database.DBWriter
+	DBReader database.DBReader
+	Quota    *quota.Checker
+}
+
+func NewRestoreTournament(dbWriter database.DBWriter, dbReader database.DBReader, plan entity.Plan) RestoreTournament {
+	return &restoreTournament{
+		DBWriter: dbWriter,
+		DBReader: dbReader,
+		Quota:    quota.NewChecker(dbReader, plan),
+	}
+}
+
+// Do restores the deleted tournament, which counts again towards the tournaments of the plan in the
+// year it starts, like a new one.
+func (u *restoreTournament) Do(ctx context.Context, id string) error {
+	tournament, err := u.DBReader.GetDeletedTournament(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := u.Quota.CheckTournaments(ctx, tournament.StartDate.Year(), 1); err != nil {
+		return err
+	}
+
+	return u.DBWriter.RestoreTournament(ctx, id)
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_restoreTournament_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+	id := primitive.NewObjectID()
+	deleted := &entity.Tournament{ID: id, StartDate: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)}
+	plan := entity.Plan{MaxTournamentsPerYear: 3}
+
+	tests := []struct {
+		name           string
+		prepareUsecase func()
+		wantErr        error
+	}{
+		{
+			name: "Restores_tournament",
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetDeletedTournament(gomock.Any(), id.Hex()).Return(deleted, nil)
+				dbReader.EXPECT().CountTournaments(gomock.Any(), time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)).Return(int64(2), nil)
+				dbWriter.EXPECT().RestoreTournament(gomock.Any(), id.Hex()).Return(nil)
+			},
+		},
+		{
+			name: "Fails_when_the_plan_is_full_in_the_year_of_the_tournament",
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetDeletedTournament(gomock.Any(), id.Hex()).Return(deleted, nil)
+				dbReader.EXPECT().CountTournaments(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(3), nil)
+			},
+			wantErr: util.ErrPlanTournamentsLimitReached,
+		},
+		{
+			name: "Fails_when_tournament_is_not_deleted",
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetDeletedTournament(gomock.Any(), id.Hex()).Return(nil, mongo.ErrNoDocuments)
+			},
+			wantErr: mongo.ErrNoDocuments,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			u := NewRestoreTournament(dbWriter, dbReader, plan)
+			if err := u.Do(context.Background(), id.Hex()); !errors.Is(err, tt.wantErr) {
+				t.Errorf("restoreTournament.Do() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,10 +2,14 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/database/quota"
 	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/log"
+	"github.com/Neniel/gotennis/lib/util"
 )
 
 type UpdateTournamentRequest struct {
@@ -15,6 +19,21 @@ type UpdateTournamentRequest struct {
 	StartDate time.Time        `json:"start_date"`
 	EndDate   time.Time        `json:"end_date"`
 	Category  *entity.Category `json:"category"`
+	// Version is the one the update is based on, taken from header If-Match. When set, the update
+	// fails with util.ErrPreconditionFailed if the tournament has been written since
+	Version *int64 `json:"-"`
+}
+
+func (r *UpdateTournamentRequest) Validate(id string) error {
+	var v util.Validator
+	if r.ID == "" {
+		v.Add("id", util.ErrTournamentIDIsEmpty)
+	} else {
+		v.Check(r.ID == id, "id", util.ErrTournamentIDMismatch)
+	}
+
+	validateTournamentFields(&v, r.Name, r.StartDate, r.EndDate, r.Category)
+	return v.Err()
 }
 
 type UpdateTournament interface {
@@ -22,28 +41,58 @@ type UpdateTournament interface {
 }
 
 type updateTournament struct {
-	DBWriter database.DBWriter
-	DBReader database.DBReader
+	DBWriter         database.DBWriter
+	DBReader         database.DBReader
+	ValidateCategory ValidateCategory
+	Quota            *quota.Checker
 }
 
-func NewUpdateTournament(dbWriter database.DBWriter, dbReader database.DBReader) UpdateTournament {
+func NewUpdateTournament(dbWriter database.DBWriter, dbReader database.DBReader, plan entity.Plan) UpdateTournament {
 	return &updateTournament{
-		DBWriter: dbWriter,
-		DBReader: dbReader,
+		DBWriter:         dbWriter,
+		DBReader:         dbReader,
+		ValidateCategory: NewValidateCategoryUsecase(dbReader),
+		Quota:            quota.NewChecker(dbReader, plan),
 	}
 }
 
 func (u *updateTournament) Do(ctx context.Context, id string, request *UpdateTournamentRequest) (*entity.Tournament, error) {
+	if err := request.Validate(id); err != nil {
+		log.Logger.Info(fmt.Errorf("couldn't update tournament. Error when validating request: %w", err).Error())
+		return nil, err
+	}
+
 	tournament, err := u.DBReader.GetTournament(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := util.CheckVersion(request.Version, tournament.Version); err != nil {
+		return nil, err
+	}
+
+	if !tournament.IsEditable() {
+		return nil, util.ErrTournamentIsNotEditable
+	}
+
+	category, err := u.ValidateCategory.Find(ctx, request.Category)
+	if err != nil {
+		log.Logger.Info(fmt.Errorf("couldn't update tournament. Error when validating category: %w", err).Error())
+		return nil, err
+	}
+
+	// Moving the tournament to another year counts it in the plan of that year
+	if request.StartDate.Year() != tournament.StartDate.Year() {
+		if err := u.Quota.CheckTournaments(ctx, request.StartDate.Year(), 1); err != nil {
+			return nil, err
+		}
+	}
+
 	tournament.Name = request.Name
 	tournament.Location = request.Location
 	tournament.StartDate = request.StartDate
 	tournament.EndDate = request.EndDate
-	tournament.Category = request.Category
+	tournament.Category = category
 
 	return u.DBWriter.UpdateTournament(ctx, tournament)
 }
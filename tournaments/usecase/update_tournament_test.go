@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Neniel/gotennis/lib/database"
+	"github.com/Neniel/gotennis/lib/entity"
+	"github.com/Neniel/gotennis/lib/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_updateTournament_Do(t *testing.T) {
+	dbReader := database.NewMockDBReader(gomock.NewController(t))
+	dbWriter := database.NewMockDBWriter(gomock.NewController(t))
+	id := primitive.NewObjectID()
+	plan := entity.Plan{MaxTournamentsPerYear: 3}
+
+	storedTournament := func() *entity.Tournament {
+		return &entity.Tournament{
+			ID:        id,
+			Name:      "Open",
+			StartDate: time.Date(2025, time.December, 20, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2025, time.December, 22, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	tests := []struct {
+		name           string
+		request        *UpdateTournamentRequest
+		prepareUsecase func()
+		want           *entity.Tournament
+		wantErr        error
+	}{
+		{
+			name: "Updates_tournament_in_the_same_year_without_checking_the_plan",
+			request: &UpdateTournamentRequest{
+				ID:        id.Hex(),
+				Name:      "Open de verano",
+				StartDate: time.Date(2025, time.December, 27, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2025, time.December, 29, 0, 0, 0, 0, time.UTC),
+			},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTournament(gomock.Any(), id.Hex()).Return(storedTournament(), nil)
+				dbWriter.EXPECT().UpdateTournament(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, tournament *entity.Tournament) (*entity.Tournament, error) {
+					return tournament, nil
+				})
+			},
+			want: &entity.Tournament{
+				ID:        id,
+				Name:      "Open de verano",
+				StartDate: time.Date(2025, time.December, 27, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2025, time.December, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Fails_when_moving_the_tournament_to_a_year_whose_plan_is_full",
+			request: &UpdateTournamentRequest{
+				ID:        id.Hex(),
+				Name:      "Open",
+				StartDate: time.Date(2026, time.January, 3, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC),
+			},
+			prepareUsecase: func() {
+				dbReader.EXPECT().GetTournament(gomock.Any(), id.Hex()).Return(storedTournament(), nil)
+				dbReader.EXPECT().CountTournaments(gomock.Any(), time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)).Return(int64(3), nil)
+			},
+			wantErr: util.ErrPlanTournamentsLimitReached,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.prepareUsecase()
+			u := NewUpdateTournament(dbWriter, dbReader, plan)
+			got, err := u.Do(context.Background(), id.Hex(), tt.request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("updateTournament.Do() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("updateTournament.Do() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}